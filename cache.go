@@ -0,0 +1,65 @@
+package toolbox
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache remembers the result of a slow read for the configured limit cache TTL
+type ttlCache[T any] struct {
+	mu      sync.Mutex
+	value   T
+	expires time.Time
+	valid   bool
+}
+
+// get returns the cached value while it is fresh, otherwise calls read and caches
+// a successful result. Errors are not cached.
+func (c *ttlCache[T]) get(read func() (T, error)) (T, error) {
+	ttl := limitCacheTTL()
+	if ttl <= 0 {
+		return read()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.valid && time.Now().Before(c.expires) {
+		return c.value, nil
+	}
+	value, err := read()
+	if err != nil {
+		return value, err
+	}
+	c.value, c.expires, c.valid = value, time.Now().Add(ttl), true
+	return value, nil
+}
+
+// reset discards the cached value
+func (c *ttlCache[T]) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero T
+	c.value, c.valid = zero, false
+}
+
+// memoryLimit is a memory limit and where it came from, see getMemoryLimitWithSource
+type memoryLimit struct {
+	bytes  int64
+	source string
+}
+
+// Caches of values that rarely change during a test, read on every call otherwise
+var (
+	cpuLimitCache      ttlCache[float64]
+	availableCPUsCache ttlCache[float64]
+	memoryLimitCache   ttlCache[memoryLimit]
+)
+
+// resetLimitCaches discards all cached limits
+func resetLimitCaches() {
+	cpuLimitCache.reset()
+	availableCPUsCache.reset()
+	memoryLimitCache.reset()
+}
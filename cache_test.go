@@ -0,0 +1,102 @@
+package toolbox
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTTLCache(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+	Configure(Options{LimitCacheTTLMs: 50})
+
+	var cache ttlCache[int]
+	reads := 0
+	read := func() (int, error) {
+		reads++
+		return reads, nil
+	}
+
+	// Within the TTL the reader runs once
+	for range 3 {
+		if value, err := cache.get(read); err != nil || value != 1 {
+			t.Errorf("Expected cached value 1, got %d (%v)", value, err)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("Expected 1 read within the TTL, got %d", reads)
+	}
+
+	// After the TTL the value is read again
+	time.Sleep(60 * time.Millisecond)
+	if value, _ := cache.get(read); value != 2 || reads != 2 {
+		t.Errorf("Expected a fresh read after the TTL, got value %d after %d reads", value, reads)
+	}
+
+	// reset discards the value
+	cache.reset()
+	if value, _ := cache.get(read); value != 3 {
+		t.Errorf("Expected a fresh read after reset, got %d", value)
+	}
+
+	// Errors are not cached
+	cache.reset()
+	if _, err := cache.get(func() (int, error) { return 0, errors.New("read failed") }); err == nil {
+		t.Error("Expected the read error")
+	}
+	if value, _ := cache.get(read); value != 4 {
+		t.Errorf("Expected a read after an error, got %d", value)
+	}
+
+	// A negative TTL disables caching
+	Configure(Options{LimitCacheTTLMs: -1})
+	cache.get(read)
+	cache.get(read)
+	if reads != 6 {
+		t.Errorf("Expected every call to read with caching disabled, got %d reads", reads)
+	}
+}
+
+func TestLimitCacheTTL(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+
+	Configure(Options{})
+	if ttl := limitCacheTTL(); ttl != defaultLimitCacheTTL {
+		t.Errorf("Expected default TTL %v, got %v", defaultLimitCacheTTL, ttl)
+	}
+	Configure(Options{LimitCacheTTLMs: 250})
+	if ttl := limitCacheTTL(); ttl != 250*time.Millisecond {
+		t.Errorf("Expected 250ms, got %v", ttl)
+	}
+	Configure(Options{LimitCacheTTLMs: -1})
+	if ttl := limitCacheTTL(); ttl != 0 {
+		t.Errorf("Expected caching disabled, got %v", ttl)
+	}
+}
+
+func TestMemoryLimitCached(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	t.Cleanup(func() { Configure(Options{}) })
+
+	root := t.TempDir()
+	Configure(Options{CgroupRoot: root, LimitCacheTTLMs: 60000})
+	writeCgroupFile(t, root, "memory.max", "536870912\n")
+
+	if limit, err := getMemoryLimit(); err != nil || limit != 536870912 {
+		t.Fatalf("Expected limit 536870912, got %d (%v)", limit, err)
+	}
+
+	// A changed file isn't read again within the TTL
+	writeCgroupFile(t, root, "memory.max", "1073741824\n")
+	if limit, _ := getMemoryLimit(); limit != 536870912 {
+		t.Errorf("Expected the cached limit, got %d", limit)
+	}
+
+	// Configure discards the cache
+	Configure(Options{CgroupRoot: root, LimitCacheTTLMs: 60000})
+	if limit, _ := getMemoryLimit(); limit != 1073741824 {
+		t.Errorf("Expected the new limit after Configure, got %d", limit)
+	}
+}
@@ -0,0 +1,704 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// GetMemoryLimitHierarchical returns the effective memory limit in bytes, taking
+// the lowest limit across the process's cgroup and all of its ancestors
+func (Toolbox) GetMemoryLimitHierarchical() (int64, error) {
+	return getMemoryLimitHierarchical()
+}
+
+// getMemoryLimitHierarchical walks from the process's own cgroup up to the root
+// and returns the minimum memory limit found, or system memory if none is set
+func getMemoryLimitHierarchical() (int64, error) {
+	if !isLinux() {
+		return getMemoryLimit()
+	}
+
+	content, err := readFile("/proc/self/cgroup")
+	if err != nil {
+		return 0, err
+	}
+	paths := parseProcCgroup(content)
+
+	var limit int64
+	var found bool
+	if cgroupPath, ok := paths[""]; ok && fileExists(cgroupFile("cgroup.controllers")) {
+		limit, found, err = minCgroupLimit(cgroupRoot(), cgroupPath, "memory.max", parseCgroupMemoryLimit)
+	} else if cgroupPath, ok := paths["memory"]; ok {
+		limit, found, err = minCgroupLimit(filepath.Join(cgroupRoot(), "memory"), cgroupPath, "memory.limit_in_bytes", parseCgroupMemoryLimit)
+	} else {
+		return 0, errors.New(ErrCgroupNotFound)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if !found {
+		// No limit anywhere in the hierarchy
+		return getSystemMemory()
+	}
+	return limit, nil
+}
+
+// parseProcCgroup parses /proc/<pid>/cgroup into a map of controller to cgroup path.
+// The cgroup v2 unified hierarchy is keyed by the empty string.
+func parseProcCgroup(content string) map[string]string {
+	paths := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		// Format: hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[1] == "" {
+			paths[""] = parts[2]
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			paths[controller] = parts[2]
+		}
+	}
+	return paths
+}
+
+// selfCgroupPaths caches the parsed /proc/self/cgroup, which doesn't change
+// for the lifetime of the process
+var selfCgroupPaths = sync.OnceValue(func() map[string]string {
+	content, err := readFile("/proc/self/cgroup")
+	if err != nil {
+		return nil
+	}
+	return parseProcCgroup(content)
+})
+
+// nestedCgroupFile returns the path of rel inside the process's own cgroup, e.g.
+// <root>/memory/kubepods/<pod>/memory.limit_in_bytes for "memory/memory.limit_in_bytes".
+// The controller comes from rel's directory ("cpu,cpuacct" is looked up as
+// "cpu"), and files without one use the unified v2 path. ok is false when the
+// process is in the root cgroup or the nested file doesn't exist, as when the
+// cgroup is namespaced and mounted at the root.
+func nestedCgroupFile(root, rel string, paths map[string]string) (string, bool) {
+	if rel == "" || len(paths) == 0 {
+		return "", false
+	}
+
+	controllerDir, file := path.Split(rel)
+	controllerDir = strings.TrimSuffix(controllerDir, "/")
+	if strings.Contains(controllerDir, "/") {
+		return "", false
+	}
+	controller, _, _ := strings.Cut(controllerDir, ",")
+
+	cgroupPath, ok := paths[controller]
+	if !ok || path.Clean("/"+cgroupPath) == "/" {
+		return "", false
+	}
+
+	nested := filepath.Join(root, controllerDir, cgroupPath, file)
+	if !fileExists(nested) {
+		return "", false
+	}
+	return nested, true
+}
+
+// minCgroupLimit reads file in cgroupPath and each of its ancestors below base and
+// returns the lowest limit. Levels that are missing or unlimited are skipped;
+// found is false when no level sets a limit.
+func minCgroupLimit(base, cgroupPath, file string, parse func(string) (int64, bool, error)) (limit int64, found bool, err error) {
+	dir := path.Clean("/" + cgroupPath)
+	for {
+		content, readErr := readFile(filepath.Join(base, dir, file))
+		if readErr == nil {
+			value, unlimited, parseErr := parse(content)
+			if parseErr != nil {
+				return 0, false, fmt.Errorf("%s: %s: %w", ErrParsingValue, filepath.Join(dir, file), parseErr)
+			}
+			if !unlimited && (!found || value < limit) {
+				limit = value
+				found = true
+			}
+		}
+		if dir == "/" {
+			break
+		}
+		dir = path.Dir(dir)
+	}
+	return limit, found, nil
+}
+
+// parseCgroupMemoryLimit parses a cgroup v1 or v2 memory limit file.
+// unlimited is true for "max" (v2) or the near-MaxInt64 sentinel (v1).
+func parseCgroupMemoryLimit(content string) (limit int64, unlimited bool, err error) {
+	limitStr := strings.TrimSpace(content)
+	if limitStr == "max" {
+		return 0, true, nil
+	}
+
+	limit, err = parseMemorySize(limitStr)
+	if err != nil {
+		return 0, false, err
+	}
+
+	// Very large number indicating no limit
+	if limit > math.MaxInt64/2 {
+		return 0, true, nil
+	}
+	return limit, false, nil
+}
+
+// memorySizeSuffixes are the suffixes some runtimes write into memory limit
+// files, e.g. "512M". As in the kernel's own parsing, K, M and G are powers of
+// 1024 like Ki, Mi and Gi. Two-letter suffixes come first so they match first.
+var memorySizeSuffixes = []struct {
+	suffix string
+	size   int64
+}{
+	{"KI", 1 << 10}, {"MI", 1 << 20}, {"GI", 1 << 30},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+}
+
+// parseMemorySize parses a byte count with an optional, case-insensitive
+// K/M/G or Ki/Mi/Gi suffix. Values too large for int64 saturate at MaxInt64.
+func parseMemorySize(value string) (int64, error) {
+	number, size := value, int64(1)
+	upper := strings.ToUpper(value)
+	for _, s := range memorySizeSuffixes {
+		if strings.HasSuffix(upper, s.suffix) {
+			number, size = value[:len(value)-len(s.suffix)], s.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxInt64/size {
+		return math.MaxInt64, nil
+	}
+	return n * size, nil
+}
+
+// ReadCgroupFile returns the raw contents of a file under the configured cgroup
+// root, e.g. "memory.max" or "memory/memory.limit_in_bytes", read from the
+// process's own cgroup like the typed getters. Absolute paths and ".." are rejected.
+func (Toolbox) ReadCgroupFile(relativePath string) (string, error) {
+	if !filepath.IsLocal(relativePath) {
+		return "", fmt.Errorf("invalid cgroup file path %q: must be relative and stay under the cgroup root", relativePath)
+	}
+	return readFile(cgroupFile(relativePath))
+}
+
+// GetMemoryStat returns the cgroup memory.stat file (v2 or v1) parsed into a map
+func (Toolbox) GetMemoryStat() (map[string]int64, error) {
+	return getMemoryStat()
+}
+
+// getMemoryStat reads memory.stat from cgroup v2, falling back to cgroup v1
+func getMemoryStat() (map[string]int64, error) {
+	if !isLinux() {
+		return nil, errors.New(ErrNotSupported)
+	}
+
+	if stat, err := readMemoryStat("memory.stat"); err == nil {
+		return stat, nil
+	}
+	return readMemoryStat("memory/memory.stat")
+}
+
+// readMemoryStat reads and parses the memory.stat file at rel under the cgroup root
+func readMemoryStat(rel string) (map[string]int64, error) {
+	content, err := readFile(cgroupFile(rel))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrCgroupNotFound, err)
+	}
+	return parseFlatKeyedFile(content)
+}
+
+// GetMemoryEvents returns the cgroup memory event counters: memory.events on v2
+// (low, high, max, oom, oom_kill), or memory.oom_control on v1 (oom_kill_disable,
+// under_oom and, on Linux 4.13+, oom_kill). A non-zero oom_kill means a process
+// in the cgroup was killed for running out of memory.
+func (Toolbox) GetMemoryEvents() (map[string]int64, error) {
+	return getMemoryEvents()
+}
+
+// getMemoryEvents reads memory.events from cgroup v2, falling back to cgroup v1
+func getMemoryEvents() (map[string]int64, error) {
+	if !isLinux() {
+		return nil, errors.New(ErrNotSupported)
+	}
+
+	content, err := readFile(cgroupFile("memory.events"))
+	if err != nil {
+		content, err = readFile(cgroupFile("memory/memory.oom_control"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrCgroupNotFound, err)
+		}
+	}
+	return parseFlatKeyedFile(content)
+}
+
+// GetMemoryPeak returns the highest memory usage of the cgroup in bytes, from
+// memory.peak on cgroup v2 or memory.max_usage_in_bytes on v1. memory.peak
+// needs Linux 5.19+; on older v2 kernels this returns a not supported error.
+func (Toolbox) GetMemoryPeak() (int64, error) {
+	return getMemoryPeak()
+}
+
+// getMemoryPeak reads the cgroup's memory high watermark, see GetMemoryPeak
+func getMemoryPeak() (int64, error) {
+	if !isLinux() {
+		return 0, errors.New(ErrNotSupported)
+	}
+
+	if peak, err := readCgroupInt64(cgroupFile("memory.peak")); err == nil {
+		return peak, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	if _, err := os.Stat(cgroupFile("memory.current")); err == nil {
+		return 0, fmt.Errorf("%s: memory.peak is missing, it requires Linux 5.19 or later", ErrNotSupported)
+	}
+
+	peak, err := readCgroupInt64(cgroupFile("memory/memory.max_usage_in_bytes"))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrCgroupNotFound, err)
+	}
+	return peak, nil
+}
+
+// parseFlatKeyedFile parses cgroup "key value" files such as memory.stat and cpu.stat
+func parseFlatKeyedFile(content string) (map[string]int64, error) {
+	values := make(map[string]int64)
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid line format: %q", line)
+		}
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", ErrParsingValue, fields[0], err)
+		}
+		values[fields[0]] = value
+	}
+	return values, nil
+}
+
+// IsSwapAccountingEnabled returns true if the cgroup exposes swap usage
+// (memory.swap.current on v2, memory.memsw.usage_in_bytes on v1). When it
+// is false, swap metrics cannot be collected and should not be asserted on.
+func (Toolbox) IsSwapAccountingEnabled() bool {
+	return isSwapAccountingEnabled()
+}
+
+// isSwapAccountingEnabled checks for the cgroup v2 or v1 swap usage files
+func isSwapAccountingEnabled() bool {
+	if !isLinux() {
+		return false
+	}
+	return fileExists(cgroupFile("memory.swap.current")) ||
+		fileExists(cgroupFile("memory/memory.memsw.usage_in_bytes"))
+}
+
+// MemoryLRUStats is the active/inactive split of anonymous and file-backed memory.
+// Inactive file pages are the most readily reclaimable under pressure.
+type MemoryLRUStats struct {
+	ActiveAnonBytes   int64 `json:"active_anon_bytes"`
+	InactiveAnonBytes int64 `json:"inactive_anon_bytes"`
+	ActiveFileBytes   int64 `json:"active_file_bytes"`
+	InactiveFileBytes int64 `json:"inactive_file_bytes"`
+}
+
+// GetMemoryLRUStats returns the active/inactive anon and file split from memory.stat
+func (Toolbox) GetMemoryLRUStats() (MemoryLRUStats, error) {
+	stat, err := getMemoryStat()
+	if err != nil {
+		return MemoryLRUStats{}, err
+	}
+	return memoryLRUStatsFromStat(stat)
+}
+
+// memoryLRUStatsFromStat extracts the LRU split from parsed memory.stat entries.
+// The hierarchical total_* entries of cgroup v1 are preferred when present.
+func memoryLRUStatsFromStat(stat map[string]int64) (MemoryLRUStats, error) {
+	var stats MemoryLRUStats
+
+	fields := []struct {
+		key  string
+		dest *int64
+	}{
+		{"active_anon", &stats.ActiveAnonBytes},
+		{"inactive_anon", &stats.InactiveAnonBytes},
+		{"active_file", &stats.ActiveFileBytes},
+		{"inactive_file", &stats.InactiveFileBytes},
+	}
+	for _, field := range fields {
+		if value, ok := stat["total_"+field.key]; ok {
+			*field.dest = value
+		} else if value, ok := stat[field.key]; ok {
+			*field.dest = value
+		} else {
+			return stats, fmt.Errorf("%s not found in memory.stat", field.key)
+		}
+	}
+	return stats, nil
+}
+
+// cachedBytesFromStat returns the page cache size from parsed memory.stat entries:
+// "file" on cgroup v2, or "total_cache" (hierarchical) or "cache" on cgroup v1
+func cachedBytesFromStat(stat map[string]int64) (int64, bool) {
+	for _, key := range []string{"file", "total_cache", "cache"} {
+		if value, ok := stat[key]; ok {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// NUMANodeMemory is a cgroup's memory usage on one NUMA node
+type NUMANodeMemory struct {
+	AnonBytes  int64 `json:"anon_bytes"`
+	FileBytes  int64 `json:"file_bytes"`
+	TotalBytes int64 `json:"total_bytes"` // From the v1 total line, or anon + file on v2
+}
+
+// GetMemoryNUMAStats returns cgroup memory usage per NUMA node from memory.numa_stat,
+// keyed by node name ("N0", "N1", ...)
+func (Toolbox) GetMemoryNUMAStats() (map[string]NUMANodeMemory, error) {
+	return getMemoryNUMAStats()
+}
+
+// getMemoryNUMAStats reads memory.numa_stat from cgroup v2, falling back to cgroup v1
+func getMemoryNUMAStats() (map[string]NUMANodeMemory, error) {
+	if !isLinux() {
+		return nil, errors.New(ErrNotSupported)
+	}
+
+	content, err := readFile(cgroupFile("memory.numa_stat"))
+	if err != nil {
+		content, err = readFile(cgroupFile("memory/memory.numa_stat"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrCgroupNotFound, err)
+		}
+	}
+	return parseNUMAStat(content, int64(os.Getpagesize()))
+}
+
+// parseNUMAStat parses the anon, file and total lines of memory.numa_stat.
+// cgroup v1 lines look like "anon=12 N0=10 N1=2" and count pages, which are
+// converted using pageSize; cgroup v2 lines look like "anon N0=40960 N1=8192"
+// and are already in bytes.
+func parseNUMAStat(content string, pageSize int64) (map[string]NUMANodeMemory, error) {
+	nodes := make(map[string]NUMANodeMemory)
+	hasTotal := false
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		key, _, v1 := strings.Cut(fields[0], "=")
+		scale := int64(1)
+		if v1 {
+			scale = pageSize
+		}
+		if key != "anon" && key != "file" && key != "total" {
+			continue
+		}
+		if key == "total" {
+			hasTotal = true
+		}
+
+		for _, field := range fields[1:] {
+			node, valueStr, found := strings.Cut(field, "=")
+			if !found || !strings.HasPrefix(node, "N") {
+				return nil, fmt.Errorf("invalid numa_stat entry: %q", field)
+			}
+			value, err := strconv.ParseInt(valueStr, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", ErrParsingValue, node, err)
+			}
+
+			stats := nodes[node]
+			switch key {
+			case "anon":
+				stats.AnonBytes = value * scale
+			case "file":
+				stats.FileBytes = value * scale
+			case "total":
+				stats.TotalBytes = value * scale
+			}
+			nodes[node] = stats
+		}
+	}
+
+	if len(nodes) == 0 {
+		return nil, errors.New("no NUMA nodes found in memory.numa_stat")
+	}
+	if !hasTotal {
+		for node, stats := range nodes {
+			stats.TotalBytes = stats.AnonBytes + stats.FileBytes
+			nodes[node] = stats
+		}
+	}
+	return nodes, nil
+}
+
+// ThrottleStats reports CFS bandwidth throttling of the cgroup from cpu.stat
+type ThrottleStats struct {
+	NrPeriods        int64   `json:"nr_periods"`        // Enforcement periods that have elapsed
+	NrThrottled      int64   `json:"nr_throttled"`      // Periods in which the cgroup was throttled
+	ThrottledUsec    int64   `json:"throttled_usec"`    // Total time throttled in microseconds
+	ThrottledPercent float64 `json:"throttled_percent"` // NrThrottled as a percentage of NrPeriods
+}
+
+// GetCPUThrottlingStats returns how often the cgroup has been CPU throttled
+func (Toolbox) GetCPUThrottlingStats() (ThrottleStats, error) {
+	return getCPUThrottlingStats()
+}
+
+// getCPUThrottlingStats reads cpu.stat from cgroup v2, falling back to cgroup v1
+func getCPUThrottlingStats() (ThrottleStats, error) {
+	if !isLinux() {
+		return ThrottleStats{}, errors.New(ErrNotSupported)
+	}
+
+	// cgroup v2 has cpu.stat even without the cpu controller, but only reports
+	// throttling when it is enabled
+	if content, err := readFile(cgroupFile("cpu.stat")); err == nil {
+		if stats, err := parseThrottleStats(content); err == nil {
+			return stats, nil
+		}
+	}
+
+	content, err := readFile(cgroupFile("cpu,cpuacct/cpu.stat"))
+	if err != nil {
+		return ThrottleStats{}, fmt.Errorf("%s: %w", ErrCgroupNotFound, err)
+	}
+	return parseThrottleStats(content)
+}
+
+// parseThrottleStats parses a v2 cpu.stat (throttled_usec) or a v1 cpu.stat
+// (throttled_time in nanoseconds)
+func parseThrottleStats(content string) (ThrottleStats, error) {
+	var stats ThrottleStats
+
+	values, err := parseFlatKeyedFile(content)
+	if err != nil {
+		return stats, err
+	}
+	periods, ok := values["nr_periods"]
+	if !ok {
+		return stats, errors.New("cpu.stat has no throttling statistics")
+	}
+
+	stats.NrPeriods = periods
+	stats.NrThrottled = values["nr_throttled"]
+	if usec, ok := values["throttled_usec"]; ok {
+		stats.ThrottledUsec = usec
+	} else {
+		stats.ThrottledUsec = values["throttled_time"] / 1000
+	}
+	if stats.NrPeriods > 0 {
+		stats.ThrottledPercent = float64(stats.NrThrottled) / float64(stats.NrPeriods) * 100
+	}
+	return stats, nil
+}
+
+// IOStat holds cumulative block I/O counters of the cgroup for one device
+type IOStat struct {
+	ReadBytes  int64 `json:"read_bytes"`  // rbytes
+	WriteBytes int64 `json:"write_bytes"` // wbytes
+	ReadOps    int64 `json:"read_ops"`    // rios
+	WriteOps   int64 `json:"write_ops"`   // wios
+}
+
+// GetBlockIOStats returns the cgroup's block I/O counters keyed by device ("major:minor").
+// The counters are cumulative; sample twice to compute throughput.
+func (Toolbox) GetBlockIOStats() (map[string]IOStat, error) {
+	return getBlockIOStats()
+}
+
+// getBlockIOStats reads io.stat from cgroup v2, falling back to the v1 blkio controller
+func getBlockIOStats() (map[string]IOStat, error) {
+	if !isLinux() {
+		return nil, errors.New(ErrNotSupported)
+	}
+
+	if content, err := readFile(cgroupFile("io.stat")); err == nil {
+		return parseIOStat(content)
+	}
+
+	// v1 only fills the throttle files when the default I/O scheduler is in use
+	var found bool
+	var lastErr error
+	stats := make(map[string]IOStat)
+	for _, prefix := range []string{"blkio/blkio.throttle.", "blkio/blkio."} {
+		bytesContent, err := readFile(cgroupFile(prefix + "io_service_bytes"))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		opsContent, err := readFile(cgroupFile(prefix + "io_serviced"))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		found = true
+
+		err = parseBlkioFile(bytesContent, stats, func(stat *IOStat) (read, write *int64) {
+			return &stat.ReadBytes, &stat.WriteBytes
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = parseBlkioFile(opsContent, stats, func(stat *IOStat) (read, write *int64) {
+			return &stat.ReadOps, &stat.WriteOps
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(stats) > 0 {
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: io controller not available: %w", ErrNotSupported, lastErr)
+	}
+	return stats, nil
+}
+
+// parseIOStat parses a cgroup v2 io.stat file with lines like
+// "8:0 rbytes=1024 wbytes=2048 rios=1 wios=2 dbytes=0 dios=0"
+func parseIOStat(content string) (map[string]IOStat, error) {
+	stats := make(map[string]IOStat)
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var stat IOStat
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			var target *int64
+			switch key {
+			case "rbytes":
+				target = &stat.ReadBytes
+			case "wbytes":
+				target = &stat.WriteBytes
+			case "rios":
+				target = &stat.ReadOps
+			case "wios":
+				target = &stat.WriteOps
+			default:
+				continue
+			}
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", ErrParsingValue, key, err)
+			}
+			*target = n
+		}
+		stats[fields[0]] = stat
+	}
+	return stats, nil
+}
+
+// parseBlkioFile adds the Read and Write lines of a v1 blkio file such as
+// "8:0 Read 1024" into stats, storing them in the fields chosen by target
+func parseBlkioFile(content string, stats map[string]IOStat, target func(*IOStat) (read, write *int64)) error {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		// Skips the trailing "Total <n>" line
+		if len(fields) != 3 {
+			continue
+		}
+
+		stat := stats[fields[0]]
+		read, write := target(&stat)
+		var dst *int64
+		switch fields[1] {
+		case "Read":
+			dst = read
+		case "Write":
+			dst = write
+		default:
+			continue
+		}
+		n, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", ErrParsingValue, fields[0], err)
+		}
+		*dst = n
+		stats[fields[0]] = stat
+	}
+	return nil
+}
+
+// getAvailableCPUs returns the number of CPUs the cgroup's cpuset allows
+// (cpuset.cpus.effective on v2, cpuset.cpus on v1), or the host CPU count
+// from /proc/cpuinfo when there is no cpuset. The count is cached for the
+// limit cache TTL.
+func getAvailableCPUs() (float64, error) {
+	return availableCPUsCache.get(readAvailableCPUs)
+}
+
+// readAvailableCPUs reads the CPU count, see getAvailableCPUs
+func readAvailableCPUs() (float64, error) {
+	for _, file := range []string{"cpuset.cpus.effective", "cpuset/cpuset.cpus"} {
+		content, err := readFile(cgroupFile(file))
+		if err != nil {
+			continue
+		}
+		if count, err := countCPUList(content); err == nil && count > 0 {
+			return float64(count), nil
+		}
+	}
+	return getNumCPUs()
+}
+
+// countCPUList counts the CPUs in a cpuset list such as "0-3,8,10-11"
+func countCPUList(content string) (int, error) {
+	count := 0
+	for _, part := range strings.Split(strings.TrimSpace(content), ",") {
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %q: %w", ErrParsingValue, part, err)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil {
+				return 0, fmt.Errorf("%s: %q: %w", ErrParsingValue, part, err)
+			}
+		}
+		if end < start {
+			return 0, fmt.Errorf("invalid CPU range: %q", part)
+		}
+		count += end - start + 1
+	}
+	return count, nil
+}
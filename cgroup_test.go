@@ -0,0 +1,710 @@
+package toolbox
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCgroupFile creates a file under a fixture cgroup tree
+func writeCgroupFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	fullPath := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatalf("Failed to create fixture directory: %v", err)
+	}
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write fixture file: %v", err)
+	}
+}
+
+func TestParseProcCgroup(t *testing.T) {
+	content := `12:memory:/kubepods/burstable/pod123/abc
+4:cpu,cpuacct:/kubepods/burstable/pod123/abc
+1:name=systemd:/kubepods/burstable/pod123/abc
+0::/kubepods.slice/pod123.slice/cri-containerd-abc.scope
+`
+	paths := parseProcCgroup(content)
+
+	if paths["memory"] != "/kubepods/burstable/pod123/abc" {
+		t.Errorf("Unexpected memory path: %s", paths["memory"])
+	}
+	if paths["cpu"] != "/kubepods/burstable/pod123/abc" || paths["cpuacct"] != paths["cpu"] {
+		t.Errorf("Expected cpu and cpuacct to share a path, got %q and %q", paths["cpu"], paths["cpuacct"])
+	}
+	if paths[""] != "/kubepods.slice/pod123.slice/cri-containerd-abc.scope" {
+		t.Errorf("Unexpected unified path: %s", paths[""])
+	}
+}
+
+func TestParseCgroupMemoryLimit(t *testing.T) {
+	limit, unlimited, err := parseCgroupMemoryLimit("536870912\n")
+	if err != nil || unlimited || limit != 536870912 {
+		t.Errorf("Expected 536870912, got %d (unlimited=%v, err=%v)", limit, unlimited, err)
+	}
+
+	if _, unlimited, err := parseCgroupMemoryLimit("max\n"); err != nil || !unlimited {
+		t.Errorf("Expected 'max' to be unlimited (err=%v)", err)
+	}
+
+	if _, unlimited, err := parseCgroupMemoryLimit("9223372036854771712"); err != nil || !unlimited {
+		t.Errorf("Expected v1 sentinel to be unlimited (err=%v)", err)
+	}
+
+	if _, _, err := parseCgroupMemoryLimit("garbage"); err == nil {
+		t.Error("Expected error for invalid limit")
+	}
+
+	suffixed := map[string]int64{
+		"512M\n": 512 << 20,
+		"512m":   512 << 20,
+		"64K":    64 << 10,
+		"2G":     2 << 30,
+		"256Mi":  256 << 20,
+		"1Gi\n":  1 << 30,
+		"8ki":    8 << 10,
+	}
+	for content, expected := range suffixed {
+		limit, unlimited, err := parseCgroupMemoryLimit(content)
+		if err != nil || unlimited || limit != expected {
+			t.Errorf("parseCgroupMemoryLimit(%q): expected %d, got %d (unlimited=%v, err=%v)", content, expected, limit, unlimited, err)
+		}
+	}
+
+	// Too large for int64 once scaled
+	if _, unlimited, err := parseCgroupMemoryLimit("9223372036854775807G"); err != nil || !unlimited {
+		t.Errorf("Expected an overflowing suffixed limit to be unlimited (err=%v)", err)
+	}
+
+	for _, content := range []string{"M", "12T", "1.5G", "-G"} {
+		if _, _, err := parseCgroupMemoryLimit(content); err == nil {
+			t.Errorf("Expected error for %q", content)
+		}
+	}
+}
+
+func TestGetMemoryLimitSuffixed(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	root := t.TempDir()
+	writeCgroupFile(t, root, "memory.max", "512M\n")
+	writeCgroupFile(t, root, "memory.current", "104857600\n")
+	Configure(Options{CgroupRoot: root})
+	t.Cleanup(func() { Configure(Options{}) })
+
+	limit, err := Toolbox{}.GetMemoryLimit()
+	if err != nil {
+		t.Fatalf("GetMemoryLimit() error: %v", err)
+	}
+	if limit != 512<<20 {
+		t.Errorf("Expected the suffixed cgroup limit instead of host memory, got %d", limit)
+	}
+}
+
+func TestMinCgroupLimit(t *testing.T) {
+	root := t.TempDir()
+	writeCgroupFile(t, root, "memory.max", "max\n")
+	writeCgroupFile(t, root, "kubepods/memory.max", "8589934592\n")
+	writeCgroupFile(t, root, "kubepods/pod123/memory.max", "1073741824\n")
+	writeCgroupFile(t, root, "kubepods/pod123/container/memory.max", "max\n")
+
+	// The pod ancestor enforces the limit even though the leaf is unlimited
+	limit, found, err := minCgroupLimit(root, "/kubepods/pod123/container", "memory.max", parseCgroupMemoryLimit)
+	if err != nil {
+		t.Fatalf("minCgroupLimit failed: %v", err)
+	}
+	if !found || limit != 1073741824 {
+		t.Errorf("Expected limit 1073741824, got %d (found=%v)", limit, found)
+	}
+
+	// Unlimited everywhere
+	_, found, err = minCgroupLimit(root, "/", "memory.max", parseCgroupMemoryLimit)
+	if err != nil {
+		t.Fatalf("minCgroupLimit failed: %v", err)
+	}
+	if found {
+		t.Error("Expected no limit at the root")
+	}
+
+	// Invalid content is reported
+	writeCgroupFile(t, root, "broken/memory.max", "garbage\n")
+	if _, _, err := minCgroupLimit(root, "/broken", "memory.max", parseCgroupMemoryLimit); err == nil {
+		t.Error("Expected error for invalid memory.max")
+	}
+}
+
+func TestGetMemoryLimitHierarchical(t *testing.T) {
+	toolbox := Toolbox{}
+	limit, err := toolbox.GetMemoryLimitHierarchical()
+	if err != nil {
+		t.Logf("GetMemoryLimitHierarchical failed (expected in test environment): %v", err)
+		return
+	}
+
+	if limit <= 0 {
+		t.Errorf("Expected memory limit > 0, got %d", limit)
+	}
+
+	t.Logf("Hierarchical memory limit: %d bytes (%.2f MB)", limit, float64(limit)/(1024*1024))
+}
+
+func TestParseFlatKeyedFile(t *testing.T) {
+	content := `anon 104857600
+file 52428800
+kernel_stack 1048576
+shmem 0
+`
+	values, err := parseFlatKeyedFile(content)
+	if err != nil {
+		t.Fatalf("parseFlatKeyedFile failed: %v", err)
+	}
+
+	if len(values) != 4 {
+		t.Errorf("Expected 4 entries, got %d", len(values))
+	}
+	if values["anon"] != 104857600 || values["file"] != 52428800 {
+		t.Errorf("Unexpected values: %v", values)
+	}
+
+	if _, err := parseFlatKeyedFile("anon abc\n"); err == nil {
+		t.Error("Expected error for non-numeric value")
+	}
+	if _, err := parseFlatKeyedFile("anon 1 2\n"); err == nil {
+		t.Error("Expected error for malformed line")
+	}
+}
+
+func TestGetMemoryStat(t *testing.T) {
+	toolbox := Toolbox{}
+	stat, err := toolbox.GetMemoryStat()
+	if err != nil {
+		t.Logf("GetMemoryStat failed (expected in test environment): %v", err)
+		return
+	}
+
+	if len(stat) == 0 {
+		t.Error("Expected non-empty memory.stat")
+	}
+
+	t.Logf("memory.stat: %d entries", len(stat))
+}
+
+func TestIsSwapAccountingEnabled(t *testing.T) {
+	toolbox := Toolbox{}
+	enabled := toolbox.IsSwapAccountingEnabled()
+
+	if !isLinux() && enabled {
+		t.Error("Expected swap accounting to be disabled outside Linux")
+	}
+
+	t.Logf("Swap accounting enabled: %v", enabled)
+}
+
+func TestMemoryLRUStatsFromStat(t *testing.T) {
+	// cgroup v2
+	stat := map[string]int64{
+		"anon":          300,
+		"active_anon":   100,
+		"inactive_anon": 200,
+		"active_file":   400,
+		"inactive_file": 800,
+	}
+	stats, err := memoryLRUStatsFromStat(stat)
+	if err != nil {
+		t.Fatalf("memoryLRUStatsFromStat failed: %v", err)
+	}
+	if stats.ActiveAnonBytes != 100 || stats.InactiveAnonBytes != 200 ||
+		stats.ActiveFileBytes != 400 || stats.InactiveFileBytes != 800 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// cgroup v1 prefers hierarchical totals
+	stat["total_inactive_file"] = 1600
+	stats, err = memoryLRUStatsFromStat(stat)
+	if err != nil {
+		t.Fatalf("memoryLRUStatsFromStat failed: %v", err)
+	}
+	if stats.InactiveFileBytes != 1600 {
+		t.Errorf("Expected total_inactive_file 1600, got %d", stats.InactiveFileBytes)
+	}
+
+	delete(stat, "active_file")
+	if _, err := memoryLRUStatsFromStat(stat); err == nil {
+		t.Error("Expected error when active_file is missing")
+	}
+}
+
+func TestParseNUMAStat(t *testing.T) {
+	// cgroup v1 counts pages and reports a total line
+	v1 := "total=300 N0=200 N1=100\nfile=100 N0=80 N1=20\nanon=200 N0=120 N1=80\nhierarchical_total=900 N0=600 N1=300\n"
+	nodes, err := parseNUMAStat(v1, 4096)
+	if err != nil {
+		t.Fatalf("parseNUMAStat failed on v1: %v", err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("Expected 2 nodes, got %d", len(nodes))
+	}
+	if nodes["N0"].AnonBytes != 120*4096 || nodes["N0"].FileBytes != 80*4096 || nodes["N0"].TotalBytes != 200*4096 {
+		t.Errorf("Unexpected v1 N0 stats: %+v", nodes["N0"])
+	}
+
+	// cgroup v2 is in bytes and has no total line
+	v2 := "anon N0=40960 N1=8192\nfile N0=4096 N1=0\nkernel_stack N0=16384 N1=0\n"
+	nodes, err = parseNUMAStat(v2, 4096)
+	if err != nil {
+		t.Fatalf("parseNUMAStat failed on v2: %v", err)
+	}
+	if nodes["N0"].AnonBytes != 40960 || nodes["N0"].TotalBytes != 45056 {
+		t.Errorf("Unexpected v2 N0 stats: %+v", nodes["N0"])
+	}
+	if nodes["N1"].TotalBytes != 8192 {
+		t.Errorf("Expected N1 total 8192, got %d", nodes["N1"].TotalBytes)
+	}
+
+	if _, err := parseNUMAStat("anon N0=abc\n", 4096); err == nil {
+		t.Error("Expected error for invalid value")
+	}
+	if _, err := parseNUMAStat("", 4096); err == nil {
+		t.Error("Expected error for empty content")
+	}
+}
+
+func TestGetMemoryNUMAStats(t *testing.T) {
+	toolbox := Toolbox{}
+	nodes, err := toolbox.GetMemoryNUMAStats()
+	if err != nil {
+		t.Logf("GetMemoryNUMAStats failed (expected outside containers): %v", err)
+		return
+	}
+
+	for node, stats := range nodes {
+		if stats.TotalBytes < 0 {
+			t.Errorf("Expected non-negative total on %s, got %d", node, stats.TotalBytes)
+		}
+	}
+
+	t.Logf("NUMA memory: %+v", nodes)
+}
+
+func TestParseThrottleStats(t *testing.T) {
+	v2 := `usage_usec 1000000
+user_usec 600000
+system_usec 400000
+nr_periods 200
+nr_throttled 50
+throttled_usec 250000
+`
+	stats, err := parseThrottleStats(v2)
+	if err != nil {
+		t.Fatalf("parseThrottleStats(v2) error: %v", err)
+	}
+	expected := ThrottleStats{NrPeriods: 200, NrThrottled: 50, ThrottledUsec: 250000, ThrottledPercent: 25}
+	if stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+
+	// v1 reports throttled time in nanoseconds
+	v1 := "nr_periods 10\nnr_throttled 1\nthrottled_time 3000000\n"
+	stats, err = parseThrottleStats(v1)
+	if err != nil {
+		t.Fatalf("parseThrottleStats(v1) error: %v", err)
+	}
+	expected = ThrottleStats{NrPeriods: 10, NrThrottled: 1, ThrottledUsec: 3000, ThrottledPercent: 10}
+	if stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+
+	// No quota has ever applied
+	stats, err = parseThrottleStats("nr_periods 0\nnr_throttled 0\nthrottled_usec 0\n")
+	if err != nil || stats.ThrottledPercent != 0 {
+		t.Errorf("Expected zero stats, got %+v, %v", stats, err)
+	}
+
+	// v2 cpu.stat without the cpu controller has no throttling keys
+	if _, err := parseThrottleStats("usage_usec 1\nuser_usec 1\nsystem_usec 0\n"); err == nil {
+		t.Error("Expected error without nr_periods")
+	}
+}
+
+func TestGetCPUThrottlingStatsFallsBackToV1(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	root := t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	t.Cleanup(func() { Configure(Options{}) })
+
+	// A v2 cpu.stat without throttling keys is skipped in favour of v1
+	writeCgroupFile(t, root, "cpu.stat", "usage_usec 1\n")
+	writeCgroupFile(t, root, "cpu,cpuacct/cpu.stat", "nr_periods 4\nnr_throttled 2\nthrottled_time 5000\n")
+
+	stats, err := Toolbox{}.GetCPUThrottlingStats()
+	if err != nil {
+		t.Fatalf("GetCPUThrottlingStats() error: %v", err)
+	}
+	if stats.NrThrottled != 2 || stats.ThrottledUsec != 5 || stats.ThrottledPercent != 50 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestCachedBytesFromStat(t *testing.T) {
+	tests := []struct {
+		name     string
+		stat     map[string]int64
+		expected int64
+		found    bool
+	}{
+		{"v2", map[string]int64{"anon": 100, "file": 300}, 300, true},
+		{"v1 hierarchical", map[string]int64{"cache": 10, "total_cache": 40}, 40, true},
+		{"v1", map[string]int64{"cache": 10, "rss": 5}, 10, true},
+		{"missing", map[string]int64{"anon": 100}, 0, false},
+	}
+	for _, tt := range tests {
+		cached, found := cachedBytesFromStat(tt.stat)
+		if cached != tt.expected || found != tt.found {
+			t.Errorf("%s: expected %d/%v, got %d/%v", tt.name, tt.expected, tt.found, cached, found)
+		}
+	}
+}
+
+func TestGetMemoryInfoCgroupCachedBytes(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	root := t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	t.Cleanup(func() { Configure(Options{}) })
+
+	writeCgroupFile(t, root, "memory.max", "1073741824\n")
+	writeCgroupFile(t, root, "memory.current", "536870912\n")
+	writeCgroupFile(t, root, "memory.stat", "anon 268435456\nfile 201326592\nkernel 1048576\n")
+
+	info, err := getMemoryInfoCgroup()
+	if err != nil {
+		t.Fatalf("getMemoryInfoCgroup() error: %v", err)
+	}
+	if info.CachedBytes != 201326592 {
+		t.Errorf("Expected cached bytes from the file entry, got %d", info.CachedBytes)
+	}
+	if !info.LimitIsSet || info.LimitSource != "cgroup" {
+		t.Errorf("Expected a cgroup limit source, got %v/%q", info.LimitIsSet, info.LimitSource)
+	}
+}
+
+func TestGetMemoryLimitWithSource(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	t.Cleanup(func() { Configure(Options{}) })
+
+	root := t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	writeCgroupFile(t, root, "memory.max", "536870912\n")
+
+	limit, source, err := getMemoryLimitWithSource()
+	if err != nil || limit != 536870912 || source != "cgroup" {
+		t.Errorf("Expected cgroup limit 536870912, got %d/%q (%v)", limit, source, err)
+	}
+
+	root = t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	writeCgroupFile(t, root, "memory.max", "max\n")
+
+	limit, source, err = getMemoryLimitWithSource()
+	if err != nil {
+		t.Fatalf("getMemoryLimitWithSource() error: %v", err)
+	}
+	if source != "system" || limit <= 0 {
+		t.Errorf("Expected system memory for an unlimited cgroup, got %d/%q", limit, source)
+	}
+}
+
+func TestParseIOStat(t *testing.T) {
+	content := "8:0 rbytes=1048576 wbytes=2097152 rios=16 wios=32 dbytes=0 dios=0\n" +
+		"253:1 rbytes=4096 wbytes=0 rios=1 wios=0 dbytes=0 dios=0\n"
+
+	stats, err := parseIOStat(content)
+	if err != nil {
+		t.Fatalf("parseIOStat failed: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 devices, got %d", len(stats))
+	}
+	expected := IOStat{ReadBytes: 1048576, WriteBytes: 2097152, ReadOps: 16, WriteOps: 32}
+	if stats["8:0"] != expected {
+		t.Errorf("Expected %+v for 8:0, got %+v", expected, stats["8:0"])
+	}
+	if stats["253:1"].ReadBytes != 4096 {
+		t.Errorf("Expected 4096 read bytes for 253:1, got %d", stats["253:1"].ReadBytes)
+	}
+
+	if _, err := parseIOStat("8:0 rbytes=abc\n"); err == nil {
+		t.Error("Expected error for invalid value")
+	}
+}
+
+func TestGetBlockIOStatsV1(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	t.Cleanup(func() { Configure(Options{}) })
+
+	root := t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	writeCgroupFile(t, root, "blkio/blkio.throttle.io_service_bytes",
+		"8:0 Read 1048576\n8:0 Write 2097152\n8:0 Sync 0\n8:0 Async 3145728\n8:0 Total 3145728\nTotal 3145728\n")
+	writeCgroupFile(t, root, "blkio/blkio.throttle.io_serviced",
+		"8:0 Read 16\n8:0 Write 32\n8:0 Total 48\nTotal 48\n")
+
+	stats, err := getBlockIOStats()
+	if err != nil {
+		t.Fatalf("getBlockIOStats() error: %v", err)
+	}
+	expected := IOStat{ReadBytes: 1048576, WriteBytes: 2097152, ReadOps: 16, WriteOps: 32}
+	if len(stats) != 1 || stats["8:0"] != expected {
+		t.Errorf("Expected %+v for 8:0, got %+v", expected, stats)
+	}
+
+	Configure(Options{CgroupRoot: t.TempDir()})
+	if _, err := getBlockIOStats(); err == nil || !strings.Contains(err.Error(), ErrNotSupported) {
+		t.Errorf("Expected not supported error without an io controller, got %v", err)
+	}
+}
+
+func TestCountCPUList(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected int
+		wantErr  bool
+	}{
+		{"0-3\n", 4, false},
+		{"0-3,8,10-11\n", 7, false},
+		{"5", 1, false},
+		{"\n", 0, false},
+		{"3-1", 0, true},
+		{"a-b", 0, true},
+	}
+
+	for _, tt := range tests {
+		count, err := countCPUList(tt.content)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("countCPUList(%q) error = %v, wantErr %v", tt.content, err, tt.wantErr)
+			continue
+		}
+		if count != tt.expected {
+			t.Errorf("countCPUList(%q) = %d, expected %d", tt.content, count, tt.expected)
+		}
+	}
+}
+
+func TestCPULimitUsesCpuset(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	t.Cleanup(func() { Configure(Options{}) })
+
+	root := t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	writeCgroupFile(t, root, "cpu.max", "max 100000\n")
+	writeCgroupFile(t, root, "cpuset.cpus.effective", "2-3\n")
+
+	limit, err := readCgroupV2CPULimit()
+	if err != nil || limit != 2 {
+		t.Errorf("Expected 2 cores from cpuset.cpus.effective, got %f (%v)", limit, err)
+	}
+
+	root = t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	writeCgroupFile(t, root, "cpu,cpuacct/cpu.cfs_quota_us", "-1\n")
+	writeCgroupFile(t, root, "cpu,cpuacct/cpu.cfs_period_us", "100000\n")
+	writeCgroupFile(t, root, "cpuset/cpuset.cpus", "0,4\n")
+
+	limit, err = readCgroupV1CPULimit()
+	if err != nil || limit != 2 {
+		t.Errorf("Expected 2 cores from cpuset.cpus, got %f (%v)", limit, err)
+	}
+}
+
+func TestNestedCgroupFile(t *testing.T) {
+	root := t.TempDir()
+	writeCgroupFile(t, root, "memory/kubepods/pod1/memory.limit_in_bytes", "536870912\n")
+	writeCgroupFile(t, root, "cpu,cpuacct/kubepods/pod1/cpu.cfs_quota_us", "50000\n")
+	writeCgroupFile(t, root, "system.slice/k6.service/memory.max", "1073741824\n")
+
+	v1 := map[string]string{"memory": "/kubepods/pod1", "cpu": "/kubepods/pod1", "cpuacct": "/kubepods/pod1", "blkio": "/"}
+	v2 := map[string]string{"": "/system.slice/k6.service"}
+
+	tests := []struct {
+		rel      string
+		paths    map[string]string
+		expected string // Empty when the root file should be used
+	}{
+		{"memory/memory.limit_in_bytes", v1, "memory/kubepods/pod1/memory.limit_in_bytes"},
+		{"cpu,cpuacct/cpu.cfs_quota_us", v1, "cpu,cpuacct/kubepods/pod1/cpu.cfs_quota_us"},
+		{"memory/memory.usage_in_bytes", v1, ""}, // Missing in the nested cgroup
+		{"blkio/blkio.throttle.io_serviced", v1, ""},
+		{"memory.max", v2, "system.slice/k6.service/memory.max"},
+		{"memory.current", v2, ""},
+		{"memory.max", nil, ""},
+		{"", v2, ""},
+	}
+
+	for _, tt := range tests {
+		nested, ok := nestedCgroupFile(root, tt.rel, tt.paths)
+		if tt.expected == "" {
+			if ok {
+				t.Errorf("nestedCgroupFile(%q) = %q, expected the root file", tt.rel, nested)
+			}
+			continue
+		}
+		if expected := filepath.Join(root, tt.expected); !ok || nested != expected {
+			t.Errorf("nestedCgroupFile(%q) = %q/%v, expected %q", tt.rel, nested, ok, expected)
+		}
+	}
+}
+
+func TestGetMemoryEvents(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	t.Cleanup(func() { Configure(Options{}) })
+
+	root := t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	writeCgroupFile(t, root, "memory.events", "low 0\nhigh 12\nmax 3\noom 1\noom_kill 1\noom_group_kill 0\n")
+
+	toolbox := Toolbox{}
+	events, err := toolbox.GetMemoryEvents()
+	if err != nil {
+		t.Fatalf("GetMemoryEvents failed: %v", err)
+	}
+	if events["oom_kill"] != 1 || events["high"] != 12 || events["max"] != 3 {
+		t.Errorf("Unexpected v2 events: %v", events)
+	}
+
+	root = t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	writeCgroupFile(t, root, "memory/memory.oom_control", "oom_kill_disable 0\nunder_oom 0\noom_kill 2\n")
+
+	events, err = toolbox.GetMemoryEvents()
+	if err != nil {
+		t.Fatalf("GetMemoryEvents failed: %v", err)
+	}
+	if events["oom_kill"] != 2 || events["under_oom"] != 0 {
+		t.Errorf("Unexpected v1 events: %v", events)
+	}
+
+	Configure(Options{CgroupRoot: t.TempDir()})
+	if _, err := toolbox.GetMemoryEvents(); err == nil {
+		t.Error("Expected error without memory.events or memory.oom_control")
+	}
+}
+
+func TestGetMemoryInfoCgroupClampsUsageAboveLimit(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	t.Cleanup(func() { Configure(Options{}) })
+
+	root := t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	writeCgroupFile(t, root, "memory.max", "1073741824\n")
+	writeCgroupFile(t, root, "memory.current", "1100000000\n")
+
+	info, err := getMemoryInfoCgroup()
+	if err != nil {
+		t.Fatalf("getMemoryInfoCgroup() error: %v", err)
+	}
+	if !info.Clamped || info.AvailableBytes != 0 || info.UsagePercent != 100 {
+		t.Errorf("Expected clamped 0 available and 100%%, got %+v", info)
+	}
+	if info.UsageBytes != 1100000000 {
+		t.Errorf("Expected the raw usage to be kept, got %d", info.UsageBytes)
+	}
+
+	writeCgroupFile(t, root, "memory.current", "536870912\n")
+	info, err = getMemoryInfoCgroup()
+	if err != nil {
+		t.Fatalf("getMemoryInfoCgroup() error: %v", err)
+	}
+	if info.Clamped || info.UsagePercent != 50 {
+		t.Errorf("Expected unclamped 50%%, got %+v", info)
+	}
+}
+
+func TestApplyCgroupCPUUsageClamps(t *testing.T) {
+	info := CPUInfo{LimitCores: 2}
+	applyCgroupCPUUsage(&info, 2.2)
+	if !info.Clamped || info.Available != 0 || info.UsagePercent != 100 || info.UsedCores != 2.2 {
+		t.Errorf("Expected clamped 0 available and 100%% with raw used cores, got %+v", info)
+	}
+
+	info = CPUInfo{LimitCores: 2}
+	applyCgroupCPUUsage(&info, 0.5)
+	if info.Clamped || info.Available != 1.5 || info.UsagePercent != 25 {
+		t.Errorf("Expected unclamped 25%%, got %+v", info)
+	}
+}
+
+func TestReadCgroupFile(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+
+	root := t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	writeCgroupFile(t, root, "memory/memory.limit_in_bytes", "536870912\n")
+
+	toolbox := Toolbox{}
+	content, err := toolbox.ReadCgroupFile("memory/memory.limit_in_bytes")
+	if err != nil || content != "536870912\n" {
+		t.Errorf("Expected the raw file contents, got %q (%v)", content, err)
+	}
+
+	for _, rel := range []string{"", "../etc/passwd", "memory/../../etc/passwd", "/etc/passwd"} {
+		if _, err := toolbox.ReadCgroupFile(rel); err == nil {
+			t.Errorf("Expected %q to be rejected", rel)
+		}
+	}
+	if _, err := toolbox.ReadCgroupFile("missing"); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+}
+
+func TestGetMemoryPeak(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	t.Cleanup(func() { Configure(Options{}) })
+	toolbox := Toolbox{}
+
+	// cgroup v2
+	root := t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	writeCgroupFile(t, root, "memory.current", "536870912\n")
+	writeCgroupFile(t, root, "memory.peak", "805306368\n")
+	if peak, err := toolbox.GetMemoryPeak(); err != nil || peak != 805306368 {
+		t.Errorf("Expected peak 805306368 from memory.peak, got %d (%v)", peak, err)
+	}
+
+	// cgroup v2 on a kernel without memory.peak
+	root = t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	writeCgroupFile(t, root, "memory.current", "536870912\n")
+	if _, err := toolbox.GetMemoryPeak(); err == nil || !strings.Contains(err.Error(), ErrNotSupported) {
+		t.Errorf("Expected a not supported error without memory.peak, got %v", err)
+	}
+
+	// cgroup v1
+	root = t.TempDir()
+	Configure(Options{CgroupRoot: root})
+	writeCgroupFile(t, root, "memory/memory.max_usage_in_bytes", "268435456\n")
+	if peak, err := toolbox.GetMemoryPeak(); err != nil || peak != 268435456 {
+		t.Errorf("Expected peak 268435456 from max_usage_in_bytes, got %d (%v)", peak, err)
+	}
+
+	Configure(Options{CgroupRoot: t.TempDir()})
+	if _, err := toolbox.GetMemoryPeak(); err == nil {
+		t.Error("Expected error without cgroup files")
+	}
+}
@@ -0,0 +1,101 @@
+package toolbox
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// ClockInfo describes the system clock and its synchronization state
+type ClockInfo struct {
+	Timezone         string `json:"timezone"`                                   // IANA name when known, zone abbreviation otherwise
+	UTCOffsetSeconds int    `json:"utc_offset_seconds" js:"utc_offset_seconds"` // Current offset from UTC
+	CurrentTime      string `json:"current_time"`                               // RFC 3339 timestamp with nanoseconds
+	UnixMillis       int64  `json:"unix_millis"`
+	NTPSynchronized  bool   `json:"ntp_synchronized" js:"ntp_synchronized"` // Only meaningful when NTPSource is not "unavailable"
+	NTPSource        string `json:"ntp_source" js:"ntp_source"`             // How the sync state was determined
+}
+
+// GetClockInfo returns the system timezone, current time and NTP sync state
+func (Toolbox) GetClockInfo() (ClockInfo, error) {
+	return getClockInfo(), nil
+}
+
+// getClockInfo collects clock information, preferring timedatectl when present
+// and falling back to the kernel's adjtimex state for the sync status, so hosts
+// and containers without systemd still report it
+func getClockInfo() ClockInfo {
+	now := time.Now()
+	abbrev, offset := now.Zone()
+
+	info := ClockInfo{
+		UTCOffsetSeconds: offset,
+		CurrentTime:      now.Format(time.RFC3339Nano),
+		UnixMillis:       now.UnixMilli(),
+		NTPSource:        "unavailable",
+	}
+
+	if isLinux() {
+		output, err := commandOutput("timedatectl", "show", "--property=Timezone", "--property=NTPSynchronized")
+		if err == nil {
+			timezone, synced, ok := parseTimedatectlOutput(string(output))
+			if ok {
+				info.Timezone = timezone
+				info.NTPSynchronized = synced
+				info.NTPSource = "timedatectl"
+			}
+		}
+	}
+	if info.NTPSource == "unavailable" {
+		if sync, err := readAdjtimexTimeSync(); err == nil {
+			info.NTPSynchronized = sync.Synchronized
+			info.NTPSource = sync.Source
+		}
+	}
+
+	if info.Timezone == "" {
+		info.Timezone = getTimezoneName()
+	}
+	if info.Timezone == "" {
+		info.Timezone = abbrev
+	}
+
+	return info
+}
+
+// parseTimedatectlOutput parses `timedatectl show` key=value output.
+// ok is false when the NTPSynchronized property is missing.
+func parseTimedatectlOutput(output string) (timezone string, synced bool, ok bool) {
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "Timezone":
+			timezone = value
+		case "NTPSynchronized":
+			synced = value == "yes"
+			ok = true
+		}
+	}
+	return timezone, synced, ok
+}
+
+// getTimezoneName returns the IANA timezone name from TZ, /etc/timezone or /etc/localtime
+func getTimezoneName() string {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		return tz
+	}
+	if content, err := readFile("/etc/timezone"); err == nil {
+		if tz := strings.TrimSpace(content); tz != "" {
+			return tz
+		}
+	}
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if idx := strings.Index(target, "zoneinfo/"); idx != -1 {
+			return target[idx+len("zoneinfo/"):]
+		}
+	}
+	return ""
+}
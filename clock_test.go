@@ -0,0 +1,73 @@
+package toolbox
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimedatectlOutput(t *testing.T) {
+	output := `Timezone=Europe/Berlin
+LocalRTC=no
+NTPSynchronized=yes
+`
+	timezone, synced, ok := parseTimedatectlOutput(output)
+	if !ok {
+		t.Fatal("Expected NTPSynchronized to be found")
+	}
+	if timezone != "Europe/Berlin" {
+		t.Errorf("Expected timezone 'Europe/Berlin', got '%s'", timezone)
+	}
+	if !synced {
+		t.Error("Expected NTP to be synchronized")
+	}
+
+	_, synced, ok = parseTimedatectlOutput("Timezone=UTC\nNTPSynchronized=no\n")
+	if !ok || synced {
+		t.Errorf("Expected unsynchronized clock, got synced=%v ok=%v", synced, ok)
+	}
+
+	// Missing property
+	if _, _, ok := parseTimedatectlOutput("Timezone=UTC\n"); ok {
+		t.Error("Expected ok=false when NTPSynchronized is missing")
+	}
+}
+
+func TestGetClockInfo(t *testing.T) {
+	toolbox := Toolbox{}
+	info, err := toolbox.GetClockInfo()
+	if err != nil {
+		t.Fatalf("GetClockInfo failed: %v", err)
+	}
+
+	if info.Timezone == "" {
+		t.Error("Expected non-empty timezone")
+	}
+	if _, err := time.Parse(time.RFC3339Nano, info.CurrentTime); err != nil {
+		t.Errorf("Expected RFC 3339 current time, got '%s': %v", info.CurrentTime, err)
+	}
+	if info.UnixMillis <= 0 {
+		t.Errorf("Expected positive unix millis, got %d", info.UnixMillis)
+	}
+
+	t.Logf("Clock info: %+v", info)
+}
+
+func TestGetClockInfoWithoutTimedatectl(t *testing.T) {
+	useFakeRunner(t, fakeRunner{})
+
+	info, err := Toolbox{}.GetClockInfo()
+	if err != nil {
+		t.Fatalf("GetClockInfo failed: %v", err)
+	}
+
+	sync, err := readAdjtimexTimeSync()
+	if err != nil {
+		if info.NTPSource != "unavailable" {
+			t.Errorf("Expected no sync source without timedatectl or adjtimex, got %q", info.NTPSource)
+		}
+		return
+	}
+	if info.NTPSource != "adjtimex" || info.NTPSynchronized != sync.Synchronized {
+		t.Errorf("Expected the adjtimex sync state %v, got %+v", sync.Synchronized, info)
+	}
+}
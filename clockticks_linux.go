@@ -0,0 +1,29 @@
+//go:build linux
+
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// atClockTicks is AT_CLKTCK, the auxiliary vector entry holding the value
+// sysconf(_SC_CLK_TCK) returns
+const atClockTicks = 17
+
+// readClockTicks reads USER_HZ from the process's ELF auxiliary vector, where
+// libc's sysconf(_SC_CLK_TCK) gets it
+func readClockTicks() (int64, error) {
+	auxv, err := unix.Auxv()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the auxiliary vector: %w", err)
+	}
+	for _, entry := range auxv {
+		if entry[0] == atClockTicks && entry[1] > 0 {
+			return int64(entry[1]), nil
+		}
+	}
+	return 0, errors.New("AT_CLKTCK not found in the auxiliary vector")
+}
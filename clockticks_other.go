@@ -0,0 +1,10 @@
+//go:build !linux
+
+package toolbox
+
+import "errors"
+
+// readClockTicks is only implemented on Linux, the only platform with /proc CPU times
+func readClockTicks() (int64, error) {
+	return 0, errors.New(ErrNotSupported)
+}
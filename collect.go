@@ -0,0 +1,74 @@
+package toolbox
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// metricCollector collects a single named metric
+type metricCollector func(tb Toolbox) (interface{}, error)
+
+// metricCollectors maps the metric names accepted by Collect to their collectors
+var metricCollectors = map[string]metricCollector{
+	"cpu_usage":     func(tb Toolbox) (interface{}, error) { return tb.GetCPUUsage() },
+	"cpu_limit":     func(tb Toolbox) (interface{}, error) { return tb.GetCPULimit() },
+	"cpu_available": func(tb Toolbox) (interface{}, error) { return tb.GetAvailableCPU() },
+	"mem_usage":     func(tb Toolbox) (interface{}, error) { return tb.GetMemoryUsage() },
+	"mem_limit":     func(tb Toolbox) (interface{}, error) { return tb.GetMemoryLimit() },
+	"mem_percent":   func(tb Toolbox) (interface{}, error) { return tb.GetMemoryUsagePercent() },
+	"mem_available": func(tb Toolbox) (interface{}, error) { return tb.GetAvailableMemory() },
+	"swap_usage":    func(tb Toolbox) (interface{}, error) { return tb.GetSwapUsage() },
+	"load1":         func(Toolbox) (interface{}, error) { return loadAverageField(0) },
+	"load5":         func(Toolbox) (interface{}, error) { return loadAverageField(1) },
+	"load15":        func(Toolbox) (interface{}, error) { return loadAverageField(2) },
+}
+
+// Collect collects the requested metrics and returns them keyed by name.
+// Unknown names are rejected up front; a metric that fails to collect is
+// returned as null so one unavailable metric doesn't fail the whole batch.
+func (tb Toolbox) Collect(metricNames []string) (map[string]interface{}, error) {
+	var unknown []string
+	for _, name := range metricNames {
+		if _, ok := metricCollectors[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown metric names: %s (available: %s)",
+			strings.Join(unknown, ", "), strings.Join(availableMetricNames(), ", "))
+	}
+
+	result := make(map[string]interface{}, len(metricNames))
+	for _, name := range metricNames {
+		if _, done := result[name]; done {
+			continue
+		}
+		value, err := metricCollectors[name](tb)
+		if err != nil {
+			result[name] = nil
+			continue
+		}
+		result[name] = value
+	}
+	return result, nil
+}
+
+// availableMetricNames returns the sorted names accepted by Collect
+func availableMetricNames() []string {
+	names := make([]string, 0, len(metricCollectors))
+	for name := range metricCollectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadAverageField returns the 1, 5 or 15 minute load average (index 0, 1 or 2)
+func loadAverageField(index int) (float64, error) {
+	load, err := readLoadAverage()
+	if err != nil {
+		return 0, err
+	}
+	return [3]float64{load.One, load.Five, load.Fifteen}[index], nil
+}
@@ -0,0 +1,31 @@
+package toolbox
+
+import (
+	"testing"
+)
+
+func TestCollect(t *testing.T) {
+	toolbox := Toolbox{}
+	result, err := toolbox.Collect([]string{"mem_limit", "load1", "mem_limit"})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Errorf("Expected 2 metrics, got %d: %v", len(result), result)
+	}
+	for _, name := range []string{"mem_limit", "load1"} {
+		if _, ok := result[name]; !ok {
+			t.Errorf("Expected metric '%s' in result", name)
+		}
+	}
+
+	t.Logf("Collected: %v", result)
+}
+
+func TestCollectUnknownMetric(t *testing.T) {
+	toolbox := Toolbox{}
+	if _, err := toolbox.Collect([]string{"cpu_usage", "bogus"}); err == nil {
+		t.Error("Expected error for unknown metric name")
+	}
+}
@@ -0,0 +1,108 @@
+package toolbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// CommandRunner runs a system command and returns its standard output. All
+// command-based collection goes through it, so tests can replace it with
+// SetCommandRunner to feed canned top, free, ps or vm_stat output.
+type CommandRunner interface {
+	Run(name string, args ...string) ([]byte, error)
+}
+
+// execRunner is the default CommandRunner, running commands with os/exec
+type execRunner struct{}
+
+// The runner used by commandOutput, set by SetCommandRunner
+var (
+	commandRunnerMu sync.RWMutex
+	commandRunner   CommandRunner = execRunner{}
+)
+
+// SetCommandRunner replaces the runner used for system commands. nil restores
+// the default, which executes them.
+func SetCommandRunner(runner CommandRunner) {
+	commandRunnerMu.Lock()
+	defer commandRunnerMu.Unlock()
+
+	if runner == nil {
+		runner = execRunner{}
+	}
+	commandRunner = runner
+}
+
+// commandOutput runs a system command through the configured CommandRunner
+func commandOutput(name string, args ...string) ([]byte, error) {
+	commandRunnerMu.RLock()
+	runner := commandRunner
+	commandRunnerMu.RUnlock()
+
+	return runner.Run(name, args...)
+}
+
+// Run executes the command and returns its standard output. The command is
+// killed if it outlives the configured command timeout, in which case the
+// returned error wraps context.DeadlineExceeded.
+func (execRunner) Run(name string, args ...string) ([]byte, error) {
+	timeout := commandTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	// Don't wait for output pipes held open by children of a killed shell
+	cmd.WaitDelay = time.Second
+	output, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return output, fmt.Errorf("%s timed out after %v: %w", name, timeout, context.DeadlineExceeded)
+	}
+	return output, err
+}
+
+// CommandResult holds the outcome of a command run by RunCommand
+type CommandResult struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// RunCommand executes name with args and returns its standard output, standard
+// error and exit code. Only binaries listed in the CommandAllowlist option may
+// run, and none do by default. A non-zero exit code is reported in the result
+// rather than as an error; errors are returned when the command is not allowed,
+// can't be started or outlives the configured command timeout.
+func (Toolbox) RunCommand(name string, args []string) (CommandResult, error) {
+	if !commandAllowed(name) {
+		return CommandResult{}, fmt.Errorf("%s: %s", ErrCommandNotAllowed, name)
+	}
+
+	timeout := commandTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+
+	result := CommandResult{Stdout: stdout.String(), Stderr: stderr.String()}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return result, fmt.Errorf("%s: %s timed out after %v: %w", ErrCommandFailed, name, timeout, context.DeadlineExceeded)
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return result, fmt.Errorf("%s: %w", ErrCommandFailed, err)
+	}
+	return result, nil
+}
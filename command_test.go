@@ -0,0 +1,181 @@
+package toolbox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandOutput(t *testing.T) {
+	output, err := commandOutput("echo", "hello")
+	if err != nil {
+		t.Skipf("echo not available: %v", err)
+	}
+	if strings.TrimSpace(string(output)) != "hello" {
+		t.Errorf("Expected output 'hello', got %q", output)
+	}
+
+	if _, err := commandOutput("definitely-not-a-command"); err == nil {
+		t.Error("Expected error for a missing command")
+	}
+}
+
+func TestCommandOutputTimeout(t *testing.T) {
+	Configure(Options{CommandTimeoutSeconds: 0.2})
+	t.Cleanup(func() { Configure(Options{}) })
+
+	start := time.Now()
+	_, err := commandOutput("sleep", "5")
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("Expected a timeout error")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error to wrap context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("Expected the command to be killed at the timeout, took %v", elapsed)
+	}
+}
+
+func TestCommandTimeout(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+
+	Configure(Options{})
+	if timeout := commandTimeout(); timeout != defaultCommandTimeout {
+		t.Errorf("Expected default timeout %v, got %v", defaultCommandTimeout, timeout)
+	}
+
+	Configure(Options{CommandTimeoutSeconds: 1.5})
+	if timeout := commandTimeout(); timeout != 1500*time.Millisecond {
+		t.Errorf("Expected 1.5s timeout, got %v", timeout)
+	}
+}
+
+// fakeRunner returns canned output keyed by the full command line
+type fakeRunner map[string]string
+
+func (f fakeRunner) Run(name string, args ...string) ([]byte, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	output, ok := f[line]
+	if !ok {
+		return nil, errors.New("unexpected command: " + line)
+	}
+	return []byte(output), nil
+}
+
+// useFakeRunner installs runner for the duration of a test
+func useFakeRunner(t *testing.T, runner CommandRunner) {
+	t.Helper()
+	SetCommandRunner(runner)
+	t.Cleanup(func() { SetCommandRunner(nil) })
+}
+
+// fakeCommands holds output for the Linux and macOS variants of each command
+var fakeCommands = fakeRunner{
+	"nproc":             "4\n",
+	"sysctl -n hw.ncpu": "4\n",
+	"free -b": `              total        used        free      shared  buff/cache   available
+Mem:     8000000000  2000000000  4000000000    10000000  2000000000  5800000000
+Swap:             0           0           0
+`,
+	"vm_stat": `Mach Virtual Memory Statistics: (page size of 4096 bytes)
+Pages free:                              976562.
+Pages active:                            488281.
+Pages inactive:                          244140.
+Pages speculative:                            0.
+Pages wired down:                        244140.
+`,
+	"sysctl -n hw.pagesize": "4096\n",
+	"top -b -n 2 -d 0.5": `%Cpu(s): 90.0 us,  5.0 sy,  0.0 ni,  5.0 id,  0.0 wa,  0.0 hi,  0.0 si,  0.0 st
+%Cpu(s): 20.0 us,  5.0 sy,  0.0 ni, 75.0 id,  0.0 wa,  0.0 hi,  0.0 si,  0.0 st
+`,
+	"sh -c top -l 1 | grep 'CPU usage'": "CPU usage: 20.0% user, 5.0% sys, 75.0% idle\n",
+	"ps aux": `USER       PID %CPU %MEM    VSZ   RSS TTY      STAT START   TIME COMMAND
+root         1  0.0  0.1 169420 13028 ?        Ss   10:00   0:02 /sbin/init
+`,
+	"uptime": " 10:00:00 up 1 day,  2:03,  1 user,  load average: 0.50, 0.75, 1.00\n",
+}
+
+func TestSetCommandRunner(t *testing.T) {
+	useFakeRunner(t, fakeRunner{"echo hello": "canned\n"})
+
+	output, err := commandOutput("echo", "hello")
+	if err != nil || string(output) != "canned\n" {
+		t.Errorf("Expected canned output from the fake runner, got %q (%v)", output, err)
+	}
+
+	SetCommandRunner(nil)
+	if _, ok := commandRunner.(execRunner); !ok {
+		t.Errorf("Expected nil to restore the exec runner, got %T", commandRunner)
+	}
+}
+
+func TestCommandPathsWithFakeRunner(t *testing.T) {
+	if !isLinux() && !isMacOS() {
+		t.Skip("command paths are only used on Linux and macOS")
+	}
+	useFakeRunner(t, fakeCommands)
+
+	cores, err := getCPUCoresCommand()
+	if err != nil || cores != 4 {
+		t.Errorf("Expected 4 cores, got %f (%v)", cores, err)
+	}
+
+	usage, err := getCPUUsageFromTop()
+	if err != nil || usage != 25 {
+		t.Errorf("Expected 25%% CPU usage from top, got %f (%v)", usage, err)
+	}
+
+	memInfo, err := getMemoryInfoCommand()
+	if err != nil {
+		t.Fatalf("getMemoryInfoCommand failed: %v", err)
+	}
+	if memInfo.LimitBytes <= 0 || memInfo.UsagePercent <= 0 || memInfo.UsagePercent >= 100 {
+		t.Errorf("Unexpected memory info: %+v", memInfo)
+	}
+
+	toolbox := Toolbox{}
+	if output, err := toolbox.GetPsOutput(); err != nil || !strings.Contains(output, "/sbin/init") {
+		t.Errorf("Expected canned ps output, got %q (%v)", output, err)
+	}
+	if load, err := getLoadAverage(); err != nil || !strings.Contains(load, "0.50") {
+		t.Errorf("Expected load average from canned uptime, got %q (%v)", load, err)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+	toolbox := Toolbox{}
+
+	// Secure by default
+	if _, err := toolbox.RunCommand("echo", []string{"hello"}); err == nil || !strings.Contains(err.Error(), ErrCommandNotAllowed) {
+		t.Errorf("Expected %q error with an empty allowlist, got %v", ErrCommandNotAllowed, err)
+	}
+
+	Configure(Options{CommandAllowlist: []string{"sh"}})
+	if _, err := toolbox.RunCommand("/bin/sh", []string{"-c", "true"}); err == nil {
+		t.Error("Expected error for a path not listed verbatim")
+	}
+
+	result, err := toolbox.RunCommand("sh", []string{"-c", "echo out; echo err >&2; exit 3"})
+	if err != nil {
+		t.Skipf("sh not available: %v", err)
+	}
+	if result.Stdout != "out\n" || result.Stderr != "err\n" || result.ExitCode != 3 {
+		t.Errorf("Expected stdout, stderr and exit code 3 reported separately, got %+v", result)
+	}
+
+	Configure(Options{CommandAllowlist: []string{"sh"}, CommandTimeoutSeconds: 0.2})
+	if _, err := toolbox.RunCommand("sh", []string{"-c", "sleep 5"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error to wrap context.DeadlineExceeded, got %v", err)
+	}
+
+	Configure(Options{CommandAllowlist: []string{"definitely-not-a-command"}})
+	if _, err := toolbox.RunCommand("definitely-not-a-command", nil); err == nil {
+		t.Error("Expected error for a missing command")
+	}
+}
@@ -0,0 +1,54 @@
+package toolbox
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestConcurrentCollection calls the collection methods from many goroutines at
+// once, the way VUs share the module, while options are reconfigured and state is
+// reset. Run with -race to catch unguarded shared state; errors are expected and
+// ignored since only the access pattern matters here.
+func TestConcurrentCollection(t *testing.T) {
+	root := t.TempDir()
+	writeCgroupFile(t, root, "cpu.max", "200000 100000\n")
+	writeCgroupFile(t, root, "cpu.stat", "usage_usec 1000000\n")
+	writeCgroupFile(t, root, "memory.max", "1073741824\n")
+	writeCgroupFile(t, root, "memory.current", "536870912\n")
+	writeCgroupFile(t, root, "memory.stat", "anon 268435456\nfile 201326592\n")
+	Configure(Options{CgroupRoot: root})
+	t.Cleanup(func() { Configure(Options{}) })
+	useFakeRunner(t, fakeCommands)
+
+	// Shared like the module's global state, except for the per-VU memory unit
+	toolbox := Toolbox{}
+	calls := []func(){
+		func() { _, _ = toolbox.GetSystemInfo() },
+		func() { _, _ = toolbox.GetSystemInfoWithMethod("command") },
+		func() { _, _ = toolbox.GetCPUInfo() },
+		func() { _, _ = toolbox.GetMemoryInfo() },
+		func() { _, _ = toolbox.GetMemoryLimitSource() },
+		func() { _, _ = toolbox.GetCPUUsageAdaptive(60000) },
+		func() { _, _ = smoothCPUUsage(0.5, func() (float64, error) { return 50, nil }) },
+		func() { _, _ = toolbox.ReadCgroupFile("memory.current") },
+		func() { Configure(Options{CgroupRoot: root, LimitCacheTTLMs: -1}) },
+		func() { toolbox.Close() },
+	}
+
+	const goroutines = 64
+	const iterations = 20
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			vu := Toolbox{}
+			for i := 0; i < iterations; i++ {
+				calls[(g+i)%len(calls)]()
+				_ = vu.SetMemoryUnit("MB")
+				_, _ = vu.GetMemoryUsage()
+			}
+		}(g)
+	}
+	wg.Wait()
+}
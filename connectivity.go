@@ -0,0 +1,773 @@
+package toolbox
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConnectivityReport represents the result of connectivity checks at different layers
+type ConnectivityReport struct {
+	Domain         string        `json:"domain"`
+	Port           string        `json:"port"`
+	TimeoutSeconds int           `json:"timeout_seconds"`
+	Method         string        `json:"method"`                                 // HTTP method used for the HTTP check
+	Path           string        `json:"path"`                                   // HTTP path requested by the HTTP check
+	DNS            string        `json:"dns"`                                    // e.g. "success" or resolver error
+	Addresses      []string      `json:"addresses"`                              // Addresses the domain resolved to
+	Network        string        `json:"network"`                                // Address family requested: "tcp", "tcp4" or "tcp6"
+	AddressFamily  string        `json:"address_family"`                         // Family of the address TCP connected to: "ipv4" or "ipv6"
+	TCP            string        `json:"tcp"`                                    // e.g. "success" or error message
+	HTTPS          string        `json:"https"`                                  // TLS handshake "success" or error, empty for plain HTTP
+	TLSVersion     string        `json:"tls_version"`                            // Negotiated protocol version, e.g. "TLS 1.3"
+	TLSCipherSuite string        `json:"tls_cipher_suite"`                       // Negotiated cipher suite
+	CertSubject    string        `json:"cert_subject"`                           // Subject of the server's leaf certificate
+	CertNotAfter   string        `json:"cert_not_after"`                         // Expiry of the leaf certificate (RFC3339)
+	HTTP           string        `json:"http"`                                   // e.g. "success" or error message
+	Cached         bool          `json:"cached"`                                 // Whether the report was served from the connectivity cache
+	HTTPStatusCode int           `json:"http_status_code" js:"http_status_code"` // 0 if no HTTP response was received
+	HTTPAcceptable bool          `json:"http_acceptable" js:"http_acceptable"`   // Whether the status is in the acceptable set
+	FinalURL       string        `json:"final_url" js:"final_url"`               // URL of the response, after any redirects followed
+	LatencyMs      float64       `json:"latency_ms"`                             // Time from sending the HTTP request to receiving the response headers
+	Attempts       int           `json:"attempts"`                               // Number of times the check ran, 1 without retries
+	ElapsedMs      float64       `json:"elapsed_ms"`                             // Total time of all attempts including backoff
+	Layers         []LayerResult `json:"layers"`                                 // Per-layer results in pipeline order
+	FailedLayer    string        `json:"failed_layer"`                           // First layer that failed, empty if all succeeded
+}
+
+// LayerResult is the outcome of one layer of a connectivity check
+type LayerResult struct {
+	Layer     string  `json:"layer"`  // "dns", "tcp", "tls" or "http"
+	Status    string  `json:"status"` // "success", "failed" or "skipped"
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error"`
+}
+
+// maxDrainBytes bounds how much of a response body is read before closing it
+const maxDrainBytes = 64 * 1024
+
+// connectivityTransports are shared by connectivity HTTP checks so their idle
+// connections can be released by Close. There is one per network so a pooled
+// connection is never reused by a check forcing a different address family.
+var connectivityTransports = map[string]*http.Transport{
+	"tcp":  newConnectivityTransport("tcp"),
+	"tcp4": newConnectivityTransport("tcp4"),
+	"tcp6": newConnectivityTransport("tcp6"),
+}
+
+// connectivityClientKey selects a shared connectivity HTTP client
+type connectivityClientKey struct {
+	network         string
+	followRedirects bool
+}
+
+// connectivityClients are the shared HTTP clients of connectivity checks, for
+// each network with and without following redirects
+var connectivityClients = newConnectivityClients()
+
+// newConnectivityClients creates a client per network and redirect policy
+func newConnectivityClients() map[connectivityClientKey]*http.Client {
+	clients := make(map[connectivityClientKey]*http.Client, 2*len(connectivityTransports))
+	for network, transport := range connectivityTransports {
+		clients[connectivityClientKey{network, true}] = &http.Client{Transport: transport}
+		clients[connectivityClientKey{network, false}] = &http.Client{
+			Transport: transport,
+			// Report the redirect itself rather than where it leads
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+	return clients
+}
+
+// newConnectivityTransport returns an HTTP transport that only dials network.
+// A request carrying a pipelineConn is handed the connection the check's TCP
+// (and TLS) layers opened instead of dialing, see pipelineConn.
+func newConnectivityTransport(network string) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	var dialer net.Dialer
+	transport.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+		pipeline, _ := ctx.Value(pipelineConnKey{}).(*pipelineConn)
+		if conn := pipeline.take(addr, false); conn != nil {
+			return conn, nil
+		}
+		return dialer.DialContext(ctx, network, pipeline.resolve(addr))
+	}
+	transport.DialTLSContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+		pipeline, _ := ctx.Value(pipelineConnKey{}).(*pipelineConn)
+		if conn := pipeline.take(addr, true); conn != nil {
+			return conn, nil
+		}
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		tlsDialer := tls.Dialer{NetDialer: &dialer, Config: &tls.Config{ServerName: host}}
+		return tlsDialer.DialContext(ctx, network, pipeline.resolve(addr))
+	}
+	return transport
+}
+
+// pipelineConnKey is the request context key of a *pipelineConn
+type pipelineConnKey struct{}
+
+// pipelineConn carries the connection a check's TCP (and TLS) layers verified
+// to the shared transport through the HTTP request's context, so the HTTP layer
+// talks to the address they verified and its latency doesn't count DNS, TCP and
+// TLS again. Later dials of the checked address, for redirects on the same
+// host, go to the same IP. A nil *pipelineConn hands nothing over.
+type pipelineConn struct {
+	mu      sync.Mutex
+	conn    net.Conn // nil once taken
+	secure  bool     // Whether conn is TLS-handshaked
+	address string   // The checked host:port
+	pinned  string   // The IP and port conn is connected to
+}
+
+// newPipelineConn wraps conn, the verified connection to address, which may be nil
+func newPipelineConn(conn net.Conn, address string, secure bool) *pipelineConn {
+	p := &pipelineConn{conn: conn, secure: secure, address: address, pinned: address}
+	if conn != nil {
+		p.pinned = conn.RemoteAddr().String()
+	}
+	return p
+}
+
+// take returns the verified connection if addr is the checked address, it is
+// of the kind the transport is dialing and it hasn't been taken yet
+func (p *pipelineConn) take(addr string, secure bool) net.Conn {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if addr != p.address || secure != p.secure || p.conn == nil {
+		return nil
+	}
+	conn := p.conn
+	p.conn = nil
+	return conn
+}
+
+// resolve returns the pinned IP for the checked address, other addresses as-is
+func (p *pipelineConn) resolve(addr string) string {
+	if p != nil && addr == p.address {
+		return p.pinned
+	}
+	return addr
+}
+
+// close closes the verified connection if the transport never took it, as
+// when it reused an idle connection to the same host instead
+func (p *pipelineConn) close() {
+	if conn := p.take(p.address, p.secure); conn != nil {
+		conn.Close()
+	}
+}
+
+// connectivityCacheKey identifies a probed target in the connectivity cache
+type connectivityCacheKey struct {
+	domain   string
+	port     string
+	protocol string
+	method   string
+	path     string
+	network  string
+	redirect bool
+	host     string
+	headers  string // Canonical form of the request headers, see headersCacheKey
+}
+
+// connectivityCacheEntry is a cached report and the time it stops being valid
+type connectivityCacheEntry struct {
+	report  ConnectivityReport
+	expires time.Time
+}
+
+// Connectivity cache state. A zero TTL disables caching.
+var (
+	connectivityCacheMu  sync.Mutex
+	connectivityCacheTTL time.Duration
+	connectivityCache    = make(map[connectivityCacheKey]connectivityCacheEntry)
+)
+
+// SetConnectivityCacheTTL sets how long connectivity reports are reused for
+// identical targets. A ttl of zero or less disables caching and clears any
+// cached reports.
+func SetConnectivityCacheTTL(ttl time.Duration) {
+	connectivityCacheMu.Lock()
+	defer connectivityCacheMu.Unlock()
+
+	if ttl <= 0 {
+		ttl = 0
+	}
+	connectivityCacheTTL = ttl
+	clear(connectivityCache)
+}
+
+// getCachedConnectivity returns a cached report for key if one is still valid
+func getCachedConnectivity(key connectivityCacheKey) (ConnectivityReport, bool) {
+	connectivityCacheMu.Lock()
+	defer connectivityCacheMu.Unlock()
+
+	if connectivityCacheTTL == 0 {
+		return ConnectivityReport{}, false
+	}
+	entry, ok := connectivityCache[key]
+	if !ok {
+		return ConnectivityReport{}, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(connectivityCache, key)
+		return ConnectivityReport{}, false
+	}
+	return entry.report, true
+}
+
+// storeCachedConnectivity stores report under key when caching is enabled
+func storeCachedConnectivity(key connectivityCacheKey, report ConnectivityReport) {
+	connectivityCacheMu.Lock()
+	defer connectivityCacheMu.Unlock()
+
+	if connectivityCacheTTL == 0 {
+		return
+	}
+	connectivityCache[key] = connectivityCacheEntry{
+		report:  report,
+		expires: time.Now().Add(connectivityCacheTTL),
+	}
+}
+
+// ConnectivityOptions configures a connectivity check
+type ConnectivityOptions struct {
+	Domain             string            `json:"domain"`
+	Port               string            `json:"port"`                // Default Options.DefaultPort ("80") if empty
+	TimeoutSeconds     int               `json:"timeout_seconds"`     // Timeout for each check, default Options.DefaultTimeoutSeconds (5) if <=0
+	AcceptableStatuses []int             `json:"acceptable_statuses"` // HTTP statuses counted as acceptable, default any 2xx/3xx
+	Path               string            `json:"path"`                // HTTP path to request, default "/"
+	Method             string            `json:"method"`              // HTTP method, default "GET"
+	Scheme             string            `json:"scheme"`              // "http" or "https", which adds a TLS layer; default "https" on port 443, else "http"
+	Network            string            `json:"network"`             // "tcp4" or "tcp6" to force IPv4 or IPv6, default "tcp" (either)
+	FollowRedirects    *bool             `json:"follow_redirects"`    // Whether the HTTP check follows redirects, default true
+	Headers            map[string]string `json:"headers"`             // Extra HTTP request headers, e.g. Authorization
+	Host               string            `json:"host"`                // Host header override, e.g. for an ingress; TLS still uses Domain
+	// OverallDeadlineSeconds bounds the whole check. Each layer gets the smaller of
+	// TimeoutSeconds and the time left in the budget. Unbounded if <=0.
+	OverallDeadlineSeconds float64 `json:"overall_deadline_seconds"`
+	// Retries is how many more times a check failing at TCP, TLS or HTTP, or
+	// getting an unacceptable status, is repeated. DNS failures are not retried.
+	Retries   int `json:"retries"`
+	BackoffMs int `json:"backoff_ms"` // Wait before the first retry, doubled for each further retry; default 100
+}
+
+// CheckConnectivity checks connectivity to a domain at multiple layers (DNS, TCP, HTTP)
+// timeoutSeconds: timeout for each check in seconds (default 5, or the
+// configured DefaultTimeoutSeconds, if <=0)
+// port: port to check (default "80", or the configured DefaultPort, if empty)
+func CheckConnectivity(domain, port string, timeoutSeconds int) ConnectivityReport {
+	return CheckConnectivityWithOptions(ConnectivityOptions{
+		Domain:         domain,
+		Port:           port,
+		TimeoutSeconds: timeoutSeconds,
+	})
+}
+
+// CheckConnectivityWithOptions checks connectivity to a domain at multiple layers
+// (DNS, TCP, TLS for https, HTTP), stopping at the first layer that fails
+func CheckConnectivityWithOptions(opts ConnectivityOptions) ConnectivityReport {
+	if opts.TimeoutSeconds <= 0 {
+		opts.TimeoutSeconds = defaultConnectivityTimeoutSeconds()
+	}
+	if opts.Port == "" {
+		opts.Port = defaultConnectivityPort()
+	}
+	if opts.Path == "" {
+		opts.Path = "/"
+	} else if !strings.HasPrefix(opts.Path, "/") {
+		opts.Path = "/" + opts.Path
+	}
+	opts.Method = strings.ToUpper(opts.Method)
+	if opts.Method == "" {
+		opts.Method = http.MethodGet
+	}
+	// Accept bracketed IPv6 literals such as "[::1]"
+	if strings.HasPrefix(opts.Domain, "[") && strings.HasSuffix(opts.Domain, "]") {
+		opts.Domain = opts.Domain[1 : len(opts.Domain)-1]
+	}
+	opts.Network = strings.ToLower(opts.Network)
+	if opts.Network == "" {
+		opts.Network = "tcp"
+	}
+	if opts.BackoffMs <= 0 {
+		opts.BackoffMs = 100
+	}
+	opts.Scheme = strings.ToLower(opts.Scheme)
+	if opts.Scheme == "" {
+		opts.Scheme = "http"
+		if opts.Port == "443" {
+			opts.Scheme = "https"
+		}
+	}
+
+	key := connectivityCacheKey{domain: opts.Domain, port: opts.Port, protocol: opts.Scheme, method: opts.Method, path: opts.Path, network: opts.Network, redirect: followRedirects(opts),
+		host: opts.Host, headers: headersCacheKey(opts.Headers)}
+	report, ok := getCachedConnectivity(key)
+	if ok {
+		report.Cached = true
+	} else {
+		report = probeConnectivityWithRetries(opts)
+		storeCachedConnectivity(key, report)
+	}
+
+	report.HTTPAcceptable = report.HTTPStatusCode != 0 && isAcceptableStatus(report.HTTPStatusCode, opts.AcceptableStatuses)
+	return report
+}
+
+// probeConnectivityWithRetries runs the connectivity pipeline, retrying with
+// exponential backoff while it fails past DNS or gets an unacceptable status.
+// The overall deadline, if any, covers all attempts.
+func probeConnectivityWithRetries(opts ConnectivityOptions) ConnectivityReport {
+	ctx := context.Background()
+	if opts.OverallDeadlineSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(opts.OverallDeadlineSeconds*float64(time.Second)))
+		defer cancel()
+	}
+
+	start := time.Now()
+	backoff := time.Duration(opts.BackoffMs) * time.Millisecond
+	var report ConnectivityReport
+	for attempt := 1; ; attempt++ {
+		report = probeConnectivity(ctx, opts)
+		report.Attempts = attempt
+		if attempt > opts.Retries || !shouldRetryConnectivity(report, opts) || !sleepContext(ctx, backoff) {
+			break
+		}
+		backoff *= 2
+	}
+	report.ElapsedMs = float64(time.Since(start).Microseconds()) / 1000
+	return report
+}
+
+// shouldRetryConnectivity reports whether a check may succeed on a later
+// attempt: it failed at TCP, TLS or HTTP, or got an unacceptable status
+func shouldRetryConnectivity(report ConnectivityReport, opts ConnectivityOptions) bool {
+	switch report.FailedLayer {
+	case "":
+		return !isAcceptableStatus(report.HTTPStatusCode, opts.AcceptableStatuses)
+	case "dns":
+		return false
+	default:
+		return true
+	}
+}
+
+// sleepContext waits for d, returning false if ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// probeConnectivity runs the connectivity pipeline once for opts, which must
+// have defaults applied. ctx carries the overall deadline, if any.
+func probeConnectivity(ctx context.Context, opts ConnectivityOptions) ConnectivityReport {
+	timeout := time.Duration(opts.TimeoutSeconds) * time.Second
+	address := net.JoinHostPort(opts.Domain, opts.Port)
+	report := ConnectivityReport{
+		Domain:         opts.Domain,
+		Port:           opts.Port,
+		TimeoutSeconds: opts.TimeoutSeconds,
+		Method:         opts.Method,
+		Path:           opts.Path,
+		Network:        opts.Network,
+	}
+
+	pipeline := connectivityPipeline{report: &report, ctx: ctx, timeout: timeout}
+	conn := pipeline.dialTCP(opts.Domain, opts.Port, opts.Network)
+
+	// TLS: handshake over the established connection (https only)
+	if opts.Scheme == "https" {
+		pipeline.run("tls", func() error {
+			ctx, cancel := pipeline.layerContext()
+			defer cancel()
+			tlsConn := tls.Client(conn, &tls.Config{ServerName: opts.Domain})
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				return err
+			}
+			state := tlsConn.ConnectionState()
+			report.TLSVersion = tls.VersionName(state.Version)
+			report.TLSCipherSuite = tls.CipherSuiteName(state.CipherSuite)
+			if len(state.PeerCertificates) > 0 {
+				leaf := state.PeerCertificates[0]
+				report.CertSubject = leaf.Subject.String()
+				report.CertNotAfter = leaf.NotAfter.UTC().Format(time.RFC3339)
+			}
+			conn = tlsConn
+			return nil
+		})
+	}
+	pipelineConn := newPipelineConn(conn, address, opts.Scheme == "https")
+	defer pipelineConn.close()
+
+	// HTTP: any response counts as success, its status is recorded as-is,
+	// so a non-2xx status is reported rather than treated as an error
+	pipeline.run("http", func() error {
+		ctx, cancel := pipeline.layerContext()
+		defer cancel()
+		// net.JoinHostPort brackets IPv6 literals, as URLs require
+		url := opts.Scheme + "://" + address + opts.Path
+		ctx = context.WithValue(ctx, pipelineConnKey{}, pipelineConn)
+		req, err := http.NewRequestWithContext(ctx, opts.Method, url, nil)
+		if err != nil {
+			return err
+		}
+		for name, value := range opts.Headers {
+			req.Header.Set(name, value)
+		}
+		if opts.Host != "" {
+			req.Host = opts.Host
+		}
+		client := connectivityClients[connectivityClientKey{opts.Network, followRedirects(opts)}]
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		report.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+		report.HTTP = resp.Status
+		report.HTTPStatusCode = resp.StatusCode
+		report.FinalURL = resp.Request.URL.String()
+		// Drain a bounded amount of the body so the shared client can keep the
+		// connection idle for the next check to the same host
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+		resp.Body.Close()
+		return nil
+	})
+
+	report.DNS = pipeline.summary("dns", "success")
+	report.TCP = pipeline.summary("tcp", "success")
+	if opts.Scheme == "https" {
+		report.HTTPS = pipeline.summary("tls", "success")
+	}
+	report.HTTP = pipeline.summary("http", report.HTTP)
+	return report
+}
+
+// waitForConnectivityInterval is the pause between WaitForConnectivity attempts
+const waitForConnectivityInterval = 250 * time.Millisecond
+
+// WaitForConnectivity resolves and connects to domain:port over TCP every
+// 250ms until a connection succeeds or overallTimeoutSeconds (default 30 if
+// <=0) pass. An empty port uses the configured default. Unlike retries, DNS failures are retried too, since a dependency
+// coming online may not be resolvable yet. It returns the last report, with
+// the attempts made and the time spent, and an error if the deadline passed.
+func WaitForConnectivity(domain, port string, overallTimeoutSeconds int) (ConnectivityReport, error) {
+	if port == "" {
+		port = defaultConnectivityPort()
+	}
+	if overallTimeoutSeconds <= 0 {
+		overallTimeoutSeconds = 30
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(overallTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	var report ConnectivityReport
+	for attempt := 1; ; attempt++ {
+		report = ConnectivityReport{Domain: domain, Port: port, TimeoutSeconds: overallTimeoutSeconds, Network: "tcp"}
+		pipeline := connectivityPipeline{report: &report, ctx: ctx, timeout: time.Duration(overallTimeoutSeconds) * time.Second}
+		if conn := pipeline.dialTCP(domain, port, "tcp"); conn != nil {
+			conn.Close()
+		}
+		report.DNS = pipeline.summary("dns", "success")
+		report.TCP = pipeline.summary("tcp", "success")
+		report.Attempts = attempt
+		if report.FailedLayer == "" || !sleepContext(ctx, waitForConnectivityInterval) {
+			break
+		}
+	}
+	report.ElapsedMs = float64(time.Since(start).Microseconds()) / 1000
+
+	if report.FailedLayer != "" {
+		cause := report.TCP
+		if report.FailedLayer == "dns" {
+			cause = report.DNS
+		}
+		return report, fmt.Errorf("%s not reachable within %ds after %d attempts: %s", net.JoinHostPort(domain, port), overallTimeoutSeconds, report.Attempts, cause)
+	}
+	return report, nil
+}
+
+// headersCacheKey returns headers as a sorted string usable in a cache key
+func headersCacheKey(headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var key strings.Builder
+	for _, name := range names {
+		key.WriteString(http.CanonicalHeaderKey(name) + ": " + headers[name] + "\n")
+	}
+	return key.String()
+}
+
+// followRedirects returns whether the HTTP check of opts follows redirects
+func followRedirects(opts ConnectivityOptions) bool {
+	return opts.FollowRedirects == nil || *opts.FollowRedirects
+}
+
+// ipNetworks maps the supported dial networks to their resolver networks
+var ipNetworks = map[string]string{"tcp": "ip", "tcp4": "ip4", "tcp6": "ip6"}
+
+// addressFamily returns "ipv4" or "ipv6" for the IP of addr, or "" if it has none
+func addressFamily(addr net.Addr) string {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return ""
+	}
+	if tcpAddr.IP.To4() != nil {
+		return "ipv4"
+	}
+	return "ipv6"
+}
+
+// connectivityPipeline runs connectivity layers in order, skipping every
+// layer after the first failure
+type connectivityPipeline struct {
+	report  *ConnectivityReport
+	ctx     context.Context // Carries the overall deadline, if any
+	timeout time.Duration   // Per-layer timeout
+}
+
+// layerContext returns the context for one layer, bounded by both the
+// per-layer timeout and the overall deadline
+func (p *connectivityPipeline) layerContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(p.ctx, p.timeout)
+}
+
+// run executes check as the named layer and records its result
+func (p *connectivityPipeline) run(layer string, check func() error) {
+	result := LayerResult{Layer: layer}
+	if p.report.FailedLayer != "" {
+		result.Status = "skipped"
+		p.report.Layers = append(p.report.Layers, result)
+		return
+	}
+
+	start := time.Now()
+	err := check()
+	if err != nil && p.ctx.Err() != nil {
+		err = fmt.Errorf("overall deadline exceeded: %w", err)
+	}
+	result.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+		p.report.FailedLayer = layer
+	} else {
+		result.Status = "success"
+	}
+	p.report.Layers = append(p.report.Layers, result)
+}
+
+// dialTCP runs the DNS and TCP layers: it resolves domain to addresses of
+// network's family and connects to the first that accepts. The caller closes
+// the returned connection, which is nil if either layer failed.
+func (p *connectivityPipeline) dialTCP(domain, port, network string) net.Conn {
+	var addrs []string
+	var conn net.Conn
+
+	// DNS: resolve the domain to addresses of the requested family
+	// (IP literals resolve to themselves)
+	p.run("dns", func() error {
+		ipNetwork, ok := ipNetworks[network]
+		if !ok {
+			return fmt.Errorf("unsupported network %q (expected tcp, tcp4 or tcp6)", network)
+		}
+		ctx, cancel := p.layerContext()
+		defer cancel()
+		ips, err := net.DefaultResolver.LookupIP(ctx, ipNetwork, domain)
+		for _, ip := range ips {
+			addrs = append(addrs, ip.String())
+		}
+		p.report.Addresses = addrs
+		return err
+	})
+
+	// TCP: connect to the first resolved address that accepts
+	p.run("tcp", func() error {
+		ctx, cancel := p.layerContext()
+		defer cancel()
+		var dialer net.Dialer
+		var err error
+		for _, addr := range addrs {
+			conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+			if err == nil {
+				p.report.AddressFamily = addressFamily(conn.RemoteAddr())
+				return nil
+			}
+		}
+		return err
+	})
+	return conn
+}
+
+// summary returns the legacy one-line status of a layer: success on
+// success, the error on failure, or which layer caused it to be skipped
+func (p *connectivityPipeline) summary(layer, success string) string {
+	for _, result := range p.report.Layers {
+		if result.Layer != layer {
+			continue
+		}
+		switch result.Status {
+		case "success":
+			return success
+		case "failed":
+			return result.Error
+		}
+	}
+	return "skipped (" + strings.ToUpper(p.report.FailedLayer) + " failed)"
+}
+
+// isAcceptableStatus reports whether code is in acceptable, or is 2xx/3xx when acceptable is empty
+func isAcceptableStatus(code int, acceptable []int) bool {
+	if len(acceptable) == 0 {
+		return code >= 200 && code < 400
+	}
+	return slices.Contains(acceptable, code)
+}
+
+// Target is one connectivity check of a batch
+type Target struct {
+	Domain         string `json:"domain"`
+	Port           string `json:"port"`            // Default Options.DefaultPort ("80") if empty
+	TimeoutSeconds int    `json:"timeout_seconds"` // Default Options.DefaultTimeoutSeconds (5) if <=0
+}
+
+// maxConnectivityWorkers bounds how many checks of a batch run at once
+const maxConnectivityWorkers = 8
+
+// CheckConnectivityBatch checks all targets concurrently and returns their
+// reports in the same order as targets
+func CheckConnectivityBatch(targets []Target) []ConnectivityReport {
+	reports := make([]ConnectivityReport, len(targets))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(len(targets), maxConnectivityWorkers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				target := targets[i]
+				reports[i] = CheckConnectivity(target.Domain, target.Port, target.TimeoutSeconds)
+			}
+		}()
+	}
+	for i := range targets {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return reports
+}
+
+// CheckConnectivity exposes CheckConnectivity to k6 JavaScript
+func (Toolbox) CheckConnectivity(domain string, port string, timeoutSeconds int) ConnectivityReport {
+	return CheckConnectivity(domain, port, timeoutSeconds)
+}
+
+// WaitForConnectivity exposes WaitForConnectivity to k6 JavaScript
+func (Toolbox) WaitForConnectivity(domain string, port string, overallTimeoutSeconds int) (ConnectivityReport, error) {
+	return WaitForConnectivity(domain, port, overallTimeoutSeconds)
+}
+
+// CheckConnectivityWithOptions exposes CheckConnectivityWithOptions to k6 JavaScript
+func (Toolbox) CheckConnectivityWithOptions(opts ConnectivityOptions) ConnectivityReport {
+	return CheckConnectivityWithOptions(opts)
+}
+
+// CheckConnectivityBatch exposes CheckConnectivityBatch to k6 JavaScript
+func (Toolbox) CheckConnectivityBatch(targets []Target) []ConnectivityReport {
+	return CheckConnectivityBatch(targets)
+}
+
+// SpanAttributes flattens the report into OpenTelemetry span attributes, using
+// semantic convention names where one exists and toolbox.* otherwise. Per-layer
+// results become toolbox.<layer>.status, .latency_ms and .error.
+func (r ConnectivityReport) SpanAttributes() map[string]string {
+	attrs := map[string]string{
+		"net.peer.name":  r.Domain,
+		"net.peer.port":  r.Port,
+		"toolbox.cached": strconv.FormatBool(r.Cached),
+	}
+	if r.Method != "" {
+		attrs["http.method"] = r.Method
+		attrs["http.target"] = r.Path
+	}
+	if r.AddressFamily != "" {
+		attrs["network.type"] = r.AddressFamily
+	}
+	if r.HTTPStatusCode != 0 {
+		attrs["http.status_code"] = strconv.Itoa(r.HTTPStatusCode)
+		attrs["toolbox.http.acceptable"] = strconv.FormatBool(r.HTTPAcceptable)
+	}
+	if r.FailedLayer != "" {
+		attrs["toolbox.failed_layer"] = r.FailedLayer
+	}
+	for _, layer := range r.Layers {
+		prefix := "toolbox." + layer.Layer + "."
+		attrs[prefix+"status"] = layer.Status
+		if layer.Status != "skipped" {
+			attrs[prefix+"latency_ms"] = strconv.FormatFloat(layer.LatencyMs, 'f', 3, 64)
+		}
+		if layer.Error != "" {
+			attrs[prefix+"error"] = layer.Error
+		}
+	}
+	return attrs
+}
+
+// GetConnectivitySpanAttributes returns a connectivity report as a flat map of
+// OpenTelemetry span attributes (see ConnectivityReport.SpanAttributes)
+func (Toolbox) GetConnectivitySpanAttributes(report ConnectivityReport) map[string]string {
+	return report.SpanAttributes()
+}
+
+// SetConnectivityCacheTTL exposes SetConnectivityCacheTTL to k6 JavaScript.
+// ttlMs: cache lifetime in milliseconds (0 disables caching, the default)
+func (Toolbox) SetConnectivityCacheTTL(ttlMs int) {
+	SetConnectivityCacheTTL(time.Duration(ttlMs) * time.Millisecond)
+}
+
+// resetConnectivity drops cached reports and closes idle connections of the
+// connectivity HTTP client. The configured cache TTL is kept.
+func resetConnectivity() {
+	connectivityCacheMu.Lock()
+	clear(connectivityCache)
+	connectivityCacheMu.Unlock()
+
+	for _, transport := range connectivityTransports {
+		transport.CloseIdleConnections()
+	}
+}
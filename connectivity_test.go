@@ -0,0 +1,708 @@
+package toolbox
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckConnectivity(t *testing.T) {
+	// This is a basic test that requires network access
+	report := CheckConnectivity("google.com", "80", 5)
+
+	if report.Domain != "google.com" {
+		t.Errorf("Expected domain 'google.com', got '%s'", report.Domain)
+	}
+
+	if report.TCP != "success" && !strings.Contains(report.TCP, "refused") {
+		// Allow for connection refused in sandboxed environments
+		t.Logf("TCP check did not succeed (as expected in some environments): %s", report.TCP)
+	}
+}
+
+// newTestServer starts a local HTTP server and returns its host, port and hit counter
+func newTestServer(t *testing.T, handler http.HandlerFunc) (string, string, *atomic.Int64) {
+	t.Helper()
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if handler != nil {
+			handler(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to split test server address: %v", err)
+	}
+	return host, port, &hits
+}
+
+func TestCheckConnectivityCache(t *testing.T) {
+	host, port, hits := newTestServer(t, nil)
+
+	// Caching is off by default: every check probes the target
+	CheckConnectivity(host, port, 2)
+	report := CheckConnectivity(host, port, 2)
+	if hits.Load() != 2 {
+		t.Errorf("Expected 2 probes with caching disabled, got %d", hits.Load())
+	}
+	if report.Cached {
+		t.Error("Expected uncached report with caching disabled")
+	}
+
+	SetConnectivityCacheTTL(time.Minute)
+	t.Cleanup(func() { SetConnectivityCacheTTL(0) })
+
+	first := CheckConnectivity(host, port, 2)
+	second := CheckConnectivity(host, port, 2)
+	if hits.Load() != 3 {
+		t.Errorf("Expected identical checks within the TTL to reuse the result, got %d probes", hits.Load())
+	}
+	if first.Cached {
+		t.Error("Expected first report within the TTL to be fresh")
+	}
+	if !second.Cached {
+		t.Error("Expected second report within the TTL to be cached")
+	}
+	if second.HTTP != first.HTTP {
+		t.Errorf("Expected cached HTTP status '%s', got '%s'", first.HTTP, second.HTTP)
+	}
+
+	// Disabling the cache drops cached reports
+	SetConnectivityCacheTTL(0)
+	CheckConnectivity(host, port, 2)
+	if hits.Load() != 4 {
+		t.Errorf("Expected a fresh probe after disabling the cache, got %d probes", hits.Load())
+	}
+}
+
+func TestIsAcceptableStatus(t *testing.T) {
+	if !isAcceptableStatus(200, nil) || !isAcceptableStatus(301, nil) {
+		t.Error("Expected 2xx and 3xx to be acceptable by default")
+	}
+	if isAcceptableStatus(404, nil) || isAcceptableStatus(503, nil) {
+		t.Error("Expected 4xx and 5xx to be unacceptable by default")
+	}
+	if !isAcceptableStatus(503, []int{200, 503}) {
+		t.Error("Expected explicitly listed status to be acceptable")
+	}
+	if isAcceptableStatus(204, []int{200}) {
+		t.Error("Expected unlisted status to be unacceptable")
+	}
+}
+
+func TestCheckConnectivityAcceptableStatuses(t *testing.T) {
+	host, port, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	// A 503 is recorded but not acceptable by default
+	report := CheckConnectivityWithOptions(ConnectivityOptions{Domain: host, Port: port, TimeoutSeconds: 2})
+	if report.HTTPStatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code 503, got %d", report.HTTPStatusCode)
+	}
+	if report.HTTPAcceptable {
+		t.Error("Expected 503 to be unacceptable by default")
+	}
+
+	report = CheckConnectivityWithOptions(ConnectivityOptions{
+		Domain:             host,
+		Port:               port,
+		TimeoutSeconds:     2,
+		AcceptableStatuses: []int{503},
+	})
+	if !report.HTTPAcceptable {
+		t.Error("Expected 503 to be acceptable when listed")
+	}
+}
+
+// layerStatuses returns the layer statuses of a report keyed by layer name
+func layerStatuses(report ConnectivityReport) map[string]string {
+	statuses := make(map[string]string)
+	for _, layer := range report.Layers {
+		statuses[layer.Layer] = layer.Status
+	}
+	return statuses
+}
+
+func TestCheckConnectivityPipeline(t *testing.T) {
+	host, port, _ := newTestServer(t, nil)
+
+	report := CheckConnectivity(host, port, 2)
+	if report.FailedLayer != "" {
+		t.Errorf("Expected no failed layer, got '%s'", report.FailedLayer)
+	}
+
+	var order []string
+	for _, layer := range report.Layers {
+		order = append(order, layer.Layer)
+		if layer.Status != "success" {
+			t.Errorf("Expected layer %s to succeed, got %s (%s)", layer.Layer, layer.Status, layer.Error)
+		}
+	}
+	if strings.Join(order, ",") != "dns,tcp,http" {
+		t.Errorf("Expected layers dns,tcp,http, got %v", order)
+	}
+	if report.TCP != "success" || report.HTTP != "200 OK" {
+		t.Errorf("Expected TCP success and HTTP 200 OK, got '%s' and '%s'", report.TCP, report.HTTP)
+	}
+}
+
+func TestCheckConnectivityDNS(t *testing.T) {
+	host, port, _ := newTestServer(t, nil)
+
+	report := CheckConnectivity(host, port, 2)
+	if report.DNS != "success" {
+		t.Errorf("Expected DNS success, got '%s'", report.DNS)
+	}
+	if len(report.Addresses) != 1 || report.Addresses[0] != host {
+		t.Errorf("Expected addresses [%s], got %v", host, report.Addresses)
+	}
+
+	// The .invalid TLD never resolves
+	report = CheckConnectivity("toolbox-test.invalid", "80", 2)
+	if report.FailedLayer != "dns" {
+		t.Errorf("Expected failed layer 'dns', got '%s'", report.FailedLayer)
+	}
+	if report.DNS == "success" || report.DNS == "" {
+		t.Errorf("Expected resolver error in DNS, got '%s'", report.DNS)
+	}
+	if report.TCP != "skipped (DNS failed)" || report.HTTP != "skipped (DNS failed)" {
+		t.Errorf("Expected TCP and HTTP skipped after DNS failure, got '%s' and '%s'", report.TCP, report.HTTP)
+	}
+}
+
+func TestCheckConnectivityPipelineTCPFailure(t *testing.T) {
+	// Grab a free port and close it so nothing is listening
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to open listener: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	report := CheckConnectivity("127.0.0.1", port, 2)
+	if report.FailedLayer != "tcp" {
+		t.Errorf("Expected failed layer 'tcp', got '%s'", report.FailedLayer)
+	}
+
+	statuses := layerStatuses(report)
+	if statuses["dns"] != "success" || statuses["tcp"] != "failed" || statuses["http"] != "skipped" {
+		t.Errorf("Unexpected layer statuses: %v", statuses)
+	}
+	if report.HTTP != "skipped (TCP failed)" {
+		t.Errorf("Expected HTTP 'skipped (TCP failed)', got '%s'", report.HTTP)
+	}
+}
+
+func TestCheckConnectivityPipelineTLSFailure(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	// The test server's certificate is not trusted
+	report := CheckConnectivityWithOptions(ConnectivityOptions{Domain: host, Port: port, TimeoutSeconds: 2, Scheme: "https"})
+	if report.FailedLayer != "tls" {
+		t.Errorf("Expected failed layer 'tls', got '%s'", report.FailedLayer)
+	}
+
+	statuses := layerStatuses(report)
+	if statuses["tcp"] != "success" || statuses["tls"] != "failed" || statuses["http"] != "skipped" {
+		t.Errorf("Unexpected layer statuses: %v", statuses)
+	}
+	if report.TCP != "success" {
+		t.Errorf("Expected TCP success, got '%s'", report.TCP)
+	}
+	if !strings.Contains(report.HTTPS, "certificate") {
+		t.Errorf("Expected the handshake error in HTTPS, got '%s'", report.HTTPS)
+	}
+	if report.TLSVersion != "" || report.CertNotAfter != "" {
+		t.Errorf("Expected no TLS details after a failed handshake, got %s / %s", report.TLSVersion, report.CertNotAfter)
+	}
+}
+
+func TestCheckConnectivityHTTPS(t *testing.T) {
+	// Port 443 defaults to https; requires network access
+	report := CheckConnectivity("google.com", "443", 5)
+	if report.HTTPS != "success" {
+		t.Logf("HTTPS check did not succeed (expected without network access): %s", report.HTTPS)
+		return
+	}
+
+	if !strings.HasPrefix(report.TLSVersion, "TLS 1.") {
+		t.Errorf("Expected a TLS version, got '%s'", report.TLSVersion)
+	}
+	if report.TLSCipherSuite == "" || report.CertSubject == "" {
+		t.Errorf("Expected cipher suite and certificate subject, got '%s' and '%s'", report.TLSCipherSuite, report.CertSubject)
+	}
+	if _, err := time.Parse(time.RFC3339, report.CertNotAfter); err != nil {
+		t.Errorf("Expected RFC3339 certificate expiry, got '%s'", report.CertNotAfter)
+	}
+
+	t.Logf("TLS: %s %s, cert %s expires %s", report.TLSVersion, report.TLSCipherSuite, report.CertSubject, report.CertNotAfter)
+}
+
+func TestCheckConnectivityOverallDeadline(t *testing.T) {
+	release := make(chan struct{})
+	host, port, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	start := time.Now()
+	report := CheckConnectivityWithOptions(ConnectivityOptions{
+		Domain:                 host,
+		Port:                   port,
+		TimeoutSeconds:         5,
+		OverallDeadlineSeconds: 0.3,
+	})
+	elapsed := time.Since(start)
+
+	if elapsed > 2*time.Second {
+		t.Errorf("Expected the check to be bounded by the overall deadline, took %v", elapsed)
+	}
+	if report.FailedLayer != "http" {
+		t.Errorf("Expected failed layer 'http', got '%s'", report.FailedLayer)
+	}
+	if !strings.Contains(report.HTTP, "overall deadline exceeded") {
+		t.Errorf("Expected overall deadline error, got '%s'", report.HTTP)
+	}
+}
+
+func TestConnectivitySpanAttributes(t *testing.T) {
+	report := ConnectivityReport{
+		Domain:         "api.internal",
+		Port:           "8080",
+		AddressFamily:  "ipv4",
+		HTTPStatusCode: 503,
+		Layers: []LayerResult{
+			{Layer: "dns", Status: "success", LatencyMs: 1.5},
+			{Layer: "tcp", Status: "success", LatencyMs: 0.25},
+			{Layer: "http", Status: "success", LatencyMs: 12},
+		},
+	}
+
+	attrs := report.SpanAttributes()
+	expected := map[string]string{
+		"net.peer.name":           "api.internal",
+		"net.peer.port":           "8080",
+		"network.type":            "ipv4",
+		"http.status_code":        "503",
+		"toolbox.http.acceptable": "false",
+		"toolbox.cached":          "false",
+		"toolbox.tcp.status":      "success",
+		"toolbox.tcp.latency_ms":  "0.250",
+		"toolbox.http.latency_ms": "12.000",
+	}
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, attrs[key])
+		}
+	}
+	if _, ok := attrs["toolbox.failed_layer"]; ok {
+		t.Error("Expected no failed_layer attribute when all layers succeeded")
+	}
+
+	// A failed check records the error and skips later latencies
+	report = ConnectivityReport{
+		Domain:      "down.internal",
+		Port:        "80",
+		FailedLayer: "tcp",
+		Layers: []LayerResult{
+			{Layer: "dns", Status: "success", LatencyMs: 1},
+			{Layer: "tcp", Status: "failed", LatencyMs: 3, Error: "connection refused"},
+			{Layer: "http", Status: "skipped"},
+		},
+	}
+	attrs = report.SpanAttributes()
+	if attrs["toolbox.failed_layer"] != "tcp" || attrs["toolbox.tcp.error"] != "connection refused" {
+		t.Errorf("Expected tcp failure attributes, got %v", attrs)
+	}
+	if _, ok := attrs["toolbox.http.latency_ms"]; ok {
+		t.Error("Expected no latency for a skipped layer")
+	}
+	if _, ok := attrs["http.status_code"]; ok {
+		t.Error("Expected no status code without an HTTP response")
+	}
+}
+
+func TestCheckConnectivityPathAndMethod(t *testing.T) {
+	var gotMethod atomic.Value
+	host, port, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotMethod.Store(r.Method)
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	report := CheckConnectivityWithOptions(ConnectivityOptions{Domain: host, Port: port, Path: "healthz", Method: "head"})
+	if report.HTTPStatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 from /healthz, got %d", report.HTTPStatusCode)
+	}
+	if report.Path != "/healthz" || report.Method != "HEAD" || gotMethod.Load() != "HEAD" {
+		t.Errorf("Expected HEAD /healthz, got %s %s (server saw %v)", report.Method, report.Path, gotMethod.Load())
+	}
+	if report.LatencyMs <= 0 {
+		t.Errorf("Expected positive latency, got %f", report.LatencyMs)
+	}
+
+	// A non-2xx status is recorded, not treated as a failure
+	report = CheckConnectivity(host, port, 2)
+	if report.FailedLayer != "" || report.HTTPStatusCode != http.StatusNotFound || report.HTTP != "404 Not Found" {
+		t.Errorf("Expected a recorded 404 from /, got '%s' (failed layer '%s')", report.HTTP, report.FailedLayer)
+	}
+	if report.Path != "/" || report.Method != "GET" {
+		t.Errorf("Expected default GET /, got %s %s", report.Method, report.Path)
+	}
+}
+
+func TestCheckConnectivityNetwork(t *testing.T) {
+	host, port, _ := newTestServer(t, nil)
+
+	report := CheckConnectivityWithOptions(ConnectivityOptions{Domain: host, Port: port, TimeoutSeconds: 2, Network: "tcp4"})
+	if report.FailedLayer != "" {
+		t.Fatalf("Expected tcp4 check to succeed, failed at %s: %+v", report.FailedLayer, report.Layers)
+	}
+	if report.Network != "tcp4" || report.AddressFamily != "ipv4" {
+		t.Errorf("Expected tcp4/ipv4, got %q/%q", report.Network, report.AddressFamily)
+	}
+
+	// The IPv4 test server has no IPv6 address
+	report = CheckConnectivityWithOptions(ConnectivityOptions{Domain: host, Port: port, TimeoutSeconds: 2, Network: "tcp6"})
+	if report.FailedLayer != "dns" {
+		t.Errorf("Expected forcing tcp6 on an IPv4 literal to fail at dns, got %q", report.FailedLayer)
+	}
+
+	report = CheckConnectivityWithOptions(ConnectivityOptions{Domain: host, Port: port, TimeoutSeconds: 2, Network: "udp"})
+	if report.FailedLayer != "dns" || !strings.Contains(report.DNS, "unsupported network") {
+		t.Errorf("Expected unsupported network error, got %q", report.DNS)
+	}
+}
+
+func TestCheckConnectivityIPv6(t *testing.T) {
+	listener, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	// Both the bare and the bracketed literal work
+	for _, domain := range []string{"::1", "[::1]"} {
+		report := CheckConnectivity(domain, port, 2)
+		if report.FailedLayer != "" {
+			t.Errorf("%s: expected success, failed at %s: %+v", domain, report.FailedLayer, report.Layers)
+			continue
+		}
+		if report.AddressFamily != "ipv6" || report.HTTPStatusCode != http.StatusOK {
+			t.Errorf("%s: expected ipv6 and 200, got %q and %d", domain, report.AddressFamily, report.HTTPStatusCode)
+		}
+	}
+}
+
+func TestCheckConnectivityBatch(t *testing.T) {
+	// Each request takes a while, so a serial batch would take far longer
+	delay := 200 * time.Millisecond
+	host, port, hits := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+	})
+
+	targets := make([]Target, 10)
+	for i := range targets {
+		targets[i] = Target{Domain: host, Port: port, TimeoutSeconds: 2}
+	}
+	// An unreachable target in the middle keeps its position
+	targets[4] = Target{Domain: host, Port: "1", TimeoutSeconds: 2}
+
+	start := time.Now()
+	reports := CheckConnectivityBatch(targets)
+	elapsed := time.Since(start)
+
+	if len(reports) != len(targets) {
+		t.Fatalf("Expected %d reports, got %d", len(targets), len(reports))
+	}
+	for i, report := range reports {
+		if report.Port != targets[i].Port {
+			t.Errorf("Report %d: expected port %s, got %s", i, targets[i].Port, report.Port)
+		}
+		if i == 4 {
+			if report.FailedLayer != "tcp" {
+				t.Errorf("Report 4: expected tcp failure, got %q", report.FailedLayer)
+			}
+		} else if report.FailedLayer != "" {
+			t.Errorf("Report %d: expected success, failed at %s", i, report.FailedLayer)
+		}
+	}
+	if hits.Load() != 9 {
+		t.Errorf("Expected 9 requests, got %d", hits.Load())
+	}
+	if elapsed >= 9*delay {
+		t.Errorf("Expected checks to run concurrently, batch took %v", elapsed)
+	}
+
+	if reports := CheckConnectivityBatch(nil); len(reports) != 0 {
+		t.Errorf("Expected no reports for no targets, got %d", len(reports))
+	}
+}
+
+func TestCheckConnectivityRetries(t *testing.T) {
+	// The service reports 503 until its third request, like a cold start
+	var requests atomic.Int64
+	host, port, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	report := CheckConnectivityWithOptions(ConnectivityOptions{Domain: host, Port: port, TimeoutSeconds: 2, Retries: 5, BackoffMs: 10})
+	if report.Attempts != 3 || report.HTTPStatusCode != http.StatusOK || !report.HTTPAcceptable {
+		t.Errorf("Expected success on attempt 3, got attempt %d with status %d", report.Attempts, report.HTTPStatusCode)
+	}
+	// Backoff of 10ms then 20ms
+	if report.ElapsedMs < 30 {
+		t.Errorf("Expected elapsed time to include backoff, got %vms", report.ElapsedMs)
+	}
+
+	// Connection failures are retried until the retries run out
+	report = CheckConnectivityWithOptions(ConnectivityOptions{Domain: host, Port: "1", TimeoutSeconds: 2, Retries: 2, BackoffMs: 10})
+	if report.Attempts != 3 || report.FailedLayer != "tcp" {
+		t.Errorf("Expected 3 attempts failing at tcp, got %d failing at %q", report.Attempts, report.FailedLayer)
+	}
+
+	// DNS failures are not retried
+	report = CheckConnectivityWithOptions(ConnectivityOptions{Domain: host, Port: port, TimeoutSeconds: 2, Retries: 2, Network: "udp"})
+	if report.Attempts != 1 || report.FailedLayer != "dns" {
+		t.Errorf("Expected 1 attempt failing at dns, got %d failing at %q", report.Attempts, report.FailedLayer)
+	}
+
+	// Without retries a check runs once
+	report = CheckConnectivity(host, "1", 2)
+	if report.Attempts != 1 {
+		t.Errorf("Expected 1 attempt without retries, got %d", report.Attempts)
+	}
+}
+
+func TestCheckConnectivityRedirects(t *testing.T) {
+	host, port, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		}
+	})
+
+	// Redirects are followed by default
+	report := CheckConnectivityWithOptions(ConnectivityOptions{Domain: host, Port: port, TimeoutSeconds: 2, Path: "/old"})
+	if report.HTTPStatusCode != http.StatusOK {
+		t.Errorf("Expected 200 after following the redirect, got %d", report.HTTPStatusCode)
+	}
+	if !strings.HasSuffix(report.FinalURL, "/new") {
+		t.Errorf("Expected final URL to end in /new, got %q", report.FinalURL)
+	}
+
+	follow := false
+	report = CheckConnectivityWithOptions(ConnectivityOptions{Domain: host, Port: port, TimeoutSeconds: 2, Path: "/old", FollowRedirects: &follow})
+	if report.HTTPStatusCode != http.StatusMovedPermanently {
+		t.Errorf("Expected the redirect status 301, got %d", report.HTTPStatusCode)
+	}
+	if !strings.HasSuffix(report.FinalURL, "/old") {
+		t.Errorf("Expected final URL to end in /old, got %q", report.FinalURL)
+	}
+	if report.FailedLayer != "" {
+		t.Errorf("Expected a redirect to count as a response, failed at %s", report.FailedLayer)
+	}
+}
+
+func TestCheckConnectivityHeadersAndHost(t *testing.T) {
+	var gotHost, gotAuth atomic.Value
+	host, port, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotHost.Store(r.Host)
+		gotAuth.Store(r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	report := CheckConnectivityWithOptions(ConnectivityOptions{
+		Domain:         host,
+		Port:           port,
+		TimeoutSeconds: 2,
+		Host:           "api.example.internal",
+		Headers:        map[string]string{"authorization": "Bearer token"},
+	})
+	if report.HTTPStatusCode != http.StatusOK {
+		t.Errorf("Expected 200 with the Authorization header, got %d", report.HTTPStatusCode)
+	}
+	if gotHost.Load() != "api.example.internal" {
+		t.Errorf("Expected Host override, got %v", gotHost.Load())
+	}
+	if gotAuth.Load() != "Bearer token" {
+		t.Errorf("Expected Authorization header, got %v", gotAuth.Load())
+	}
+}
+
+func TestHeadersCacheKey(t *testing.T) {
+	a := headersCacheKey(map[string]string{"x-b": "2", "X-A": "1"})
+	b := headersCacheKey(map[string]string{"X-A": "1", "X-B": "2"})
+	if a != b {
+		t.Errorf("Expected equal keys for the same headers, got %q and %q", a, b)
+	}
+	if headersCacheKey(nil) != "" {
+		t.Error("Expected empty key without headers")
+	}
+	if a == headersCacheKey(map[string]string{"X-A": "1", "X-B": "3"}) {
+		t.Error("Expected different keys for different values")
+	}
+}
+
+func TestWaitForConnectivity(t *testing.T) {
+	// Grab a free port and only start listening on it after a delay
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to open listener: %v", err)
+	}
+	address := listener.Addr().String()
+	_, port, _ := net.SplitHostPort(address)
+	listener.Close()
+
+	go func() {
+		time.Sleep(600 * time.Millisecond)
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return
+		}
+		t.Cleanup(func() { listener.Close() })
+	}()
+
+	report, err := WaitForConnectivity("127.0.0.1", port, 10)
+	if err != nil {
+		t.Fatalf("WaitForConnectivity() error: %v", err)
+	}
+	if report.TCP != "success" || report.FailedLayer != "" {
+		t.Errorf("Expected a successful TCP connection, got %+v", report)
+	}
+	if report.Attempts < 2 || report.ElapsedMs < 500 {
+		t.Errorf("Expected several attempts over the delay, got %d in %.0fms", report.Attempts, report.ElapsedMs)
+	}
+}
+
+func TestWaitForConnectivityDeadline(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to open listener: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	start := time.Now()
+	report, err := WaitForConnectivity("127.0.0.1", port, 1)
+	if err == nil {
+		t.Fatal("Expected error when nothing listens before the deadline")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected to give up at the deadline, took %v", elapsed)
+	}
+	if report.FailedLayer != "tcp" || report.Attempts < 2 {
+		t.Errorf("Expected repeated TCP failures, got %+v", report)
+	}
+}
+
+func TestCheckConnectivityReusesPipelineConnection(t *testing.T) {
+	var connections atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+		}
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	t.Cleanup(resetConnectivity)
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	// "localhost" may resolve to several addresses; the HTTP request must go over
+	// the connection the TCP layer opened rather than resolving and dialing again
+	resetConnectivity()
+	report := CheckConnectivity("localhost", port, 2)
+	if report.FailedLayer != "" {
+		t.Skipf("localhost not reachable: %s failed", report.FailedLayer)
+	}
+	if report.HTTPStatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", report.HTTPStatusCode)
+	}
+	if n := connections.Load(); n != 1 {
+		t.Errorf("Expected the TCP and HTTP layers to share one connection, server saw %d", n)
+	}
+
+	// The shared client keeps that connection, so a redirect on the same host
+	// goes back over it
+	resetConnectivity()
+	connections.Store(0)
+	report = CheckConnectivityWithOptions(ConnectivityOptions{Domain: "localhost", Port: port, TimeoutSeconds: 2, Path: "/old"})
+	if report.HTTPStatusCode != http.StatusOK || !strings.HasSuffix(report.FinalURL, "/new") {
+		t.Errorf("Expected the redirect to be followed, got %d at %q", report.HTTPStatusCode, report.FinalURL)
+	}
+	if n := connections.Load(); n != 1 {
+		t.Errorf("Expected the redirect to reuse the verified connection, server saw %d", n)
+	}
+}
+
+func TestCheckConnectivitySharesClient(t *testing.T) {
+	var idle, closed atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateIdle:
+			idle.Add(1)
+		case http.StateClosed:
+			closed.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	t.Cleanup(resetConnectivity)
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	// Each network and redirect policy has one client, reused by every check
+	first := connectivityClients[connectivityClientKey{"tcp", true}]
+	if first == nil || first != connectivityClients[connectivityClientKey{"tcp", true}] || first.Transport != connectivityTransports["tcp"] {
+		t.Fatal("Expected one shared client per network on the shared transport")
+	}
+
+	resetConnectivity()
+	if report := CheckConnectivity(host, port, 2); report.FailedLayer != "" {
+		t.Fatalf("Check failed at %s", report.FailedLayer)
+	}
+	waitFor := func(n *atomic.Int64) {
+		deadline := time.Now().Add(2 * time.Second)
+		for n.Load() == 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitFor(&idle)
+	if idle.Load() != 1 || closed.Load() != 0 {
+		t.Fatalf("Expected the shared client to keep the connection idle, got %d idle and %d closed", idle.Load(), closed.Load())
+	}
+
+	// Releasing the client's idle connections closes it
+	resetConnectivity()
+	waitFor(&closed)
+	if closed.Load() != 1 {
+		t.Errorf("Expected the idle connection to be closed, got %d closed", closed.Load())
+	}
+}
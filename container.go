@@ -0,0 +1,190 @@
+package toolbox
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// containerIDPattern matches a full 64 character container ID
+var containerIDPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// containerMountPattern matches container IDs in the mount sources of files that
+// runtimes bind into containers, e.g. /var/lib/docker/containers/<id>/hostname
+// or /run/containerd/io.containerd.runtime.v2.task/k8s.io/<id>/rootfs
+var containerMountPattern = regexp.MustCompile(`/(?:containers|io\.containerd\.runtime\.v[12]\.task/[^/]+)/([0-9a-f]{64})/`)
+
+// GetCgroupPath returns the cgroup path of the current process from /proc/self/cgroup:
+// the unified (v2) path if present, else the memory or cpu controller's path
+func (Toolbox) GetCgroupPath() (string, error) {
+	if !isLinux() {
+		return "", errors.New(ErrNotSupported)
+	}
+
+	content, err := readFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	return cgroupPathFromProcCgroup(content)
+}
+
+// cgroupPathFromProcCgroup picks the most relevant path of a /proc/<pid>/cgroup file
+func cgroupPathFromProcCgroup(content string) (string, error) {
+	paths := parseProcCgroup(content)
+	for _, controller := range []string{"", "memory", "cpu", "name=systemd"} {
+		if cgroupPath, ok := paths[controller]; ok {
+			return cgroupPath, nil
+		}
+	}
+	return "", errors.New(ErrCgroupNotFound)
+}
+
+// GetContainerID returns the ID of the container the process runs in, found in
+// the docker, containerd, CRI-O or Kubernetes cgroup paths of /proc/self/cgroup,
+// or in /proc/self/mountinfo when the cgroup namespace hides them. Outside a
+// container it returns an empty string and a not-found error.
+func (Toolbox) GetContainerID() (string, error) {
+	if !isLinux() {
+		return "", errors.New(ErrNotSupported)
+	}
+
+	if content, err := readFile("/proc/self/cgroup"); err == nil {
+		if id := containerIDFromProcCgroup(content); id != "" {
+			return id, nil
+		}
+	}
+	if content, err := readFile("/proc/self/mountinfo"); err == nil {
+		if id := containerIDFromMountInfo(content); id != "" {
+			return id, nil
+		}
+	}
+	return "", errors.New(ErrContainerNotFound)
+}
+
+// containerIDFromProcCgroup finds a container ID in the cgroup paths of a
+// /proc/<pid>/cgroup file. It handles paths such as
+//
+//	/docker/<id>
+//	/system.slice/docker-<id>.scope
+//	/kubepods/burstable/pod<uid>/<id>
+//	/kubepods.slice/.../cri-containerd-<id>.scope
+//	/kubepods.slice/.../crio-<id>.scope
+func containerIDFromProcCgroup(content string) string {
+	for _, cgroupPath := range parseProcCgroup(content) {
+		segments := strings.Split(cgroupPath, "/")
+		for i := len(segments) - 1; i >= 0; i-- {
+			segment := strings.TrimSuffix(segments[i], ".scope")
+			// Strip a runtime prefix such as "docker-" or "cri-containerd-"
+			if index := strings.LastIndex(segment, "-"); index >= 0 {
+				segment = segment[index+1:]
+			}
+			if containerIDPattern.MatchString(segment) {
+				return segment
+			}
+		}
+	}
+	return ""
+}
+
+// containerIDFromMountInfo finds a container ID in the mount roots of a
+// /proc/<pid>/mountinfo file
+func containerIDFromMountInfo(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		// Field 4 is the root of the mount within its filesystem
+		if len(fields) < 4 {
+			continue
+		}
+		if match := containerMountPattern.FindStringSubmatch(fields[3]); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// ContainerRuntime is a best-effort guess of the environment the process runs in
+type ContainerRuntime struct {
+	Runtime  string   `json:"runtime"`  // "kubernetes", "podman", "docker", "containerd" or "none"
+	Evidence []string `json:"evidence"` // Every signal found, e.g. "/.dockerenv exists"
+}
+
+// containerSignals are the facts GetContainerRuntime classifies
+type containerSignals struct {
+	procCgroup        string // Contents of /proc/1/cgroup
+	dockerEnv         bool   // /.dockerenv exists
+	podmanEnv         bool   // /run/.containerenv exists
+	kubernetesEnv     bool   // KUBERNETES_SERVICE_HOST is set
+	kubernetesSecrets bool   // /run/secrets/kubernetes.io exists
+}
+
+// GetContainerRuntime guesses whether the process runs under Kubernetes, podman,
+// docker, containerd or none of them from /proc/1/cgroup, /.dockerenv,
+// /run/.containerenv, KUBERNETES_SERVICE_HOST and /run/secrets/kubernetes.io.
+// Kubernetes wins over the runtime it drives, so a pod on containerd reports
+// "kubernetes"; the evidence lists every signal found.
+func (Toolbox) GetContainerRuntime() (ContainerRuntime, error) {
+	if !isLinux() {
+		return ContainerRuntime{}, errors.New(ErrNotSupported)
+	}
+
+	// PID 1's cgroup is readable even when this process's own is namespaced away
+	procCgroup, _ := readFile("/proc/1/cgroup")
+	return classifyContainerRuntime(containerSignals{
+		procCgroup:        procCgroup,
+		dockerEnv:         fileExists("/.dockerenv"),
+		podmanEnv:         fileExists("/run/.containerenv"),
+		kubernetesEnv:     os.Getenv("KUBERNETES_SERVICE_HOST") != "",
+		kubernetesSecrets: fileExists("/run/secrets/kubernetes.io"),
+	}), nil
+}
+
+// classifyContainerRuntime picks the runtime the signals point to, most specific first
+func classifyContainerRuntime(signals containerSignals) ContainerRuntime {
+	var cgroupPaths []string
+	for _, cgroupPath := range parseProcCgroup(signals.procCgroup) {
+		cgroupPaths = append(cgroupPaths, cgroupPath)
+	}
+	cgroupMentions := func(word string) bool {
+		for _, cgroupPath := range cgroupPaths {
+			if strings.Contains(cgroupPath, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	result := ContainerRuntime{Runtime: "none", Evidence: []string{}}
+	found := func(runtime, evidence string) {
+		if result.Runtime == "none" {
+			result.Runtime = runtime
+		}
+		result.Evidence = append(result.Evidence, evidence)
+	}
+
+	if signals.kubernetesEnv {
+		found("kubernetes", "KUBERNETES_SERVICE_HOST is set")
+	}
+	if signals.kubernetesSecrets {
+		found("kubernetes", "/run/secrets/kubernetes.io exists")
+	}
+	if cgroupMentions("kubepods") {
+		found("kubernetes", "/proc/1/cgroup mentions kubepods")
+	}
+	if signals.podmanEnv {
+		found("podman", "/run/.containerenv exists")
+	}
+	if cgroupMentions("libpod") {
+		found("podman", "/proc/1/cgroup mentions libpod")
+	}
+	if signals.dockerEnv {
+		found("docker", "/.dockerenv exists")
+	}
+	if cgroupMentions("docker") {
+		found("docker", "/proc/1/cgroup mentions docker")
+	}
+	if cgroupMentions("containerd") {
+		found("containerd", "/proc/1/cgroup mentions containerd")
+	}
+	return result
+}
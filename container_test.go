@@ -0,0 +1,161 @@
+package toolbox
+
+import (
+	"strings"
+	"testing"
+)
+
+const testContainerID = "3f4e1c2b9a8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f"
+
+func TestContainerIDFromProcCgroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"docker v1", "12:memory:/docker/" + testContainerID + "\n11:cpu,cpuacct:/docker/" + testContainerID + "\n"},
+		{"docker systemd", "0::/system.slice/docker-" + testContainerID + ".scope\n"},
+		{"kubernetes cgroupfs", "4:memory:/kubepods/burstable/pod6f1b2c3d-1111-2222-3333-444455556666/" + testContainerID + "\n"},
+		{"kubernetes containerd", "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod6f1b2c3d_1111.slice/cri-containerd-" + testContainerID + ".scope\n"},
+		{"kubernetes cri-o", "0::/kubepods.slice/kubepods-pod6f1b2c3d_1111.slice/crio-" + testContainerID + ".scope\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if id := containerIDFromProcCgroup(tt.content); id != testContainerID {
+				t.Errorf("Expected %s, got %q", testContainerID, id)
+			}
+		})
+	}
+
+	// Host processes and cgroup namespaces have no ID in their paths
+	for _, content := range []string{"0::/\n", "0::/user.slice/user-1000.slice/session-2.scope\n", ""} {
+		if id := containerIDFromProcCgroup(content); id != "" {
+			t.Errorf("Expected no ID for %q, got %q", content, id)
+		}
+	}
+}
+
+func TestContainerIDFromMountInfo(t *testing.T) {
+	docker := `622 601 0:52 / / rw,relatime master:205 - overlay overlay rw
+641 622 254:1 /var/lib/docker/containers/` + testContainerID + `/resolv.conf /etc/resolv.conf rw,relatime - ext4 /dev/vda1 rw
+642 622 254:1 /var/lib/docker/containers/` + testContainerID + `/hostname /etc/hostname rw,relatime - ext4 /dev/vda1 rw
+`
+	if id := containerIDFromMountInfo(docker); id != testContainerID {
+		t.Errorf("Expected %s from docker mountinfo, got %q", testContainerID, id)
+	}
+
+	containerd := `1200 1100 0:300 /io.containerd.runtime.v2.task/k8s.io/` + testContainerID + `/rootfs / rw - overlay overlay rw
+`
+	if id := containerIDFromMountInfo(containerd); id != testContainerID {
+		t.Errorf("Expected %s from containerd mountinfo, got %q", testContainerID, id)
+	}
+
+	// Kubelet container directories are named, not IDs
+	kubelet := `1300 1200 254:1 /var/lib/kubelet/pods/6f1b2c3d/containers/app/0a1b2c3d /dev/termination-log rw - ext4 /dev/vda1 rw
+`
+	if id := containerIDFromMountInfo(kubelet); id != "" {
+		t.Errorf("Expected no ID from kubelet mounts, got %q", id)
+	}
+}
+
+func TestCgroupPathFromProcCgroup(t *testing.T) {
+	path, err := cgroupPathFromProcCgroup("0::/kubepods.slice/pod.slice\n")
+	if err != nil || path != "/kubepods.slice/pod.slice" {
+		t.Errorf("Expected unified path, got %q, %v", path, err)
+	}
+
+	path, err = cgroupPathFromProcCgroup("5:cpu,cpuacct:/docker/abc\n4:memory:/docker/abc\n1:name=systemd:/init.scope\n")
+	if err != nil || path != "/docker/abc" {
+		t.Errorf("Expected memory controller path, got %q, %v", path, err)
+	}
+
+	if _, err := cgroupPathFromProcCgroup(""); err == nil {
+		t.Error("Expected error without cgroup entries")
+	}
+}
+
+func TestGetContainerID(t *testing.T) {
+	toolbox := Toolbox{}
+
+	id, err := toolbox.GetContainerID()
+	if err != nil {
+		if id != "" {
+			t.Errorf("Expected empty ID with an error, got %q", id)
+		}
+		if !strings.Contains(err.Error(), ErrContainerNotFound) && !strings.Contains(err.Error(), ErrNotSupported) {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		t.Logf("GetContainerID failed (expected outside containers): %v", err)
+		return
+	}
+	if !containerIDPattern.MatchString(id) {
+		t.Errorf("Expected a 64 character hex ID, got %q", id)
+	}
+	t.Logf("Container ID: %s", id)
+}
+
+func TestGetCgroupPath(t *testing.T) {
+	toolbox := Toolbox{}
+
+	path, err := toolbox.GetCgroupPath()
+	if err != nil {
+		t.Logf("GetCgroupPath failed (expected outside Linux): %v", err)
+		return
+	}
+	if !strings.HasPrefix(path, "/") {
+		t.Errorf("Expected an absolute cgroup path, got %q", path)
+	}
+	t.Logf("Cgroup path: %s", path)
+}
+
+func TestClassifyContainerRuntime(t *testing.T) {
+	tests := []struct {
+		name     string
+		signals  containerSignals
+		runtime  string
+		evidence int
+	}{
+		{"bare metal", containerSignals{procCgroup: "0::/init.scope\n"}, "none", 0},
+		{"docker v1", containerSignals{procCgroup: "12:memory:/docker/" + testContainerID + "\n", dockerEnv: true}, "docker", 2},
+		{"docker with cgroup namespace", containerSignals{procCgroup: "0::/\n", dockerEnv: true}, "docker", 1},
+		{"podman", containerSignals{procCgroup: "0::/user.slice/libpod-" + testContainerID + ".scope\n", podmanEnv: true}, "podman", 2},
+		{"containerd", containerSignals{procCgroup: "0::/system.slice/containerd.service/default/" + testContainerID + "\n"}, "containerd", 1},
+		{
+			"kubernetes on containerd",
+			containerSignals{
+				procCgroup:        "0::/kubepods.slice/kubepods-burstable.slice/cri-containerd-" + testContainerID + ".scope\n",
+				kubernetesEnv:     true,
+				kubernetesSecrets: true,
+			},
+			"kubernetes", 4,
+		},
+	}
+
+	for _, tt := range tests {
+		result := classifyContainerRuntime(tt.signals)
+		if result.Runtime != tt.runtime {
+			t.Errorf("%s: expected %q, got %q (evidence %v)", tt.name, tt.runtime, result.Runtime, result.Evidence)
+		}
+		if len(result.Evidence) != tt.evidence {
+			t.Errorf("%s: expected %d pieces of evidence, got %v", tt.name, tt.evidence, result.Evidence)
+		}
+	}
+}
+
+func TestGetContainerRuntime(t *testing.T) {
+	result, err := Toolbox{}.GetContainerRuntime()
+	if !isLinux() {
+		if err == nil {
+			t.Error("Expected error on non-Linux platforms")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("GetContainerRuntime() error: %v", err)
+	}
+	if result.Runtime == "" || (result.Runtime != "none" && len(result.Evidence) == 0) {
+		t.Errorf("Expected a runtime backed by evidence, got %+v", result)
+	}
+
+	t.Logf("Container runtime: %+v", result)
+}
@@ -0,0 +1,173 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// diskLatencyBlockSize is the size of each probe write, one filesystem block
+const diskLatencyBlockSize = 4096
+
+// maxDiskLatencySamples caps MeasureDiskLatency so a typo can't stall a VU for minutes
+const maxDiskLatencySamples = 1000
+
+// DiskInfo contains filesystem usage for a path
+type DiskInfo struct {
+	Path         string  `json:"path"`
+	MountPoint   string  `json:"mount_point"`
+	TotalBytes   int64   `json:"total_bytes"`
+	UsedBytes    int64   `json:"used_bytes"`
+	FreeBytes    int64   `json:"free_bytes"`    // Space available to unprivileged users
+	UsagePercent float64 `json:"usage_percent"` // Used / (used + free), as reported by df
+}
+
+// DiskLatency summarizes synchronous write latency measured under a path
+type DiskLatency struct {
+	Path    string  `json:"path"`
+	Samples int     `json:"samples"`
+	MinMs   float64 `json:"min_ms"`
+	AvgMs   float64 `json:"avg_ms"`
+	MaxMs   float64 `json:"max_ms"`
+	P95Ms   float64 `json:"p95_ms" js:"p95_ms"`
+}
+
+// GetDiskUsage returns usage of the filesystem containing path ("/" if empty)
+func (Toolbox) GetDiskUsage(path string) (DiskInfo, error) {
+	if path == "" {
+		path = "/"
+	}
+	return getDiskUsage(path)
+}
+
+// MeasureDiskLatency writes and fsyncs a block to a temporary file under path
+// (the system temp directory if empty) samples times and returns the latency of
+// each synchronous write. The file is opened with O_SYNC so writes bypass the
+// page cache's write-back, and it is removed afterwards even on error.
+func (Toolbox) MeasureDiskLatency(path string, samples int) (DiskLatency, error) {
+	if path == "" {
+		path = os.TempDir()
+	}
+	return measureDiskLatency(path, samples)
+}
+
+// measureDiskLatency implements MeasureDiskLatency
+func measureDiskLatency(path string, samples int) (DiskLatency, error) {
+	result := DiskLatency{Path: path}
+
+	if samples <= 0 || samples > maxDiskLatencySamples {
+		return result, fmt.Errorf("samples must be between 1 and %d", maxDiskLatencySamples)
+	}
+
+	file, err := os.CreateTemp(path, ".xk6-toolbox-latency-*")
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", ErrReadingFile, err)
+	}
+	defer os.Remove(file.Name())
+	file.Close()
+
+	file, err = os.OpenFile(file.Name(), os.O_WRONLY|os.O_SYNC, 0o600)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", ErrReadingFile, err)
+	}
+	defer file.Close()
+
+	block := make([]byte, diskLatencyBlockSize)
+	latencies := make([]float64, 0, samples)
+	for range samples {
+		start := time.Now()
+		if _, err := file.WriteAt(block, 0); err != nil {
+			return result, fmt.Errorf("failed to write probe file: %w", err)
+		}
+		if err := file.Sync(); err != nil {
+			return result, fmt.Errorf("failed to sync probe file: %w", err)
+		}
+		latencies = append(latencies, float64(time.Since(start))/float64(time.Millisecond))
+	}
+
+	summary := summarize(latencies)
+	result.Samples = samples
+	result.MinMs = summary.Min
+	result.AvgMs = summary.Mean
+	result.MaxMs = summary.Max
+	result.P95Ms = summary.P95
+	return result, nil
+}
+
+// diskInfoFromBlocks fills in DiskInfo from statfs block counts
+func diskInfoFromBlocks(path string, blockSize, blocks, free, available uint64) DiskInfo {
+	info := DiskInfo{
+		Path:       path,
+		TotalBytes: int64(blocks * blockSize),
+		UsedBytes:  int64((blocks - free) * blockSize),
+		FreeBytes:  int64(available * blockSize),
+	}
+	if usable := info.UsedBytes + info.FreeBytes; usable > 0 {
+		info.UsagePercent = float64(info.UsedBytes) / float64(usable) * 100
+	}
+	return info
+}
+
+// findMountPoint returns the longest mount point in /proc/mounts content that contains path
+func findMountPoint(path, mounts string) (string, error) {
+	best := ""
+	for _, line := range strings.Split(mounts, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		mountPoint := unescapeMountField(fields[1])
+		if !pathWithin(path, mountPoint) {
+			continue
+		}
+		if len(mountPoint) > len(best) {
+			best = mountPoint
+		}
+	}
+	if best == "" {
+		return "", errors.New("no mount point found for " + path)
+	}
+	return best, nil
+}
+
+// pathWithin reports whether path is dir or below it
+func pathWithin(path, dir string) bool {
+	if dir == "/" || path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, dir+"/")
+}
+
+// unescapeMountField decodes the octal escapes (e.g. \040 for space) used in /proc/mounts
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			if value, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(value))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
+
+// resolveDiskPath makes path absolute and resolves symlinks so it can be matched to a mount point
+func resolveDiskPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path
+}
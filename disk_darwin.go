@@ -0,0 +1,32 @@
+//go:build darwin
+
+package toolbox
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// getDiskUsage stats the filesystem containing path, which also reports its mount point
+func getDiskUsage(path string) (DiskInfo, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return DiskInfo{}, fmt.Errorf("%s: %w", ErrReadingFile, err)
+	}
+
+	info := diskInfoFromBlocks(path, uint64(stat.Bsize), stat.Blocks, stat.Bfree, stat.Bavail)
+	info.MountPoint = cString(stat.Mntonname[:])
+	return info, nil
+}
+
+// cString converts a NUL-terminated C char array to a string
+func cString(chars []int8) string {
+	b := make([]byte, 0, len(chars))
+	for _, c := range chars {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	return string(b)
+}
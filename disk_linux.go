@@ -0,0 +1,22 @@
+//go:build linux
+
+package toolbox
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// getDiskUsage stats the filesystem containing path and finds its mount point in /proc/self/mounts
+func getDiskUsage(path string) (DiskInfo, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return DiskInfo{}, fmt.Errorf("%s: %w", ErrReadingFile, err)
+	}
+
+	info := diskInfoFromBlocks(path, uint64(stat.Bsize), stat.Blocks, stat.Bfree, stat.Bavail)
+	if mounts, err := readFile("/proc/self/mounts"); err == nil {
+		info.MountPoint, _ = findMountPoint(resolveDiskPath(path), mounts)
+	}
+	return info, nil
+}
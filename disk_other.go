@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package toolbox
+
+import "errors"
+
+// getDiskUsage is not supported on this platform
+func getDiskUsage(path string) (DiskInfo, error) {
+	return DiskInfo{}, errors.New(ErrNotSupported)
+}
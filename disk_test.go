@@ -0,0 +1,112 @@
+package toolbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskInfoFromBlocks(t *testing.T) {
+	// 1000 blocks of 4KiB, 500 free of which 300 are available to users
+	info := diskInfoFromBlocks("/data", 4096, 1000, 500, 300)
+
+	if info.TotalBytes != 1000*4096 || info.UsedBytes != 500*4096 || info.FreeBytes != 300*4096 {
+		t.Errorf("Unexpected byte counts: %+v", info)
+	}
+	// Reserved blocks don't count as usable: 500 / (500 + 300)
+	if info.UsagePercent != 62.5 {
+		t.Errorf("Expected df-style usage 62.5%%, got %f", info.UsagePercent)
+	}
+
+	// An empty filesystem must not divide by zero
+	if info := diskInfoFromBlocks("/", 4096, 0, 0, 0); info.UsagePercent != 0 {
+		t.Errorf("Expected 0%% usage, got %f", info.UsagePercent)
+	}
+}
+
+func TestFindMountPoint(t *testing.T) {
+	mounts := `overlay / overlay rw,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+/dev/sda1 /data ext4 rw,relatime 0 0
+/dev/sdb1 /data/results ext4 rw,relatime 0 0
+/dev/sdc1 /mnt/my\040disk ext4 rw,relatime 0 0
+`
+
+	tests := map[string]string{
+		"/":                     "/",
+		"/tmp/file":             "/",
+		"/data":                 "/data",
+		"/data/results/out.csv": "/data/results",
+		"/data/resultsold":      "/data",
+		"/mnt/my disk/file":     "/mnt/my disk",
+	}
+	for path, expected := range tests {
+		mountPoint, err := findMountPoint(path, mounts)
+		if err != nil {
+			t.Errorf("findMountPoint(%q) failed: %v", path, err)
+			continue
+		}
+		if mountPoint != expected {
+			t.Errorf("findMountPoint(%q): expected '%s', got '%s'", path, expected, mountPoint)
+		}
+	}
+
+	if _, err := findMountPoint("/data", ""); err == nil {
+		t.Error("Expected error without mounts")
+	}
+}
+
+func TestGetDiskUsage(t *testing.T) {
+	toolbox := Toolbox{}
+	info, err := toolbox.GetDiskUsage("")
+	if err != nil {
+		t.Logf("GetDiskUsage failed (expected on unsupported platforms): %v", err)
+		return
+	}
+
+	if info.Path != "/" {
+		t.Errorf("Expected default path '/', got '%s'", info.Path)
+	}
+	if info.TotalBytes <= 0 || info.UsedBytes > info.TotalBytes {
+		t.Errorf("Unexpected disk usage: %+v", info)
+	}
+	if info.MountPoint == "" {
+		t.Error("Expected a mount point")
+	}
+
+	if _, err := toolbox.GetDiskUsage("/nonexistent/path"); err == nil {
+		t.Error("Expected error for nonexistent path")
+	}
+
+	t.Logf("Disk usage: %+v", info)
+}
+
+func TestMeasureDiskLatency(t *testing.T) {
+	dir := t.TempDir()
+	toolbox := Toolbox{}
+
+	result, err := toolbox.MeasureDiskLatency(dir, 5)
+	if err != nil {
+		t.Fatalf("MeasureDiskLatency failed: %v", err)
+	}
+	if result.Samples != 5 || result.Path != dir {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.MinMs <= 0 || result.MinMs > result.AvgMs || result.AvgMs > result.MaxMs || result.P95Ms > result.MaxMs {
+		t.Errorf("Expected 0 < min <= avg <= max and p95 <= max, got %+v", result)
+	}
+
+	// The probe file is removed
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected the probe file to be removed, found %d entries", len(entries))
+	}
+
+	if _, err := toolbox.MeasureDiskLatency(dir, 0); err == nil {
+		t.Error("Expected error for 0 samples")
+	}
+	if _, err := toolbox.MeasureDiskLatency(filepath.Join(dir, "missing"), 1); err == nil {
+		t.Error("Expected error for a missing directory")
+	}
+
+	t.Logf("Disk latency: %+v", result)
+}
@@ -0,0 +1,81 @@
+package toolbox
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// DNSResult holds the records a domain resolved to. Each query fails on its own,
+// so a domain without AAAA records still reports its A records.
+type DNSResult struct {
+	Domain string   `json:"domain"`
+	IPv4   []string `json:"ipv4" js:"ipv4"` // A records
+	IPv6   []string `json:"ipv6" js:"ipv6"` // AAAA records
+	// CNAME is the canonical name at the end of the CNAME chain, empty when the
+	// domain has no CNAME. The resolver doesn't expose intermediate names.
+	CNAME     string            `json:"cname" js:"cname"`
+	Errors    map[string]string `json:"errors"` // Error of each failed query keyed by "a", "aaaa" or "cname"
+	ElapsedMs float64           `json:"elapsed_ms"`
+}
+
+// ResolveDomain looks up the A, AAAA and CNAME records of domain.
+// timeoutSeconds: deadline for all queries together (default 5 if <=0).
+// An error is only returned when every query failed.
+func (Toolbox) ResolveDomain(domain string, timeoutSeconds int) (DNSResult, error) {
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = 5
+	}
+	return resolveDomain(net.DefaultResolver, domain, time.Duration(timeoutSeconds)*time.Second)
+}
+
+// resolveDomain runs the lookups of ResolveDomain with resolver
+func resolveDomain(resolver *net.Resolver, domain string, timeout time.Duration) (DNSResult, error) {
+	result := DNSResult{
+		Domain: domain,
+		IPv4:   []string{},
+		IPv6:   []string{},
+		Errors: map[string]string{},
+	}
+	if domain == "" {
+		return result, errors.New("domain must not be empty")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+
+	for _, query := range []struct {
+		name    string
+		network string
+		target  *[]string
+	}{
+		{"a", "ip4", &result.IPv4},
+		{"aaaa", "ip6", &result.IPv6},
+	} {
+		ips, err := resolver.LookupIP(ctx, query.network, domain)
+		if err != nil {
+			result.Errors[query.name] = err.Error()
+			continue
+		}
+		for _, ip := range ips {
+			*query.target = append(*query.target, ip.String())
+		}
+	}
+
+	cname, err := resolver.LookupCNAME(ctx, domain)
+	if err != nil {
+		result.Errors["cname"] = err.Error()
+	} else if cname = strings.TrimSuffix(cname, "."); !strings.EqualFold(cname, strings.TrimSuffix(domain, ".")) {
+		// A domain without a CNAME is its own canonical name
+		result.CNAME = cname
+	}
+
+	result.ElapsedMs = float64(time.Since(start).Microseconds()) / 1000
+	if len(result.Errors) == 3 {
+		return result, errors.New("all DNS queries failed: " + result.Errors["a"])
+	}
+	return result, nil
+}
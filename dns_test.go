@@ -0,0 +1,55 @@
+package toolbox
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestResolveDomainLocalhost(t *testing.T) {
+	toolbox := Toolbox{}
+	result, err := toolbox.ResolveDomain("localhost", 2)
+	if err != nil {
+		t.Skipf("localhost does not resolve in this environment: %v", err)
+	}
+
+	if len(result.IPv4) == 0 && len(result.IPv6) == 0 {
+		t.Errorf("Expected localhost to resolve to at least one address, got %+v", result)
+	}
+	for _, ip := range result.IPv4 {
+		if parsed := net.ParseIP(ip); parsed == nil || parsed.To4() == nil {
+			t.Errorf("Expected an IPv4 address, got %q", ip)
+		}
+	}
+	if result.CNAME != "" {
+		t.Errorf("Expected no CNAME for localhost, got %q", result.CNAME)
+	}
+
+	t.Logf("DNS result: %+v", result)
+}
+
+func TestResolveDomainFailure(t *testing.T) {
+	// A resolver that can't reach any server makes every query fail
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(_ context.Context, _, _ string) (net.Conn, error) {
+			return nil, errors.New("no DNS server")
+		},
+	}
+
+	result, err := resolveDomain(resolver, "example.invalid", time.Second)
+	if err == nil {
+		t.Fatal("Expected error when every query fails")
+	}
+	for _, query := range []string{"a", "aaaa", "cname"} {
+		if result.Errors[query] == "" {
+			t.Errorf("Expected an error for the %s query, got %+v", query, result.Errors)
+		}
+	}
+
+	if _, err := resolveDomain(resolver, "", time.Second); err == nil {
+		t.Error("Expected error for an empty domain")
+	}
+}
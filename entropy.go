@@ -0,0 +1,33 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// entropyAvailPath is the kernel's estimate of available entropy in bits
+const entropyAvailPath = "/proc/sys/kernel/random/entropy_avail"
+
+// GetEntropyAvailable returns the kernel's available entropy in bits. Low values
+// can stall TLS handshakes and token generation on older kernels. Linux only.
+func (Toolbox) GetEntropyAvailable() (int, error) {
+	if !isLinux() {
+		return 0, errors.New(ErrNotSupported)
+	}
+	return readEntropyAvailable(entropyAvailPath)
+}
+
+// readEntropyAvailable reads an entropy_avail file
+func readEntropyAvailable(path string) (int, error) {
+	content, err := readFile(path)
+	if err != nil {
+		return 0, err
+	}
+	bits, err := strconv.Atoi(strings.TrimSpace(content))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+	return bits, nil
+}
@@ -0,0 +1,40 @@
+package toolbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadEntropyAvailable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entropy_avail")
+	if err := os.WriteFile(path, []byte("256\n"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	if bits, err := readEntropyAvailable(path); err != nil || bits != 256 {
+		t.Errorf("Expected 256 bits, got %d (%v)", bits, err)
+	}
+
+	if err := os.WriteFile(path, []byte("garbage\n"), 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	if _, err := readEntropyAvailable(path); err == nil {
+		t.Error("Expected error for invalid content")
+	}
+}
+
+func TestGetEntropyAvailable(t *testing.T) {
+	bits, err := Toolbox{}.GetEntropyAvailable()
+	if !isLinux() {
+		if err == nil {
+			t.Error("Expected error on non-Linux platforms")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("GetEntropyAvailable() error: %v", err)
+	}
+	if bits < 0 {
+		t.Errorf("Expected non-negative entropy, got %d", bits)
+	}
+}
@@ -0,0 +1,38 @@
+package toolbox
+
+import (
+	"os"
+	"strings"
+)
+
+// GetHostname returns the host name reported by the kernel, which is the pod or
+// container name in most container runtimes
+func (Toolbox) GetHostname() (string, error) {
+	return os.Hostname()
+}
+
+// GetEnvSnapshot returns the environment variables whose names start with one of
+// prefixes, e.g. ["K6_", "KUBE_"]. No prefixes returns an empty map rather than
+// the whole environment, which may hold secrets.
+func (Toolbox) GetEnvSnapshot(prefixes []string) (map[string]string, error) {
+	return filterEnv(os.Environ(), prefixes), nil
+}
+
+// filterEnv picks the "name=value" entries whose name starts with one of prefixes.
+// Empty prefixes are ignored so they can't match everything.
+func filterEnv(environ []string, prefixes []string) map[string]string {
+	snapshot := make(map[string]string)
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		for _, prefix := range prefixes {
+			if prefix != "" && strings.HasPrefix(name, prefix) {
+				snapshot[name] = value
+				break
+			}
+		}
+	}
+	return snapshot
+}
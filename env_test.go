@@ -0,0 +1,63 @@
+package toolbox
+
+import (
+	"testing"
+)
+
+func TestGetHostname(t *testing.T) {
+	toolbox := Toolbox{}
+	hostname, err := toolbox.GetHostname()
+	if err != nil {
+		t.Fatalf("GetHostname failed: %v", err)
+	}
+	if hostname == "" {
+		t.Error("Expected a non-empty hostname")
+	}
+}
+
+func TestFilterEnv(t *testing.T) {
+	environ := []string{
+		"K6_VUS=10",
+		"K6_DURATION=1m",
+		"KUBE_NAMESPACE=load",
+		"AWS_SECRET_ACCESS_KEY=secret",
+		"EMPTY=",
+		"K6_OPTS=a=b",
+	}
+
+	snapshot := filterEnv(environ, []string{"K6_", "KUBE_"})
+	expected := map[string]string{
+		"K6_VUS":         "10",
+		"K6_DURATION":    "1m",
+		"KUBE_NAMESPACE": "load",
+		"K6_OPTS":        "a=b",
+	}
+	if len(snapshot) != len(expected) {
+		t.Errorf("Expected %d variables, got %v", len(expected), snapshot)
+	}
+	for name, value := range expected {
+		if snapshot[name] != value {
+			t.Errorf("Expected %s=%q, got %q", name, value, snapshot[name])
+		}
+	}
+
+	if snapshot := filterEnv(environ, nil); len(snapshot) != 0 {
+		t.Errorf("Expected no variables without prefixes, got %v", snapshot)
+	}
+	if snapshot := filterEnv(environ, []string{""}); len(snapshot) != 0 {
+		t.Errorf("Expected an empty prefix to match nothing, got %v", snapshot)
+	}
+}
+
+func TestGetEnvSnapshot(t *testing.T) {
+	t.Setenv("XK6_TOOLBOX_TEST_VAR", "value")
+
+	toolbox := Toolbox{}
+	snapshot, err := toolbox.GetEnvSnapshot([]string{"XK6_TOOLBOX_TEST_"})
+	if err != nil {
+		t.Fatalf("GetEnvSnapshot failed: %v", err)
+	}
+	if len(snapshot) != 1 || snapshot["XK6_TOOLBOX_TEST_VAR"] != "value" {
+		t.Errorf("Expected only XK6_TOOLBOX_TEST_VAR, got %v", snapshot)
+	}
+}
@@ -0,0 +1,154 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FDBreakdown counts a process's open file descriptors grouped by type
+type FDBreakdown struct {
+	PID       int `json:"pid" js:"pid"`
+	Total     int `json:"total"`
+	Regular   int `json:"regular"`              // Regular files, directories and devices
+	Socket    int `json:"socket"`               // Network and unix sockets
+	Pipe      int `json:"pipe"`                 // Anonymous and named pipes
+	Epoll     int `json:"epoll"`                // anon_inode:[eventpoll]
+	EventFD   int `json:"eventfd" js:"eventfd"` // anon_inode:[eventfd]
+	AnonInode int `json:"anon_inode"`           // Other anon_inode descriptors (timerfd, inotify, signalfd, ...)
+	Other     int `json:"other"`                // Descriptors whose target could not be classified
+}
+
+// FDInfo reports the current process's open file descriptors against its RLIMIT_NOFILE limits
+type FDInfo struct {
+	Open         int     `json:"open"`
+	SoftLimit    int64   `json:"soft_limit"`    // -1 if unlimited
+	HardLimit    int64   `json:"hard_limit"`    // -1 if unlimited
+	UsagePercent float64 `json:"usage_percent"` // Open as a percentage of the soft limit, 0 if unlimited
+}
+
+// RLimit is the soft and hard value of a resource limit, -1 meaning unlimited
+type RLimit struct {
+	Soft int64 `json:"soft"`
+	Hard int64 `json:"hard"`
+}
+
+// GetResourceLimits returns the current process's resource limits keyed by
+// name: as, core, cpu, data, fsize, memlock, nofile, nproc and stack.
+// Linux and macOS only.
+func (Toolbox) GetResourceLimits() (map[string]RLimit, error) {
+	return getResourceLimits()
+}
+
+// GetFileDescriptorInfo returns the number of open file descriptors of the
+// current process and its soft and hard limits
+func (Toolbox) GetFileDescriptorInfo() (FDInfo, error) {
+	var info FDInfo
+
+	open, err := countOpenFDs()
+	if err != nil {
+		return info, err
+	}
+	info.Open = open
+
+	info.SoftLimit, info.HardLimit, err = getNoFileLimit()
+	if err != nil {
+		return info, err
+	}
+	if info.SoftLimit > 0 {
+		info.UsagePercent = float64(info.Open) / float64(info.SoftLimit) * 100
+	}
+	return info, nil
+}
+
+// countOpenFDs counts the current process's open descriptors from /proc/self/fd
+// on Linux or /dev/fd on macOS, excluding the one used to list the directory
+func countOpenFDs() (int, error) {
+	dir := "/proc/self/fd"
+	if isMacOS() {
+		dir = "/dev/fd"
+	} else if !isLinux() {
+		return 0, errors.New(ErrNotSupported)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrReadingFile, err)
+	}
+	return max(len(entries)-1, 0), nil
+}
+
+// GetFileDescriptorBreakdown returns open file descriptors of a process grouped by type.
+// pid: process to inspect (the current process if <=0)
+func (Toolbox) GetFileDescriptorBreakdown(pid int) (FDBreakdown, error) {
+	return getFDBreakdown(pid)
+}
+
+// getFDBreakdown classifies the /proc/<pid>/fd symlink targets of a process (Linux only)
+func getFDBreakdown(pid int) (FDBreakdown, error) {
+	var breakdown FDBreakdown
+
+	if !isLinux() {
+		return breakdown, errors.New(ErrNotSupported)
+	}
+	if pid <= 0 {
+		pid = os.Getpid()
+	}
+	breakdown.PID = pid
+
+	fdDir := filepath.Join("/proc", strconv.Itoa(pid), "fd")
+	entries, err := os.ReadDir(fdDir)
+	if err != nil {
+		return breakdown, fmt.Errorf("%s: %w", ErrReadingFile, err)
+	}
+
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join(fdDir, entry.Name()))
+		if err != nil {
+			// The descriptor was closed between listing and reading it
+			continue
+		}
+		breakdown.Total++
+		switch classifyFDTarget(target) {
+		case "regular":
+			breakdown.Regular++
+		case "socket":
+			breakdown.Socket++
+		case "pipe":
+			breakdown.Pipe++
+		case "epoll":
+			breakdown.Epoll++
+		case "eventfd":
+			breakdown.EventFD++
+		case "anon_inode":
+			breakdown.AnonInode++
+		default:
+			breakdown.Other++
+		}
+	}
+
+	return breakdown, nil
+}
+
+// classifyFDTarget maps a /proc/<pid>/fd symlink target to a descriptor type
+func classifyFDTarget(target string) string {
+	switch {
+	case strings.HasPrefix(target, "socket:"):
+		return "socket"
+	case strings.HasPrefix(target, "pipe:"):
+		return "pipe"
+	case target == "anon_inode:[eventpoll]":
+		return "epoll"
+	case target == "anon_inode:[eventfd]":
+		return "eventfd"
+	case strings.HasPrefix(target, "anon_inode:"):
+		return "anon_inode"
+	case strings.HasPrefix(target, "/"):
+		return "regular"
+	default:
+		return "other"
+	}
+}
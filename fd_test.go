@@ -0,0 +1,86 @@
+package toolbox
+
+import (
+	"net"
+	"testing"
+)
+
+func TestClassifyFDTarget(t *testing.T) {
+	tests := map[string]string{
+		"/var/log/app.log":       "regular",
+		"/dev/null":              "regular",
+		"socket:[123456]":        "socket",
+		"pipe:[98765]":           "pipe",
+		"anon_inode:[eventpoll]": "epoll",
+		"anon_inode:[eventfd]":   "eventfd",
+		"anon_inode:[timerfd]":   "anon_inode",
+		"anon_inode:inotify":     "anon_inode",
+		"net:[4026531840]":       "other",
+	}
+
+	for target, expected := range tests {
+		if got := classifyFDTarget(target); got != expected {
+			t.Errorf("classifyFDTarget(%q): expected '%s', got '%s'", target, expected, got)
+		}
+	}
+}
+
+func TestGetFileDescriptorBreakdown(t *testing.T) {
+	// Hold a socket open so at least one is counted
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to open listener: %v", err)
+	}
+	defer listener.Close()
+
+	toolbox := Toolbox{}
+	breakdown, err := toolbox.GetFileDescriptorBreakdown(0)
+	if err != nil {
+		t.Logf("GetFileDescriptorBreakdown failed (expected outside Linux): %v", err)
+		return
+	}
+
+	if breakdown.Socket < 1 {
+		t.Errorf("Expected at least 1 socket, got %d", breakdown.Socket)
+	}
+
+	sum := breakdown.Regular + breakdown.Socket + breakdown.Pipe + breakdown.Epoll +
+		breakdown.EventFD + breakdown.AnonInode + breakdown.Other
+	if sum != breakdown.Total {
+		t.Errorf("Expected type counts to sum to total %d, got %d", breakdown.Total, sum)
+	}
+
+	t.Logf("FD breakdown: %+v", breakdown)
+}
+
+func TestGetFileDescriptorInfo(t *testing.T) {
+	toolbox := Toolbox{}
+	before, err := toolbox.GetFileDescriptorInfo()
+	if err != nil {
+		t.Logf("GetFileDescriptorInfo failed (expected on unsupported platforms): %v", err)
+		return
+	}
+
+	// Opening a socket should raise the count by one
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to open listener: %v", err)
+	}
+	defer listener.Close()
+
+	after, err := toolbox.GetFileDescriptorInfo()
+	if err != nil {
+		t.Fatalf("GetFileDescriptorInfo failed: %v", err)
+	}
+	if after.Open != before.Open+1 {
+		t.Errorf("Expected %d open descriptors after opening a socket, got %d", before.Open+1, after.Open)
+	}
+	if after.SoftLimit != -1 && (after.SoftLimit <= 0 || after.UsagePercent <= 0) {
+		t.Errorf("Unexpected limits: %+v", after)
+	}
+	if after.HardLimit != -1 && after.SoftLimit > after.HardLimit {
+		t.Errorf("Expected soft limit <= hard limit, got %+v", after)
+	}
+
+	t.Logf("File descriptors: %+v", after)
+}
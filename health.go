@@ -0,0 +1,112 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// HealthOptions selects the checks HealthCheck runs. Zero or empty fields skip
+// their check.
+type HealthOptions struct {
+	MaxCPUPercent    float64  `json:"max_cpu_percent"`    // CPU usage must be at or below this
+	MaxMemoryPercent float64  `json:"max_memory_percent"` // Memory usage must be at or below this
+	Endpoints        []Target `json:"endpoints"`          // Each must resolve and accept a TCP connection
+	DiskPath         string   `json:"disk_path"`          // Filesystem checked by MinFreeDiskBytes, default "/"
+	MinFreeDiskBytes int64    `json:"min_free_disk_bytes"`
+}
+
+// HealthCheckResult is the outcome of one check of a HealthReport
+type HealthCheckResult struct {
+	Name      string  `json:"name"` // "cpu", "memory", "disk" or "endpoint:<host:port>"
+	Passed    bool    `json:"passed"`
+	Value     float64 `json:"value"`     // Measured value, 0 for endpoints
+	Threshold float64 `json:"threshold"` // Configured limit, 0 for endpoints
+	Detail    string  `json:"detail"`    // Why the check failed, empty if it passed
+}
+
+// HealthReport is the result of HealthCheck
+type HealthReport struct {
+	Healthy   bool                `json:"healthy"` // Whether every check passed
+	Checks    []HealthCheckResult `json:"checks"`
+	ElapsedMs float64             `json:"elapsed_ms"`
+}
+
+// HealthCheck runs the checks enabled in opts and reports each one and whether
+// all passed. A check whose value can't be collected fails. Endpoints are
+// checked concurrently. It only returns an error for invalid options, so
+// scripts can abort from setup() when the report isn't healthy.
+func (tb Toolbox) HealthCheck(opts HealthOptions) (HealthReport, error) {
+	report := HealthReport{Checks: []HealthCheckResult{}}
+
+	if opts.MaxCPUPercent < 0 || opts.MaxMemoryPercent < 0 || opts.MinFreeDiskBytes < 0 {
+		return report, errors.New("health thresholds must not be negative")
+	}
+	for _, endpoint := range opts.Endpoints {
+		if endpoint.Domain == "" {
+			return report, errors.New("endpoint domain must not be empty")
+		}
+	}
+
+	start := time.Now()
+	if opts.MaxCPUPercent > 0 {
+		result := HealthCheckResult{Name: "cpu", Threshold: opts.MaxCPUPercent}
+		info, err := tb.GetCPUInfo()
+		result.Value = info.UsagePercent
+		report.Checks = append(report.Checks, maxThresholdResult(result, err, "CPU usage %.1f%% is above %.1f%%"))
+	}
+	if opts.MaxMemoryPercent > 0 {
+		result := HealthCheckResult{Name: "memory", Threshold: opts.MaxMemoryPercent}
+		info, err := tb.GetMemoryInfo()
+		result.Value = info.UsagePercent
+		report.Checks = append(report.Checks, maxThresholdResult(result, err, "memory usage %.1f%% is above %.1f%%"))
+	}
+	if opts.MinFreeDiskBytes > 0 {
+		result := HealthCheckResult{Name: "disk", Threshold: float64(opts.MinFreeDiskBytes)}
+		info, err := tb.GetDiskUsage(opts.DiskPath)
+		result.Value = float64(info.FreeBytes)
+		switch {
+		case err != nil:
+			result.Detail = err.Error()
+		case info.FreeBytes < opts.MinFreeDiskBytes:
+			result.Detail = fmt.Sprintf("%d bytes free on %s, below %d", info.FreeBytes, info.MountPoint, opts.MinFreeDiskBytes)
+		default:
+			result.Passed = true
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	for _, endpoint := range CheckConnectivityBatch(opts.Endpoints) {
+		result := HealthCheckResult{Name: "endpoint:" + net.JoinHostPort(endpoint.Domain, endpoint.Port)}
+		switch endpoint.FailedLayer {
+		case "dns":
+			result.Detail = "DNS failed: " + endpoint.DNS
+		case "tcp":
+			result.Detail = "TCP failed: " + endpoint.TCP
+		default:
+			result.Passed = true
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	report.Healthy = true
+	for _, check := range report.Checks {
+		report.Healthy = report.Healthy && check.Passed
+	}
+	report.ElapsedMs = elapsedMs(start)
+	return report, nil
+}
+
+// maxThresholdResult completes a check whose value must not exceed its threshold
+func maxThresholdResult(result HealthCheckResult, err error, format string) HealthCheckResult {
+	switch {
+	case err != nil:
+		result.Detail = err.Error()
+	case result.Value > result.Threshold:
+		result.Detail = fmt.Sprintf(format, result.Value, result.Threshold)
+	default:
+		result.Passed = true
+	}
+	return result
+}
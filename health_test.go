@@ -0,0 +1,86 @@
+package toolbox
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHealthCheck(t *testing.T) {
+	root := t.TempDir()
+	writeCgroupFile(t, root, "memory.max", "1073741824\n")
+	writeCgroupFile(t, root, "memory.current", "805306368\n")
+	Configure(Options{CgroupRoot: root})
+	t.Cleanup(func() { Configure(Options{}) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, openPort, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to open listener: %v", err)
+	}
+	_, closedPort, _ := net.SplitHostPort(closed.Addr().String())
+	closed.Close()
+
+	toolbox := Toolbox{}
+	report, err := toolbox.HealthCheck(HealthOptions{
+		MaxMemoryPercent: 90,
+		Endpoints: []Target{
+			{Domain: "127.0.0.1", Port: openPort, TimeoutSeconds: 2},
+			{Domain: "127.0.0.1", Port: closedPort, TimeoutSeconds: 2},
+		},
+		MinFreeDiskBytes: 1,
+		DiskPath:         t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("HealthCheck() error: %v", err)
+	}
+
+	checks := make(map[string]HealthCheckResult)
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+	if len(checks) != 4 {
+		t.Fatalf("Expected memory, disk and two endpoint checks, got %+v", report.Checks)
+	}
+	if _, ok := checks["cpu"]; ok {
+		t.Error("Expected the CPU check to be skipped without a threshold")
+	}
+	if memory := checks["memory"]; isLinux() && (!memory.Passed || memory.Value != 75) {
+		t.Errorf("Expected memory at 75%% to pass, got %+v", memory)
+	}
+	if !checks["endpoint:127.0.0.1:"+openPort].Passed {
+		t.Errorf("Expected the listening endpoint to pass, got %+v", checks["endpoint:127.0.0.1:"+openPort])
+	}
+	if failed := checks["endpoint:127.0.0.1:"+closedPort]; failed.Passed || !strings.HasPrefix(failed.Detail, "TCP failed") {
+		t.Errorf("Expected the closed endpoint to fail at TCP, got %+v", failed)
+	}
+	if report.Healthy {
+		t.Error("Expected the report to be unhealthy with a failed check")
+	}
+
+	// Tighter memory threshold
+	report, err = toolbox.HealthCheck(HealthOptions{MaxMemoryPercent: 50})
+	if err != nil {
+		t.Fatalf("HealthCheck() error: %v", err)
+	}
+	if isLinux() && (report.Healthy || report.Checks[0].Detail == "") {
+		t.Errorf("Expected memory at 75%% to fail a 50%% threshold, got %+v", report)
+	}
+
+	// No checks enabled
+	if report, err := toolbox.HealthCheck(HealthOptions{}); err != nil || !report.Healthy || len(report.Checks) != 0 {
+		t.Errorf("Expected an empty healthy report, got %+v (%v)", report, err)
+	}
+
+	if _, err := toolbox.HealthCheck(HealthOptions{MaxCPUPercent: -1}); err == nil {
+		t.Error("Expected error for a negative threshold")
+	}
+	if _, err := toolbox.HealthCheck(HealthOptions{Endpoints: []Target{{Port: "80"}}}); err == nil {
+		t.Error("Expected error for an endpoint without a domain")
+	}
+}
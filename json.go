@@ -0,0 +1,42 @@
+package toolbox
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+)
+
+// jsonFloatDecimals is the precision of floating point fields in GetSystemInfoJSON
+const jsonFloatDecimals = 2
+
+// GetSystemInfoJSON returns GetSystemInfo as a JSON string for structured logging,
+// with floating point fields rounded to 2 decimals
+func (tb Toolbox) GetSystemInfoJSON() (string, error) {
+	info, err := tb.GetSystemInfo()
+	if err != nil {
+		return "", err
+	}
+
+	roundFloats(reflect.ValueOf(&info).Elem(), jsonFloatDecimals)
+	data, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// roundFloats rounds every float field of v, including those of nested structs,
+// to the given number of decimals. v must be addressable.
+func roundFloats(v reflect.Value, decimals int) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		scale := math.Pow(10, float64(decimals))
+		v.SetFloat(math.Round(v.Float()*scale) / scale)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				roundFloats(v.Field(i), decimals)
+			}
+		}
+	}
+}
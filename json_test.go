@@ -0,0 +1,63 @@
+package toolbox
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoundFloats(t *testing.T) {
+	info := SystemInfo{
+		CPU: CPUInfo{UsagePercent: 12.3456, Load: LoadAverage{One: 0.005, Five: 1.999}},
+		Memory: MemoryInfo{
+			UsageBytes: 1234,
+			UsageMB:    1.23456789,
+		},
+		Method: "proc",
+	}
+
+	roundFloats(reflect.ValueOf(&info).Elem(), 2)
+
+	if info.CPU.UsagePercent != 12.35 {
+		t.Errorf("Expected 12.35, got %v", info.CPU.UsagePercent)
+	}
+	if info.CPU.Load.One != 0.01 || info.CPU.Load.Five != 2 {
+		t.Errorf("Expected nested load rounded to 0.01 and 2, got %v and %v", info.CPU.Load.One, info.CPU.Load.Five)
+	}
+	if info.Memory.UsageMB != 1.23 {
+		t.Errorf("Expected 1.23, got %v", info.Memory.UsageMB)
+	}
+	// Non-float fields are untouched
+	if info.Memory.UsageBytes != 1234 || info.Method != "proc" {
+		t.Errorf("Expected non-float fields unchanged, got %+v", info)
+	}
+}
+
+func TestGetSystemInfoJSON(t *testing.T) {
+	toolbox := Toolbox{}
+
+	data, err := toolbox.GetSystemInfoJSON()
+	if err != nil {
+		t.Logf("GetSystemInfoJSON failed (may not be available in test environment): %v", err)
+		return
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, data)
+	}
+	memory, ok := decoded["memory"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a memory object, got %s", data)
+	}
+	for _, field := range []string{"usage_mb", "limit_mb", "available_mb"} {
+		if _, ok := memory[field]; !ok {
+			t.Errorf("Expected %s in memory, got %s", field, data)
+		}
+	}
+	if !strings.Contains(data, `"method":`) {
+		t.Errorf("Expected method in %s", data)
+	}
+	t.Logf("System info JSON: %s", data)
+}
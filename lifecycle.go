@@ -0,0 +1,30 @@
+package toolbox
+
+// Close stops the resource watches this instance started and waits for their
+// goroutines to exit. Each VU has its own instance, so watches started by other
+// VUs keep running, and state shared by all VUs (cached connectivity reports,
+// idle HTTP connections, CPU sampling baselines, cached limits) is left alone;
+// see ResetModuleState. The instance remains usable afterwards. k6 has no
+// module teardown hook, so scripts should call this from teardown().
+func (tb *Toolbox) Close() {
+	stopResourceWatches(tb)
+}
+
+// ResetModuleState releases the state the module shares between all VUs: it
+// stops every resource watch, whichever VU started it, and drops cached
+// connectivity reports, idle connections of the connectivity HTTP client, CPU
+// sampling baselines, the smoothed CPU average and cached limits.
+// Configuration such as cache TTLs is kept.
+func ResetModuleState() {
+	stopResourceWatches(nil)
+	resetConnectivity()
+	resetAdaptiveCPU()
+	resetSmoothedCPU()
+	resetLimitCaches()
+}
+
+// ResetModuleState releases the state shared by all VUs, see ResetModuleState.
+// Only call it once no VU relies on that state, e.g. from teardown().
+func (Toolbox) ResetModuleState() {
+	ResetModuleState()
+}
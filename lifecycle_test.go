@@ -0,0 +1,125 @@
+package toolbox
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResetModuleStateReleasesResources(t *testing.T) {
+	host, port, _ := newTestServer(t, nil)
+	toolbox := Toolbox{}
+
+	SetConnectivityCacheTTL(time.Minute)
+	t.Cleanup(func() { SetConnectivityCacheTTL(0) })
+
+	baseline := runtime.NumGoroutine()
+
+	// Keep-alive connections to the server leave goroutines behind on both ends
+	toolbox.CheckConnectivity(host, port, 2)
+	_, _ = toolbox.GetCPUUsageAdaptive(1000)
+	_, _ = toolbox.GetSmoothedCPUUsage(0.5)
+
+	ResetModuleState()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > baseline {
+		t.Errorf("Expected goroutines to return to baseline %d after ResetModuleState, got %d", baseline, after)
+	}
+
+	// Cached reports are dropped
+	if report := toolbox.CheckConnectivity(host, port, 2); report.Cached {
+		t.Error("Expected a fresh report after ResetModuleState")
+	}
+
+	// Sampling state is reset
+	adaptiveCPU.Lock()
+	valid := adaptiveCPU.valid
+	adaptiveCPU.Unlock()
+	if valid {
+		t.Error("Expected adaptive CPU state to be reset after ResetModuleState")
+	}
+	smoothedCPU.Lock()
+	valid = smoothedCPU.valid
+	smoothedCPU.Unlock()
+	if valid {
+		t.Error("Expected smoothed CPU state to be reset after ResetModuleState")
+	}
+}
+
+func TestCloseStopsResourceWatches(t *testing.T) {
+	toolbox := Toolbox{}
+	baseline := runtime.NumGoroutine()
+
+	collect := func() (SystemInfo, error) { return SystemInfo{}, nil }
+	ids := make([]int, 5)
+	for i := range ids {
+		ids[i] = startResourceWatch(&toolbox, 5*time.Millisecond, 16, collect)
+	}
+	if running := runtime.NumGoroutine(); running < baseline+len(ids) {
+		t.Fatalf("Expected at least %d goroutines with watches running, got %d", baseline+len(ids), running)
+	}
+
+	toolbox.Close()
+
+	if after := runtime.NumGoroutine(); after > baseline {
+		t.Errorf("Expected goroutines to return to baseline %d after Close, got %d", baseline, after)
+	}
+	for _, id := range ids {
+		if _, err := toolbox.GetLatestSample(id); err == nil {
+			t.Errorf("Expected watch %d to be gone after Close", id)
+		}
+	}
+
+	// Watches can still be started afterwards
+	id := startResourceWatch(&toolbox, 5*time.Millisecond, 16, collect)
+	toolbox.StopResourceWatch(id)
+}
+
+func TestCloseLeavesOtherInstancesRunning(t *testing.T) {
+	// Each VU gets its own instance
+	closing, other := &Toolbox{}, &Toolbox{}
+	t.Cleanup(other.Close)
+
+	var collected atomic.Int64
+	collect := func() (SystemInfo, error) {
+		collected.Add(1)
+		return SystemInfo{}, nil
+	}
+	closingID := startResourceWatch(closing, 5*time.Millisecond, 16, collect)
+	otherID := startResourceWatch(other, 5*time.Millisecond, 16, collect)
+
+	_, _ = other.GetCPUUsageAdaptive(1000)
+	adaptiveCPU.Lock()
+	baselineKept := adaptiveCPU.valid
+	adaptiveCPU.Unlock()
+
+	closing.Close()
+
+	if _, err := closing.GetLatestSample(closingID); err == nil {
+		t.Error("Expected the closed instance's watch to be stopped")
+	}
+	before := collected.Load()
+	deadline := time.Now().Add(2 * time.Second)
+	for collected.Load() == before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if collected.Load() == before {
+		t.Error("Expected the other instance's watch to keep sampling")
+	}
+	if _, err := other.GetLatestSample(otherID); err != nil {
+		t.Errorf("Expected the other instance's watch to survive Close: %v", err)
+	}
+
+	// Shared sampling state is left for the other VUs
+	adaptiveCPU.Lock()
+	valid := adaptiveCPU.valid
+	adaptiveCPU.Unlock()
+	if baselineKept && !valid {
+		t.Error("Expected Close to keep the shared CPU sampling baseline")
+	}
+}
@@ -0,0 +1,117 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LoadAverage holds the 1, 5 and 15 minute system load averages
+type LoadAverage struct {
+	One     float64 `json:"one"`
+	Five    float64 `json:"five"`
+	Fifteen float64 `json:"fifteen"`
+}
+
+// String formats the load averages like `uptime` on Linux, e.g. "0.52, 0.58, 0.59"
+func (l LoadAverage) String() string {
+	return fmt.Sprintf("%.2f, %.2f, %.2f", l.One, l.Five, l.Fifteen)
+}
+
+// GetLoadAverage returns the system load averages, read from /proc/loadavg on
+// Linux and parsed from `uptime` on macOS
+func (Toolbox) GetLoadAverage() (LoadAverage, error) {
+	return readLoadAverage()
+}
+
+// NormalizedLoad holds the load averages alongside the same averages divided by
+// the effective core count, where 1.0 means every core is busy
+type NormalizedLoad struct {
+	Raw        LoadAverage `json:"raw"`
+	Normalized LoadAverage `json:"normalized"`
+	Cores      float64     `json:"cores"`
+}
+
+// GetNormalizedLoad returns the load averages divided by the CPU limit, so a
+// load of 4.0 reads as 1.0 on 4 cores and 0.25 on 16
+func (tb Toolbox) GetNormalizedLoad() (NormalizedLoad, error) {
+	load, err := readLoadAverage()
+	if err != nil {
+		return NormalizedLoad{}, err
+	}
+	cores, err := tb.GetCPULimit()
+	if err != nil {
+		return NormalizedLoad{}, err
+	}
+	return normalizeLoad(load, cores)
+}
+
+// normalizeLoad divides each load average by cores
+func normalizeLoad(load LoadAverage, cores float64) (NormalizedLoad, error) {
+	if cores <= 0 {
+		return NormalizedLoad{}, fmt.Errorf("%s: core count %v", ErrCPUNotFound, cores)
+	}
+	return NormalizedLoad{
+		Raw: load,
+		Normalized: LoadAverage{
+			One:     load.One / cores,
+			Five:    load.Five / cores,
+			Fifteen: load.Fifteen / cores,
+		},
+		Cores: cores,
+	}, nil
+}
+
+// readLoadAverage reads /proc/loadavg on Linux, or parses `uptime` on macOS
+func readLoadAverage() (LoadAverage, error) {
+	if isMacOS() {
+		loadAvg, err := getLoadAverage()
+		if err != nil {
+			return LoadAverage{}, err
+		}
+		return parseLoadAverageString(loadAvg)
+	}
+	if !isLinux() {
+		return LoadAverage{}, errors.New(ErrNotSupported)
+	}
+
+	content, err := readFile("/proc/loadavg")
+	if err != nil {
+		return LoadAverage{}, err
+	}
+	return parseLoadAverageString(content)
+}
+
+// parseLoadAverageString parses the first three load averages from /proc/loadavg
+// ("0.52 0.58 0.59 1/123 456"), Linux uptime ("0.52, 0.58, 0.59") or macOS uptime ("0.52 0.58 0.59")
+func parseLoadAverageString(loadAvg string) (LoadAverage, error) {
+	var values [3]float64
+
+	fields := strings.FieldsFunc(loadAvg, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	if len(fields) < 3 {
+		return LoadAverage{}, errors.New("invalid load average format")
+	}
+
+	for i := range values {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return LoadAverage{}, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+		values[i] = value
+	}
+	return LoadAverage{One: values[0], Five: values[1], Fifteen: values[2]}, nil
+}
+
+// applyLoadAverage sets both the structured and string load averages of info,
+// leaving them unset if the load average can't be read
+func applyLoadAverage(info *CPUInfo) {
+	load, err := readLoadAverage()
+	if err != nil {
+		return
+	}
+	info.Load = load
+	info.LoadAverage = load.String()
+}
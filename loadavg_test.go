@@ -0,0 +1,86 @@
+package toolbox
+
+import (
+	"testing"
+)
+
+func TestParseLoadAverageString(t *testing.T) {
+	tests := map[string]LoadAverage{
+		"0.52, 0.58, 0.59":         {0.52, 0.58, 0.59}, // Linux uptime
+		"1.25 1.50 1.75\n":         {1.25, 1.50, 1.75}, // macOS uptime
+		"0.10 0.20 0.30 1/234 567": {0.10, 0.20, 0.30}, // /proc/loadavg
+	}
+	for input, expected := range tests {
+		load, err := parseLoadAverageString(input)
+		if err != nil {
+			t.Errorf("parseLoadAverageString(%q) failed: %v", input, err)
+			continue
+		}
+		if load != expected {
+			t.Errorf("parseLoadAverageString(%q): expected %+v, got %+v", input, expected, load)
+		}
+	}
+
+	if _, err := parseLoadAverageString("0.52"); err == nil {
+		t.Error("Expected error for truncated load average")
+	}
+}
+
+func TestLoadAverageString(t *testing.T) {
+	load := LoadAverage{One: 0.5, Five: 1.25, Fifteen: 2}
+	if s := load.String(); s != "0.50, 1.25, 2.00" {
+		t.Errorf("Expected '0.50, 1.25, 2.00', got '%s'", s)
+	}
+}
+
+func TestGetLoadAverageMethod(t *testing.T) {
+	toolbox := Toolbox{}
+	load, err := toolbox.GetLoadAverage()
+	if err != nil {
+		t.Logf("GetLoadAverage failed (expected on unsupported platforms): %v", err)
+		return
+	}
+	if load.One < 0 || load.Five < 0 || load.Fifteen < 0 {
+		t.Errorf("Expected non-negative load averages, got %+v", load)
+	}
+
+	t.Logf("Load average: %+v", load)
+}
+
+func TestNormalizeLoad(t *testing.T) {
+	load := LoadAverage{One: 4, Five: 2, Fifteen: 1}
+
+	normalized, err := normalizeLoad(load, 4)
+	if err != nil {
+		t.Fatalf("normalizeLoad failed: %v", err)
+	}
+	if normalized.Raw != load || normalized.Cores != 4 {
+		t.Errorf("Expected raw load and core count to be kept, got %+v", normalized)
+	}
+	if expected := (LoadAverage{One: 1, Five: 0.5, Fifteen: 0.25}); normalized.Normalized != expected {
+		t.Errorf("Expected %+v, got %+v", expected, normalized.Normalized)
+	}
+
+	// Half a core from a CPU quota
+	if normalized, _ := normalizeLoad(load, 0.5); normalized.Normalized.One != 8 {
+		t.Errorf("Expected 8 on half a core, got %f", normalized.Normalized.One)
+	}
+
+	if _, err := normalizeLoad(load, 0); err == nil {
+		t.Error("Expected error for zero cores")
+	}
+}
+
+func TestGetNormalizedLoad(t *testing.T) {
+	normalized, err := Toolbox{}.GetNormalizedLoad()
+	if err != nil {
+		t.Logf("GetNormalizedLoad failed (expected on unsupported platforms): %v", err)
+		return
+	}
+	if normalized.Cores <= 0 {
+		t.Errorf("Expected a positive core count, got %f", normalized.Cores)
+	}
+	if normalized.Normalized.One < 0 {
+		t.Errorf("Expected non-negative normalized load, got %+v", normalized.Normalized)
+	}
+}
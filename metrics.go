@@ -0,0 +1,77 @@
+package toolbox
+
+import (
+	"errors"
+	"time"
+
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/metrics"
+)
+
+// toolboxMetrics are the k6 custom metrics written by RecordMetrics
+type toolboxMetrics struct {
+	cpuUsagePercent *metrics.Metric
+	cpuUsedCores    *metrics.Metric
+	memUsageBytes   *metrics.Metric
+	memLimitBytes   *metrics.Metric
+	memUsagePercent *metrics.Metric
+}
+
+// registerMetrics registers the custom metrics with the VU's registry. It
+// returns nil outside the init context, where no registry is available.
+func registerMetrics(vu modules.VU) *toolboxMetrics {
+	if vu == nil || vu.InitEnv() == nil {
+		return nil
+	}
+	registry := vu.InitEnv().Registry
+	return &toolboxMetrics{
+		cpuUsagePercent: registry.MustNewMetric("toolbox_cpu_usage_percent", metrics.Gauge),
+		cpuUsedCores:    registry.MustNewMetric("toolbox_cpu_used_cores", metrics.Gauge),
+		memUsageBytes:   registry.MustNewMetric("toolbox_mem_usage_bytes", metrics.Gauge, metrics.Data),
+		memLimitBytes:   registry.MustNewMetric("toolbox_mem_limit_bytes", metrics.Gauge, metrics.Data),
+		memUsagePercent: registry.MustNewMetric("toolbox_mem_usage_percent", metrics.Gauge),
+	}
+}
+
+// RecordMetrics collects system info and writes it to the toolbox_* custom
+// metrics, tagged with the VU's current tags, so the readings show up in the
+// end-of-test summary and outputs. It returns the collected info and must be
+// called from a VU, not the init context.
+func (tb Toolbox) RecordMetrics() (SystemInfo, error) {
+	if tb.vu == nil || tb.metrics == nil {
+		return SystemInfo{}, errors.New("metrics are only available when running in k6")
+	}
+	state := tb.vu.State()
+	if state == nil {
+		return SystemInfo{}, errors.New("metrics can't be recorded in the init context")
+	}
+
+	info, err := tb.GetSystemInfo()
+	if err != nil {
+		return info, err
+	}
+
+	tagsAndMeta := state.Tags.GetCurrentValues()
+	sample := func(metric *metrics.Metric, value float64, now time.Time) metrics.Sample {
+		return metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: metric, Tags: tagsAndMeta.Tags},
+			Time:       now,
+			Value:      value,
+			Metadata:   tagsAndMeta.Metadata,
+		}
+	}
+
+	now := time.Now()
+	metrics.PushIfNotDone(tb.vu.Context(), state.Samples, metrics.ConnectedSamples{
+		Samples: []metrics.Sample{
+			sample(tb.metrics.cpuUsagePercent, info.CPU.UsagePercent, now),
+			sample(tb.metrics.cpuUsedCores, info.CPU.UsedCores, now),
+			sample(tb.metrics.memUsageBytes, float64(info.Memory.UsageBytes), now),
+			sample(tb.metrics.memLimitBytes, float64(info.Memory.LimitBytes), now),
+			sample(tb.metrics.memUsagePercent, info.Memory.UsagePercent, now),
+		},
+		Tags: tagsAndMeta.Tags,
+		Time: now,
+	})
+	return info, nil
+}
@@ -0,0 +1,64 @@
+package toolbox
+
+import (
+	"testing"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+func TestRecordMetrics(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroup fixtures are Linux only")
+	}
+	root := t.TempDir()
+	writeCgroupFile(t, root, "cpu.max", "200000 100000\n")
+	writeCgroupFile(t, root, "cpu.stat", "usage_usec 1000000\n")
+	writeCgroupFile(t, root, "memory.max", "1073741824\n")
+	writeCgroupFile(t, root, "memory.current", "536870912\n")
+	Configure(Options{CgroupRoot: root})
+	t.Cleanup(func() { Configure(Options{}) })
+
+	runtime := modulestest.NewRuntime(t)
+	registry := runtime.VU.InitEnv().Registry
+	instance := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+
+	if _, err := instance.toolbox.RecordMetrics(); err == nil {
+		t.Error("Expected error in the init context")
+	}
+
+	samples := make(chan metrics.SampleContainer, 10)
+	runtime.MoveToVUContext(&lib.State{
+		Samples: samples,
+		Tags:    lib.NewVUStateTags(registry.RootTagSet().With("scenario", "default")),
+	})
+
+	info, err := instance.toolbox.RecordMetrics()
+	if err != nil {
+		t.Fatalf("RecordMetrics() error: %v", err)
+	}
+
+	values := make(map[string]float64)
+	for _, container := range metrics.GetBufferedSamples(samples) {
+		for _, sample := range container.GetSamples() {
+			values[sample.Metric.Name] = sample.Value
+			if scenario, _ := sample.Tags.Get("scenario"); scenario != "default" {
+				t.Errorf("Expected %s to carry the VU's tags", sample.Metric.Name)
+			}
+		}
+	}
+	if values["toolbox_mem_usage_bytes"] != 536870912 || values["toolbox_mem_limit_bytes"] != 1073741824 {
+		t.Errorf("Unexpected memory samples: %v", values)
+	}
+	if values["toolbox_mem_usage_percent"] != info.Memory.UsagePercent || values["toolbox_cpu_usage_percent"] != info.CPU.UsagePercent {
+		t.Errorf("Expected samples to match the returned info %+v, got %v", info, values)
+	}
+	if metric := registry.Get("toolbox_mem_usage_bytes"); metric == nil || metric.Contains != metrics.Data {
+		t.Error("Expected toolbox_mem_usage_bytes to be registered as data")
+	}
+
+	if _, err := (Toolbox{}).RecordMetrics(); err == nil {
+		t.Error("Expected error outside k6")
+	}
+}
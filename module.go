@@ -0,0 +1,37 @@
+package toolbox
+
+import (
+	"go.k6.io/k6/js/modules"
+)
+
+// RootModule is the global module object registered with k6. It creates a
+// ModuleInstance for every VU.
+type RootModule struct{}
+
+// ModuleInstance is the per-VU instance of the module
+type ModuleInstance struct {
+	vu      modules.VU
+	toolbox *Toolbox
+}
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance for each VU
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu, toolbox: &Toolbox{vu: vu, metrics: registerMetrics(vu)}}
+}
+
+// Exports implements the modules.Instance interface and returns the Toolbox
+// methods as the module's default export
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{Default: mi.toolbox}
+}
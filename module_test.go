@@ -0,0 +1,29 @@
+package toolbox
+
+import (
+	"testing"
+
+	"go.k6.io/k6/js/modulestest"
+)
+
+func TestModuleInstance(t *testing.T) {
+	runtime := modulestest.NewRuntime(t)
+	instance, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	if !ok {
+		t.Fatal("Expected a *ModuleInstance")
+	}
+	if instance.toolbox.vu != runtime.VU {
+		t.Error("Expected the instance's Toolbox to hold the VU")
+	}
+
+	if err := runtime.VU.Runtime().Set("toolbox", instance.Exports().Default); err != nil {
+		t.Fatalf("Failed to expose the module: %v", err)
+	}
+	value, err := runtime.VU.Runtime().RunString(`typeof toolbox.getSystemInfo + " " + toolbox.getMemoryUnit()`)
+	if err != nil {
+		t.Fatalf("Failed to call the module from JS: %v", err)
+	}
+	if got := value.String(); got != "function bytes" {
+		t.Errorf("Expected JS-facing method names to be unchanged, got %q", got)
+	}
+}
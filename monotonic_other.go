@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package toolbox
+
+import "errors"
+
+// monotonicSeconds is not supported on this platform
+func monotonicSeconds() (float64, error) {
+	return 0, errors.New(ErrNotSupported)
+}
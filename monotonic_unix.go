@@ -0,0 +1,18 @@
+//go:build linux || darwin
+
+package toolbox
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// monotonicSeconds reads CLOCK_MONOTONIC, which counts from boot
+func monotonicSeconds() (float64, error) {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		return 0, fmt.Errorf("clock_gettime failed: %w", err)
+	}
+	return float64(ts.Nano()) / 1e9, nil
+}
@@ -0,0 +1,178 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NetworkInterface describes a network interface and its addresses
+type NetworkInterface struct {
+	Name         string   `json:"name"`
+	Index        int      `json:"index"`
+	MTU          int      `json:"mtu" js:"mtu"`
+	Flags        []string `json:"flags"`         // e.g. ["up", "broadcast", "running", "multicast"]
+	HardwareAddr string   `json:"hardware_addr"` // MAC address, empty for loopback and tunnels
+	Addrs        []string `json:"addrs"`         // Addresses in CIDR notation, e.g. "10.0.0.5/24"
+}
+
+// NetIOStat holds cumulative traffic counters of one network interface
+type NetIOStat struct {
+	RxBytes   int64 `json:"rx_bytes"`
+	RxPackets int64 `json:"rx_packets"`
+	RxErrors  int64 `json:"rx_errors"`
+	TxBytes   int64 `json:"tx_bytes"`
+	TxPackets int64 `json:"tx_packets"`
+	TxErrors  int64 `json:"tx_errors"`
+}
+
+// GetNetworkInterfaces returns the network interfaces visible to the process
+// with their addresses
+func (Toolbox) GetNetworkInterfaces() ([]NetworkInterface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list network interfaces: %w", err)
+	}
+
+	result := make([]NetworkInterface, 0, len(ifaces))
+	for _, iface := range ifaces {
+		info := NetworkInterface{
+			Name:         iface.Name,
+			Index:        iface.Index,
+			MTU:          iface.MTU,
+			Flags:        interfaceFlags(iface.Flags),
+			HardwareAddr: iface.HardwareAddr.String(),
+			Addrs:        []string{},
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list addresses of %s: %w", iface.Name, err)
+		}
+		for _, addr := range addrs {
+			info.Addrs = append(info.Addrs, addr.String())
+		}
+
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// interfaceFlags splits net.Flags into individual flag names
+func interfaceFlags(flags net.Flags) []string {
+	if flags == 0 {
+		return []string{}
+	}
+	return strings.Split(flags.String(), "|")
+}
+
+// GetNetworkIOStats returns traffic counters per interface from /proc/net/dev on
+// Linux or `netstat -ib` on macOS. The counters are cumulative; sample twice to
+// compute throughput.
+func (Toolbox) GetNetworkIOStats() (map[string]NetIOStat, error) {
+	if isMacOS() {
+		output, err := commandOutput("netstat", "-ib")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrCommandFailed, err)
+		}
+		return parseNetstatIB(string(output))
+	}
+	if !isLinux() {
+		return nil, errors.New(ErrNotSupported)
+	}
+
+	content, err := readFile("/proc/net/dev")
+	if err != nil {
+		return nil, err
+	}
+	return parseProcNetDev(content)
+}
+
+// parseProcNetDev parses /proc/net/dev, whose interface lines look like
+// "  eth0: rx_bytes rx_packets rx_errs drop fifo frame compressed multicast tx_bytes tx_packets tx_errs ..."
+func parseProcNetDev(content string) (map[string]NetIOStat, error) {
+	stats := make(map[string]NetIOStat)
+	for _, line := range strings.Split(content, "\n") {
+		// The two header lines have no colon, but contain "|"
+		name, counters, ok := strings.Cut(line, ":")
+		if !ok || strings.Contains(line, "|") {
+			continue
+		}
+		name = strings.TrimSpace(name)
+
+		fields := strings.Fields(counters)
+		if len(fields) < 16 {
+			return nil, netIOParseError(stats, name, fmt.Errorf("expected 16 counters, got %d", len(fields)))
+		}
+		values, err := parseInt64Fields(fields, 0, 1, 2, 8, 9, 10)
+		if err != nil {
+			return nil, netIOParseError(stats, name, err)
+		}
+		stats[name] = NetIOStat{
+			RxBytes: values[0], RxPackets: values[1], RxErrors: values[2],
+			TxBytes: values[3], TxPackets: values[4], TxErrors: values[5],
+		}
+	}
+
+	if len(stats) == 0 {
+		return nil, errors.New("no interfaces found in /proc/net/dev")
+	}
+	return stats, nil
+}
+
+// parseNetstatIB parses the link-level rows of macOS `netstat -ib`:
+// "Name Mtu Network Address Ipkts Ierrs Ibytes Opkts Oerrs Obytes Coll".
+// Rows for the interface's IP addresses repeat the same counters and are skipped.
+func parseNetstatIB(output string) (map[string]NetIOStat, error) {
+	stats := make(map[string]NetIOStat)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 10 || !strings.HasPrefix(fields[2], "<Link#") {
+			continue
+		}
+		name := strings.TrimSuffix(fields[0], "*")
+
+		// The address column is empty for some interfaces, so count from the end
+		n := len(fields)
+		values, err := parseInt64Fields(fields, n-7, n-5, n-6, n-4, n-2, n-3)
+		if err != nil {
+			return nil, netIOParseError(stats, name, err)
+		}
+		stats[name] = NetIOStat{
+			RxPackets: values[0], RxBytes: values[1], RxErrors: values[2],
+			TxPackets: values[3], TxBytes: values[4], TxErrors: values[5],
+		}
+	}
+
+	if len(stats) == 0 {
+		return nil, errors.New("no interfaces found in netstat output")
+	}
+	return stats, nil
+}
+
+// parseInt64Fields parses the fields at the given indexes
+func parseInt64Fields(fields []string, indexes ...int) ([]int64, error) {
+	values := make([]int64, len(indexes))
+	for i, index := range indexes {
+		value, err := strconv.ParseInt(fields[index], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// netIOParseError reports which interface failed to parse and which were parsed
+// before it, to help debug unexpected formats
+func netIOParseError(parsed map[string]NetIOStat, name string, err error) error {
+	names := make([]string, 0, len(parsed))
+	for parsedName := range parsed {
+		names = append(names, parsedName)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("%s: interface %s: %w (parsed interfaces: [%s])", ErrParsingValue, name, err, strings.Join(names, ", "))
+}
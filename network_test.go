@@ -0,0 +1,105 @@
+package toolbox
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestInterfaceFlags(t *testing.T) {
+	flags := interfaceFlags(net.FlagUp | net.FlagLoopback)
+	if len(flags) != 2 || flags[0] != "up" || flags[1] != "loopback" {
+		t.Errorf("Expected [up loopback], got %v", flags)
+	}
+
+	if flags := interfaceFlags(0); len(flags) != 0 {
+		t.Errorf("Expected no flags, got %v", flags)
+	}
+}
+
+func TestGetNetworkInterfaces(t *testing.T) {
+	toolbox := Toolbox{}
+	ifaces, err := toolbox.GetNetworkInterfaces()
+	if err != nil {
+		t.Fatalf("GetNetworkInterfaces failed: %v", err)
+	}
+
+	// Every environment has a loopback interface
+	found := false
+	for _, iface := range ifaces {
+		for _, flag := range iface.Flags {
+			if flag == "loopback" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected a loopback interface, got %+v", ifaces)
+	}
+
+	t.Logf("Network interfaces: %+v", ifaces)
+}
+
+func TestParseProcNetDev(t *testing.T) {
+	content := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo: 93398498   22416    0    0    0     0          0         0 93398498   22416    0    0    0     0       0          0
+  eth0: 32502960    2191    3    0    0     0          0         0   246045    2612    1    0    0     0       0          0
+`
+	stats, err := parseProcNetDev(content)
+	if err != nil {
+		t.Fatalf("parseProcNetDev failed: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 interfaces, got %v", stats)
+	}
+	expected := NetIOStat{RxBytes: 32502960, RxPackets: 2191, RxErrors: 3, TxBytes: 246045, TxPackets: 2612, TxErrors: 1}
+	if stats["eth0"] != expected {
+		t.Errorf("Expected %+v for eth0, got %+v", expected, stats["eth0"])
+	}
+
+	// Errors name the failing interface and those parsed before it
+	_, err = parseProcNetDev(content + "  eth1: 1 2 3\n")
+	if err == nil || !strings.Contains(err.Error(), "eth1") || !strings.Contains(err.Error(), "[eth0, lo]") {
+		t.Errorf("Expected error naming eth1 and the parsed interfaces, got %v", err)
+	}
+	if _, err := parseProcNetDev(""); err == nil {
+		t.Error("Expected error for empty content")
+	}
+}
+
+func TestParseNetstatIB(t *testing.T) {
+	output := `Name       Mtu   Network       Address            Ipkts Ierrs     Ibytes    Opkts Oerrs     Obytes  Coll
+lo0        16384 <Link#1>                        120000     0   98000000   120000     0   98000000     0
+lo0        16384 127           localhost         120000     -   98000000   120000     -   98000000     -
+en0        1500  <Link#4>    a4:83:e7:12:34:56  5000000    2 6000000000  3000000     1  400000000     0
+en0        1500  192.168.1     192.168.1.10      5000000    - 6000000000  3000000     -  400000000     -
+`
+	stats, err := parseNetstatIB(output)
+	if err != nil {
+		t.Fatalf("parseNetstatIB failed: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 interfaces, got %v", stats)
+	}
+	expected := NetIOStat{RxBytes: 6000000000, RxPackets: 5000000, RxErrors: 2, TxBytes: 400000000, TxPackets: 3000000, TxErrors: 1}
+	if stats["en0"] != expected {
+		t.Errorf("Expected %+v for en0, got %+v", expected, stats["en0"])
+	}
+	if stats["lo0"].RxBytes != 98000000 {
+		t.Errorf("Expected lo0 without an address column to parse, got %+v", stats["lo0"])
+	}
+}
+
+func TestGetNetworkIOStats(t *testing.T) {
+	toolbox := Toolbox{}
+	stats, err := toolbox.GetNetworkIOStats()
+	if err != nil {
+		t.Logf("GetNetworkIOStats failed (expected in test environment): %v", err)
+		return
+	}
+	if len(stats) == 0 {
+		t.Error("Expected at least one interface")
+	}
+	t.Logf("Network IO: %+v", stats)
+}
@@ -0,0 +1,128 @@
+package toolbox
+
+import (
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultCgroupRoot is where cgroups are mounted unless configured otherwise
+const defaultCgroupRoot = "/sys/fs/cgroup"
+
+// defaultCommandTimeout bounds system commands unless configured otherwise
+const defaultCommandTimeout = 5 * time.Second
+
+// defaultLimitCacheTTL is how long CPU and memory limits are cached unless configured otherwise
+const defaultLimitCacheTTL = time.Second
+
+// Options configures module-wide behavior. Zero values keep the defaults.
+type Options struct {
+	CgroupRoot            string  `json:"cgroup_root"`             // Where cgroups are mounted, default /sys/fs/cgroup
+	CommandTimeoutSeconds float64 `json:"command_timeout_seconds"` // Limit on system commands such as top and free, default 5
+	// How long the CPU limit, memory limit and core count are cached, default
+	// 1000. Negative disables caching. Usage is never cached.
+	LimitCacheTTLMs int `json:"limit_cache_ttl_ms"`
+	// Timeout and port of connectivity checks whose arguments leave them unset,
+	// default 5 and "80". Arguments passed to a call take precedence.
+	DefaultTimeoutSeconds int    `json:"default_timeout_seconds"`
+	DefaultPort           string `json:"default_port"`
+	// Binaries RunCommand may execute, matched exactly against its name
+	// argument. Empty denies every command.
+	CommandAllowlist []string `json:"command_allowlist"`
+	// Ordered collection strategies tried by the CPU, memory and system info
+	// getters: "cgroup_v2", "cgroup_v1", "command" and "proc". Empty keeps the
+	// built-in chain; leaving a strategy out disables it.
+	CollectionStrategies []string `json:"collection_strategies"`
+}
+
+// Module options set by Configure
+var (
+	optionsMu sync.RWMutex
+	options   Options
+)
+
+// Configure replaces the module options. Fields left at their zero value use the
+// defaults. Cached limits are discarded since they may come from another cgroup root.
+func Configure(opts Options) {
+	optionsMu.Lock()
+	options = opts
+	optionsMu.Unlock()
+
+	resetLimitCaches()
+}
+
+// Configure exposes Configure to k6 JavaScript
+func (Toolbox) Configure(opts Options) {
+	Configure(opts)
+}
+
+// currentOptions returns a copy of the module options
+func currentOptions() Options {
+	optionsMu.RLock()
+	defer optionsMu.RUnlock()
+
+	return options
+}
+
+// cgroupRoot returns the configured cgroup mount point
+func cgroupRoot() string {
+	if root := currentOptions().CgroupRoot; root != "" {
+		return root
+	}
+	return defaultCgroupRoot
+}
+
+// cgroupFile returns the path of rel in the process's own cgroup under the
+// configured root, or directly under the root when the nested file is missing
+func cgroupFile(rel string) string {
+	root := cgroupRoot()
+	if nested, ok := nestedCgroupFile(root, rel, selfCgroupPaths()); ok {
+		return nested
+	}
+	return filepath.Join(root, rel)
+}
+
+// commandTimeout returns the configured limit on system commands
+func commandTimeout() time.Duration {
+	seconds := currentOptions().CommandTimeoutSeconds
+	if seconds <= 0 {
+		return defaultCommandTimeout
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// commandAllowed reports whether RunCommand may execute name
+func commandAllowed(name string) bool {
+	return slices.Contains(currentOptions().CommandAllowlist, name)
+}
+
+// limitCacheTTL returns how long limits are cached, 0 if caching is disabled
+func limitCacheTTL() time.Duration {
+	ms := currentOptions().LimitCacheTTLMs
+	if ms < 0 {
+		return 0
+	}
+	if ms == 0 {
+		return defaultLimitCacheTTL
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// defaultConnectivityTimeoutSeconds returns the configured timeout of
+// connectivity checks that don't set one
+func defaultConnectivityTimeoutSeconds() int {
+	if seconds := currentOptions().DefaultTimeoutSeconds; seconds > 0 {
+		return seconds
+	}
+	return 5
+}
+
+// defaultConnectivityPort returns the configured port of connectivity checks
+// that don't set one
+func defaultConnectivityPort() string {
+	if port := currentOptions().DefaultPort; port != "" {
+		return port
+	}
+	return "80"
+}
@@ -0,0 +1,74 @@
+package toolbox
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCgroupFile(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+
+	if path := cgroupFile("memory.max"); path != "/sys/fs/cgroup/memory.max" {
+		t.Errorf("Expected default root, got '%s'", path)
+	}
+
+	Configure(Options{CgroupRoot: "/host/cgroup"})
+	if path := cgroupFile("memory/memory.limit_in_bytes"); path != "/host/cgroup/memory/memory.limit_in_bytes" {
+		t.Errorf("Expected configured root, got '%s'", path)
+	}
+}
+
+func TestConfigureCgroupRoot(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+
+	root := t.TempDir()
+	writeCgroupFile(t, root, "memory.max", "536870912\n")
+	writeCgroupFile(t, root, "memory.current", "134217728\n")
+	writeCgroupFile(t, root, "cpu.max", "150000 100000\n")
+
+	toolbox := Toolbox{}
+	toolbox.Configure(Options{CgroupRoot: root})
+
+	memLimit, unlimited, err := readCgroupV2MemoryLimit()
+	if err != nil || unlimited || memLimit != 536870912 {
+		t.Errorf("Expected memory limit 536870912 from fixture, got %d (%v)", memLimit, err)
+	}
+	usage, err := readCgroupV2MemoryUsage()
+	if err != nil || usage != 134217728 {
+		t.Errorf("Expected memory usage 134217728 from fixture, got %d (%v)", usage, err)
+	}
+	cpuLimit, err := readCgroupV2CPULimit()
+	if err != nil || cpuLimit != 1.5 {
+		t.Errorf("Expected CPU limit 1.5 from fixture, got %f (%v)", cpuLimit, err)
+	}
+}
+
+func TestConfigureConnectivityDefaults(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	Configure(Options{DefaultTimeoutSeconds: 12, DefaultPort: port})
+	report := CheckConnectivity("127.0.0.1", "", 0)
+	if report.Port != port || report.TimeoutSeconds != 12 {
+		t.Errorf("Expected the configured port %s and timeout 12, got %s and %d", port, report.Port, report.TimeoutSeconds)
+	}
+	if report.HTTPStatusCode != http.StatusOK {
+		t.Errorf("Expected to reach the server on the configured port, got %+v", report)
+	}
+
+	// Arguments take precedence over the configured defaults
+	report = CheckConnectivity("127.0.0.1", "1", 3)
+	if report.Port != "1" || report.TimeoutSeconds != 3 {
+		t.Errorf("Expected per-call port 1 and timeout 3, got %s and %d", report.Port, report.TimeoutSeconds)
+	}
+
+	Configure(Options{})
+	if timeout, port := defaultConnectivityTimeoutSeconds(), defaultConnectivityPort(); timeout != 5 || port != "80" {
+		t.Errorf("Expected built-in defaults 5 and 80, got %d and %s", timeout, port)
+	}
+}
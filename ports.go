@@ -0,0 +1,175 @@
+package toolbox
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tcpStateListen is the /proc/net/tcp "st" value of a socket in LISTEN state
+const tcpStateListen = "0A"
+
+// ListeningPort describes a TCP socket in LISTEN state
+type ListeningPort struct {
+	Protocol string `json:"protocol"` // "tcp" or "tcp6"
+	Address  string `json:"address"`  // Local address the socket is bound to
+	Port     int    `json:"port"`
+	Inode    uint64 `json:"inode"`
+	PID      int    `json:"pid" js:"pid"` // Owning process, 0 if it could not be resolved
+	Process  string `json:"process"`      // Command name of the owning process, if resolved
+}
+
+// GetListeningPorts returns the TCP sockets in LISTEN state visible in this network
+// namespace, with the owning process where it can be resolved (Linux only).
+// Owners are only resolvable for processes whose /proc/<pid>/fd is readable.
+func (Toolbox) GetListeningPorts() ([]ListeningPort, error) {
+	return getListeningPorts()
+}
+
+// getListeningPorts reads /proc/net/tcp and /proc/net/tcp6 and resolves socket owners
+func getListeningPorts() ([]ListeningPort, error) {
+	if !isLinux() {
+		return nil, errors.New(ErrNotSupported)
+	}
+
+	ports := []ListeningPort{}
+	found := false
+	for _, protocol := range []string{"tcp", "tcp6"} {
+		content, err := readFile(filepath.Join("/proc/net", protocol))
+		if err != nil {
+			// tcp6 is missing when IPv6 is disabled
+			continue
+		}
+		found = true
+
+		parsed, err := parseProcNetTCPListeners(content, protocol)
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, parsed...)
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: /proc/net/tcp", ErrReadingFile)
+	}
+
+	owners := socketOwners()
+	for i := range ports {
+		if pid, ok := owners[ports[i].Inode]; ok {
+			ports[i].PID = pid
+			if comm, err := readFile(filepath.Join("/proc", strconv.Itoa(pid), "comm")); err == nil {
+				ports[i].Process = strings.TrimSpace(comm)
+			}
+		}
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Protocol < ports[j].Protocol
+	})
+	return ports, nil
+}
+
+// parseProcNetTCPListeners parses the LISTEN sockets of a /proc/net/tcp or tcp6 table
+func parseProcNetTCPListeners(content, protocol string) ([]ListeningPort, error) {
+	var ports []ListeningPort
+
+	for _, line := range strings.Split(content, "\n") {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[0] == "sl" {
+			continue
+		}
+		if fields[3] != tcpStateListen {
+			continue
+		}
+
+		address, port, err := parseProcNetAddress(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+
+		ports = append(ports, ListeningPort{
+			Protocol: protocol,
+			Address:  address,
+			Port:     port,
+			Inode:    inode,
+		})
+	}
+
+	return ports, nil
+}
+
+// parseProcNetAddress decodes a hex "ADDRESS:PORT" pair from /proc/net/tcp*. The
+// address is stored as 32-bit words in host (little-endian) byte order.
+func parseProcNetAddress(value string) (string, int, error) {
+	hexAddr, hexPort, found := strings.Cut(value, ":")
+	if !found {
+		return "", 0, fmt.Errorf("invalid socket address: %q", value)
+	}
+
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+
+	raw, err := hex.DecodeString(hexAddr)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", 0, fmt.Errorf("invalid socket address: %q", value)
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(raw[i:]))
+	}
+
+	return ip.String(), int(port), nil
+}
+
+// socketOwners maps socket inodes to the PID holding them by scanning /proc/*/fd.
+// Processes whose descriptors can't be read are skipped.
+func socketOwners() map[uint64]int {
+	owners := make(map[uint64]int)
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return owners
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join("/proc", entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]"), 10, 64)
+			if err != nil {
+				continue
+			}
+			if _, ok := owners[inode]; !ok {
+				owners[inode] = pid
+			}
+		}
+	}
+
+	return owners
+}
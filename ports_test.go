@@ -0,0 +1,87 @@
+package toolbox
+
+import (
+	"net"
+	"os"
+	"testing"
+)
+
+const procNetTCPFixture = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 12345 1 0000000000000000 100 0 0 10 0
+   1: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 23456 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:1F90 0100007F:C350 01 00000000:00000000 00:00000000 00000000  1000        0 34567 1 0000000000000000 20 4 30 10 -1
+`
+
+func TestParseProcNetTCPListeners(t *testing.T) {
+	ports, err := parseProcNetTCPListeners(procNetTCPFixture, "tcp")
+	if err != nil {
+		t.Fatalf("parseProcNetTCPListeners failed: %v", err)
+	}
+
+	if len(ports) != 2 {
+		t.Fatalf("Expected 2 listening sockets, got %d", len(ports))
+	}
+	if ports[0].Address != "127.0.0.1" || ports[0].Port != 8080 || ports[0].Inode != 12345 {
+		t.Errorf("Unexpected first listener: %+v", ports[0])
+	}
+	if ports[1].Address != "0.0.0.0" || ports[1].Port != 22 {
+		t.Errorf("Unexpected second listener: %+v", ports[1])
+	}
+}
+
+func TestParseProcNetAddress(t *testing.T) {
+	tests := []struct {
+		value   string
+		address string
+		port    int
+	}{
+		{"0100007F:1F90", "127.0.0.1", 8080},
+		{"00000000000000000000000001000000:0050", "::1", 80},
+		{"00000000000000000000000000000000:01BB", "::", 443},
+	}
+
+	for _, tt := range tests {
+		address, port, err := parseProcNetAddress(tt.value)
+		if err != nil {
+			t.Errorf("parseProcNetAddress(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if address != tt.address || port != tt.port {
+			t.Errorf("parseProcNetAddress(%q): expected %s:%d, got %s:%d", tt.value, tt.address, tt.port, address, port)
+		}
+	}
+
+	if _, _, err := parseProcNetAddress("0100007F"); err == nil {
+		t.Error("Expected error for missing port")
+	}
+	if _, _, err := parseProcNetAddress("0100:1F90"); err == nil {
+		t.Error("Expected error for short address")
+	}
+}
+
+func TestGetListeningPorts(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to open listener: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	toolbox := Toolbox{}
+	ports, err := toolbox.GetListeningPorts()
+	if err != nil {
+		t.Logf("GetListeningPorts failed (expected outside Linux): %v", err)
+		return
+	}
+
+	for _, p := range ports {
+		if p.Port == port {
+			if p.PID != os.Getpid() {
+				t.Errorf("Expected listener owned by pid %d, got %d", os.Getpid(), p.PID)
+			}
+			t.Logf("Found test listener: %+v", p)
+			return
+		}
+	}
+	t.Errorf("Expected port %d among listening ports: %+v", port, ports)
+}
@@ -0,0 +1,116 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// PressureInfo holds Pressure Stall Information for CPU, memory and IO
+type PressureInfo struct {
+	CPU    PressureResource `json:"cpu"`
+	Memory PressureResource `json:"memory"`
+	IO     PressureResource `json:"io" js:"io"`
+}
+
+// PressureResource holds the "some" and "full" pressure lines of one resource.
+// some: share of time at least one task was stalled on the resource.
+// full: share of time all non-idle tasks were stalled at once.
+type PressureResource struct {
+	Some PressureStats `json:"some"`
+	Full PressureStats `json:"full"` // Zero for CPU on kernels that don't report it
+}
+
+// PressureStats is one line of a /proc/pressure file
+type PressureStats struct {
+	Avg10  float64 `json:"avg10"`  // Percent of time stalled over the last 10 seconds
+	Avg60  float64 `json:"avg60"`  // Percent of time stalled over the last 60 seconds
+	Avg300 float64 `json:"avg300"` // Percent of time stalled over the last 300 seconds
+	Total  int64   `json:"total"`  // Total stall time in microseconds
+}
+
+// GetPressureInfo returns Pressure Stall Information from /proc/pressure.
+// Requires Linux 4.20+ with PSI enabled; otherwise a "not supported" error is returned.
+func (Toolbox) GetPressureInfo() (PressureInfo, error) {
+	var info PressureInfo
+
+	if !isLinux() {
+		return info, errors.New(ErrNotSupported)
+	}
+
+	resources := []struct {
+		name   string
+		target *PressureResource
+	}{
+		{"cpu", &info.CPU},
+		{"memory", &info.Memory},
+		{"io", &info.IO},
+	}
+	for _, resource := range resources {
+		content, err := readFile("/proc/pressure/" + resource.name)
+		if errors.Is(err, fs.ErrNotExist) {
+			return info, fmt.Errorf("%s: /proc/pressure/%s not found", ErrNotSupported, resource.name)
+		}
+		if err != nil {
+			return info, err
+		}
+		if *resource.target, err = parsePressure(content); err != nil {
+			return info, err
+		}
+	}
+
+	return info, nil
+}
+
+// parsePressure parses a /proc/pressure file with lines like
+// "some avg10=0.12 avg60=0.05 avg300=0.01 total=123456"
+func parsePressure(content string) (PressureResource, error) {
+	var resource PressureResource
+	found := false
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var stats *PressureStats
+		switch fields[0] {
+		case "some":
+			stats = &resource.Some
+		case "full":
+			stats = &resource.Full
+		default:
+			continue
+		}
+		found = true
+
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			var err error
+			switch key {
+			case "avg10":
+				stats.Avg10, err = strconv.ParseFloat(value, 64)
+			case "avg60":
+				stats.Avg60, err = strconv.ParseFloat(value, 64)
+			case "avg300":
+				stats.Avg300, err = strconv.ParseFloat(value, 64)
+			case "total":
+				stats.Total, err = strconv.ParseInt(value, 10, 64)
+			}
+			if err != nil {
+				return resource, fmt.Errorf("%s: %w", ErrParsingValue, err)
+			}
+		}
+	}
+
+	if !found {
+		return resource, errors.New("invalid pressure file format")
+	}
+	return resource, nil
+}
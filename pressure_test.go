@@ -0,0 +1,68 @@
+package toolbox
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePressure(t *testing.T) {
+	content := `some avg10=1.50 avg60=0.75 avg300=0.10 total=123456
+full avg10=0.50 avg60=0.25 avg300=0.00 total=4567
+`
+	resource, err := parsePressure(content)
+	if err != nil {
+		t.Fatalf("parsePressure() error: %v", err)
+	}
+
+	expected := PressureResource{
+		Some: PressureStats{Avg10: 1.5, Avg60: 0.75, Avg300: 0.1, Total: 123456},
+		Full: PressureStats{Avg10: 0.5, Avg60: 0.25, Avg300: 0, Total: 4567},
+	}
+	if resource != expected {
+		t.Errorf("Expected %+v, got %+v", expected, resource)
+	}
+}
+
+func TestParsePressureSomeOnly(t *testing.T) {
+	// CPU pressure has no "full" line before Linux 5.13
+	resource, err := parsePressure("some avg10=2.00 avg60=1.00 avg300=0.50 total=99\n")
+	if err != nil {
+		t.Fatalf("parsePressure() error: %v", err)
+	}
+	if resource.Some.Avg10 != 2 || resource.Full != (PressureStats{}) {
+		t.Errorf("Unexpected result: %+v", resource)
+	}
+}
+
+func TestParsePressureInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"garbage\n",
+		"some avg10=abc avg60=0.00 avg300=0.00 total=0\n",
+		"some avg10=0.00 avg60=0.00 avg300=0.00 total=-x\n",
+	}
+	for _, content := range tests {
+		if _, err := parsePressure(content); err == nil {
+			t.Errorf("Expected error for %q", content)
+		}
+	}
+}
+
+func TestGetPressureInfo(t *testing.T) {
+	toolbox := Toolbox{}
+
+	info, err := toolbox.GetPressureInfo()
+	if err != nil {
+		if strings.Contains(err.Error(), ErrNotSupported) {
+			t.Skipf("PSI not available: %v", err)
+		}
+		t.Fatalf("GetPressureInfo() error: %v", err)
+	}
+
+	for name, resource := range map[string]PressureResource{"cpu": info.CPU, "memory": info.Memory, "io": info.IO} {
+		if resource.Some.Avg10 < 0 || resource.Some.Avg10 > 100 {
+			t.Errorf("%s: expected some avg10 in [0, 100], got %v", name, resource.Some.Avg10)
+		}
+	}
+	t.Logf("Pressure info: %+v", info)
+}
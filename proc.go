@@ -0,0 +1,132 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// /proc-only implementations, used on Linux when cgroup files are not
+// available, before system commands (which scratch or distroless images lack)
+
+// getCPUInfoHost gets CPU info without cgroup files: /proc first on Linux, then
+// system commands
+func getCPUInfoHost() (CPUInfo, error) {
+	if isLinux() {
+		if info, err := getCPUInfoProc(); err == nil {
+			return info, nil
+		}
+	}
+	return getCPUInfoCommand()
+}
+
+// getMemoryInfoHost gets memory info without cgroup files: /proc/meminfo first on
+// Linux, since it holds everything free reads without a subprocess, then commands
+func getMemoryInfoHost() (MemoryInfo, error) {
+	if isLinux() {
+		if info, err := getMemoryInfoProc(); err == nil {
+			return info, nil
+		}
+	}
+	return getMemoryInfoCommand()
+}
+
+// getCPUInfoProc gets CPU info from /proc/cpuinfo, /proc/stat and /proc/loadavg
+func getCPUInfoProc() (CPUInfo, error) {
+	var info CPUInfo
+
+	if !isLinux() {
+		return info, errors.New(ErrNotSupported)
+	}
+
+	cores, err := getNumCPUs()
+	if err != nil {
+		return info, err
+	}
+	info.LimitCores = cores
+
+	info.UsagePercent, info.StealPercent, err = sampleProcStatCPUPercent(defaultCPUSampleInterval)
+	if err != nil {
+		return info, err
+	}
+	info.UsedCores = (info.UsagePercent / 100.0) * cores
+	info.Available = cores - info.UsedCores
+
+	applyLoadAverage(&info)
+
+	return info, nil
+}
+
+// getMemoryInfoProc gets memory info from /proc/meminfo
+func getMemoryInfoProc() (MemoryInfo, error) {
+	if !isLinux() {
+		return MemoryInfo{}, errors.New(ErrNotSupported)
+	}
+
+	content, err := readFile("/proc/meminfo")
+	if err != nil {
+		return MemoryInfo{}, err
+	}
+
+	info, err := parseMemInfo(content)
+	if err != nil {
+		return info, err
+	}
+	applyMemoryUnit(&info, defaultMemoryUnit)
+
+	return info, nil
+}
+
+// parseMemInfo parses /proc/meminfo into MemoryInfo. Used memory is total minus
+// available, matching modern `free`. Kernels without MemAvailable (< 3.14) fall
+// back to free + buffers + cached.
+func parseMemInfo(content string) (MemoryInfo, error) {
+	var info MemoryInfo
+
+	values := make(map[string]int64)
+	for _, line := range strings.Split(content, "\n") {
+		key, rest, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return info, fmt.Errorf("%s: %s: %w", ErrParsingValue, key, err)
+		}
+		// Values are in kB unless they have no unit (e.g. HugePages_Total)
+		if len(fields) > 1 && fields[1] == "kB" {
+			value *= 1024
+		}
+		values[key] = value
+	}
+
+	total, ok := values["MemTotal"]
+	if !ok || total <= 0 {
+		return info, errors.New("MemTotal not found in /proc/meminfo")
+	}
+
+	info.LimitBytes = total
+	info.LimitSource = "system"
+	info.FreeBytes = values["MemFree"]
+	info.BufferBytes = values["Buffers"]
+	info.CachedBytes = values["Cached"]
+	if available, ok := values["MemAvailable"]; ok {
+		info.AvailableBytes = available
+	} else {
+		info.AvailableBytes = info.FreeBytes + info.BufferBytes + info.CachedBytes
+	}
+	info.UsageBytes = total - info.AvailableBytes
+
+	info.UsagePercent = (float64(info.UsageBytes) / float64(total)) * 100
+	info.UsageMB = float64(info.UsageBytes) / (1024 * 1024)
+	info.LimitMB = float64(total) / (1024 * 1024)
+	info.AvailableMB = float64(info.AvailableBytes) / (1024 * 1024)
+	applySwap(&info, values["SwapTotal"]-values["SwapFree"], values["SwapTotal"])
+
+	return info, nil
+}
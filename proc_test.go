@@ -0,0 +1,145 @@
+package toolbox
+
+import (
+	"testing"
+)
+
+const memInfoFixture = `MemTotal:        8048000 kB
+MemFree:         1024000 kB
+MemAvailable:    4096000 kB
+Buffers:          256000 kB
+Cached:          2048000 kB
+SwapCached:            0 kB
+Active:          3145728 kB
+Inactive:        2097152 kB
+Active(anon):    1835008 kB
+Inactive(anon):    65536 kB
+Active(file):    1310720 kB
+Inactive(file):  2031616 kB
+Unevictable:           0 kB
+Mlocked:               0 kB
+SwapTotal:       2048000 kB
+SwapFree:        1536000 kB
+Dirty:               128 kB
+Writeback:             0 kB
+AnonPages:       1900544 kB
+Mapped:           524288 kB
+Shmem:             32768 kB
+KReclaimable:     196608 kB
+Slab:             262144 kB
+SReclaimable:     196608 kB
+SUnreclaim:        65536 kB
+KernelStack:       12288 kB
+PageTables:        24576 kB
+CommitLimit:     6072000 kB
+Committed_AS:    5242880 kB
+VmallocTotal:   34359738367 kB
+VmallocUsed:       40960 kB
+VmallocChunk:          0 kB
+Percpu:             4096 kB
+HardwareCorrupted:     0 kB
+AnonHugePages:    512000 kB
+HugePages_Total:       0
+HugePages_Free:        0
+HugePages_Rsvd:        0
+HugePages_Surp:        0
+Hugepagesize:       2048 kB
+Hugetlb:               0 kB
+DirectMap4k:      262144 kB
+DirectMap2M:     8126464 kB
+`
+
+func TestParseMemInfo(t *testing.T) {
+	info, err := parseMemInfo(memInfoFixture)
+	if err != nil {
+		t.Fatalf("parseMemInfo failed: %v", err)
+	}
+
+	if info.LimitBytes != 8048000*1024 {
+		t.Errorf("Expected limit %d, got %d", 8048000*1024, info.LimitBytes)
+	}
+	if info.LimitSource != "system" || info.LimitIsSet {
+		t.Errorf("Expected a system limit source, got %v/%q", info.LimitIsSet, info.LimitSource)
+	}
+	if info.AvailableBytes != 4096000*1024 {
+		t.Errorf("Expected available %d, got %d", 4096000*1024, info.AvailableBytes)
+	}
+	if info.UsageBytes != (8048000-4096000)*1024 {
+		t.Errorf("Expected usage %d, got %d", (8048000-4096000)*1024, info.UsageBytes)
+	}
+	if info.CachedBytes != 2048000*1024 || info.BufferBytes != 256000*1024 {
+		t.Errorf("Unexpected cache/buffers: %d/%d", info.CachedBytes, info.BufferBytes)
+	}
+	if info.SwapUsageBytes != 512000*1024 || info.SwapUsagePercent != 25 {
+		t.Errorf("Expected 25%% swap usage of %d, got %f of %d", 512000*1024, info.SwapUsagePercent, info.SwapUsageBytes)
+	}
+
+	// Kernels before 3.14 don't report MemAvailable
+	info, err = parseMemInfo("MemTotal: 1000 kB\nMemFree: 100 kB\nBuffers: 50 kB\nCached: 250 kB\n")
+	if err != nil {
+		t.Fatalf("parseMemInfo failed without MemAvailable: %v", err)
+	}
+	if info.AvailableBytes != 400*1024 {
+		t.Errorf("Expected available %d, got %d", 400*1024, info.AvailableBytes)
+	}
+	if info.UsagePercent != 60 {
+		t.Errorf("Expected 60%% usage, got %f", info.UsagePercent)
+	}
+
+	if _, err := parseMemInfo("MemFree: 100 kB\n"); err == nil {
+		t.Error("Expected error when MemTotal is missing")
+	}
+}
+
+func TestGetInfoProc(t *testing.T) {
+	cpuInfo, err := getCPUInfoProc()
+	if err != nil {
+		t.Logf("getCPUInfoProc failed (expected outside Linux): %v", err)
+		return
+	}
+	if cpuInfo.LimitCores <= 0 {
+		t.Errorf("Expected positive core count, got %f", cpuInfo.LimitCores)
+	}
+	if cpuInfo.UsagePercent < 0 || cpuInfo.UsagePercent > 100 {
+		t.Errorf("Expected CPU usage between 0-100, got %f", cpuInfo.UsagePercent)
+	}
+
+	memInfo, err := getMemoryInfoProc()
+	if err != nil {
+		t.Fatalf("getMemoryInfoProc failed: %v", err)
+	}
+	if memInfo.LimitBytes <= 0 || memInfo.UsageBytes > memInfo.LimitBytes {
+		t.Errorf("Unexpected memory info: %+v", memInfo)
+	}
+
+	t.Logf("CPU from /proc: %+v", cpuInfo)
+	t.Logf("Memory from /proc: %+v", memInfo)
+}
+
+func TestGetMemoryInfoHostPrefersMemInfo(t *testing.T) {
+	if !isLinux() {
+		t.Skip("/proc/meminfo is Linux only")
+	}
+	// Fails every command, so the result must come from /proc/meminfo
+	useFakeRunner(t, fakeRunner{})
+
+	info, err := getMemoryInfoHost()
+	if err != nil {
+		t.Fatalf("getMemoryInfoHost spawned free instead of reading /proc/meminfo: %v", err)
+	}
+	total, err := getSystemMemory()
+	if err != nil {
+		t.Fatalf("getSystemMemory failed: %v", err)
+	}
+	if info.LimitBytes != total {
+		t.Errorf("Expected MemTotal %d, got %d", total, info.LimitBytes)
+	}
+	if info.AvailableBytes <= 0 || info.AvailableBytes > total {
+		t.Errorf("Expected MemAvailable between 0 and %d, got %d", total, info.AvailableBytes)
+	}
+
+	// The command method still means free
+	if _, err := getMemoryInfoCommand(); err == nil {
+		t.Error("Expected getMemoryInfoCommand to run free rather than read /proc/meminfo")
+	}
+}
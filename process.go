@@ -0,0 +1,615 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultUserHZ is the unit of /proc CPU times assumed when the clock tick rate can't be read
+const defaultUserHZ = 100
+
+// clockTicks reads the kernel's clock tick rate (USER_HZ) once
+var clockTicks = sync.OnceValues(readClockTicks)
+
+// GetClockTicks returns the clock tick rate (USER_HZ), the unit of the CPU
+// times in /proc/stat and /proc/<pid>/stat, as sysconf(_SC_CLK_TCK) does.
+// It is 100 on mainstream kernels. Linux only.
+func (Toolbox) GetClockTicks() (int64, error) {
+	return clockTicks()
+}
+
+// userHZ returns the clock tick rate for converting /proc CPU times to
+// seconds, or defaultUserHZ if it can't be read
+func userHZ() float64 {
+	if ticks, err := clockTicks(); err == nil {
+		return float64(ticks)
+	}
+	return defaultUserHZ
+}
+
+// ProcessInfo is a snapshot of a single process's resource usage
+type ProcessInfo struct {
+	PID        int     `json:"pid" js:"pid"`
+	Name       string  `json:"name"`        // Executable name (comm)
+	Command    string  `json:"command"`     // Full command line, or the name if unavailable
+	State      string  `json:"state"`       // e.g. "R (running)" on Linux, "Ss" on macOS
+	CPUPercent float64 `json:"cpu_percent"` // Average over the process lifetime, like ps %CPU
+	RSSBytes   int64   `json:"rss_bytes" js:"rss_bytes"`
+	VSZBytes   int64   `json:"vsz_bytes" js:"vsz_bytes"`
+	// Only set by GetProcessList
+	User       string  `json:"user"`
+	MemPercent float64 `json:"mem_percent"`
+	Start      string  `json:"start"`    // Start time as printed by ps, e.g. "10:30" or "Oct14"
+	CPUTime    string  `json:"cpu_time"` // Cumulative CPU time as printed by ps, e.g. "0:05"
+}
+
+// GetProcessInfo returns resource usage of the process with the given pid
+func (Toolbox) GetProcessInfo(pid int) (ProcessInfo, error) {
+	if pid <= 0 {
+		return ProcessInfo{}, fmt.Errorf("invalid pid: %d", pid)
+	}
+	if isMacOS() {
+		return getProcessInfoPs(pid)
+	}
+	return getProcessInfoProc(pid)
+}
+
+// GetProcessInfoByName returns resource usage of every process whose executable
+// name matches name. The result is empty, not an error, when none match.
+func (tb Toolbox) GetProcessInfoByName(name string) ([]ProcessInfo, error) {
+	if name == "" {
+		return nil, errors.New("process name must not be empty")
+	}
+
+	pids, err := findProcessesByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := []ProcessInfo{}
+	for _, pid := range pids {
+		info, err := tb.GetProcessInfo(pid)
+		if err != nil {
+			// The process exited after it was listed
+			continue
+		}
+		processes = append(processes, info)
+	}
+	return processes, nil
+}
+
+// procStat holds the fields of /proc/<pid>/stat used for ProcessInfo
+type procStat struct {
+	Name      string
+	State     string
+	PPID      int
+	UTime     float64 // In clock ticks
+	STime     float64 // In clock ticks
+	StartTime float64 // In clock ticks after boot
+}
+
+// parseProcPIDStat parses /proc/<pid>/stat. The command name is parenthesized
+// and may itself contain spaces or parentheses, so fields are split after the last ')'.
+func parseProcPIDStat(content string) (procStat, error) {
+	var stat procStat
+
+	open := strings.Index(content, "(")
+	closing := strings.LastIndex(content, ")")
+	if open < 0 || closing < open {
+		return stat, errors.New("invalid /proc/<pid>/stat format")
+	}
+	stat.Name = content[open+1 : closing]
+
+	// Fields after the name start at field 3 (state)
+	fields := strings.Fields(content[closing+1:])
+	if len(fields) < 20 {
+		return stat, errors.New("insufficient fields in /proc/<pid>/stat")
+	}
+	stat.State = fields[0]
+
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return stat, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+	stat.PPID = ppid
+
+	values := make([]float64, 3)
+	for i, index := range []int{11, 12, 19} { // utime, stime, starttime
+		value, err := strconv.ParseFloat(fields[index], 64)
+		if err != nil {
+			return stat, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+		values[i] = value
+	}
+	stat.UTime, stat.STime, stat.StartTime = values[0], values[1], values[2]
+
+	return stat, nil
+}
+
+// lifetimeCPUPercent returns CPU usage averaged over a process's lifetime, in
+// percent of one core, given the system uptime in seconds
+func lifetimeCPUPercent(stat procStat, uptimeSeconds float64) float64 {
+	hz := userHZ()
+	elapsed := uptimeSeconds - stat.StartTime/hz
+	if elapsed <= 0 {
+		return 0
+	}
+	return (stat.UTime + stat.STime) / hz / elapsed * 100
+}
+
+// getProcessInfoProc reads process info from /proc/<pid>/stat, status and cmdline
+func getProcessInfoProc(pid int) (ProcessInfo, error) {
+	info := ProcessInfo{PID: pid}
+
+	if !isLinux() {
+		return info, errors.New(ErrNotSupported)
+	}
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+
+	content, err := readFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return info, fmt.Errorf("%s: %w", ErrReadingFile, err)
+	}
+	stat, err := parseProcPIDStat(content)
+	if err != nil {
+		return info, err
+	}
+	info.Name = stat.Name
+	info.Command = stat.Name
+	info.State = stat.State
+
+	if uptime, err := readUptimeSeconds(); err == nil {
+		info.CPUPercent = lifetimeCPUPercent(stat, uptime)
+	}
+
+	// status has the human readable state and sizes in kB
+	if status, err := readFile(filepath.Join(dir, "status")); err == nil {
+		fields := parseProcStatus(status)
+		if state, ok := fields["State"]; ok {
+			info.State = state
+		}
+		info.RSSBytes = parseKBField(fields["VmRSS"])
+		info.VSZBytes = parseKBField(fields["VmSize"])
+	}
+
+	if cmdline, err := readFile(filepath.Join(dir, "cmdline")); err == nil {
+		if command := strings.TrimSpace(strings.ReplaceAll(cmdline, "\x00", " ")); command != "" {
+			info.Command = command
+		}
+	}
+
+	return info, nil
+}
+
+// procStatusFieldPattern matches the field names of /proc/<pid>/status
+var procStatusFieldPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// GetProcStatus returns the raw value of one field of /proc/<pid>/status, such
+// as "voluntary_ctxt_switches" or "VmHWM", for stats ProcessInfo doesn't cover.
+// Sizes keep their unit, e.g. "1234 kB". Field names may only contain letters,
+// digits and underscores. Linux only.
+func (Toolbox) GetProcStatus(pid int, field string) (string, error) {
+	if pid <= 0 {
+		return "", fmt.Errorf("invalid pid: %d", pid)
+	}
+	if !procStatusFieldPattern.MatchString(field) {
+		return "", fmt.Errorf("invalid status field name %q", field)
+	}
+	if !isLinux() {
+		return "", errors.New(ErrNotSupported)
+	}
+
+	content, err := readFile(filepath.Join("/proc", strconv.Itoa(pid), "status"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("process %d not found", pid)
+		}
+		return "", err
+	}
+	value, ok := parseProcStatus(content)[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found in /proc/%d/status", field, pid)
+	}
+	return value, nil
+}
+
+// parseProcStatus parses the "Key:\tvalue" lines of /proc/<pid>/status
+func parseProcStatus(content string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		fields[key] = strings.TrimSpace(value)
+	}
+	return fields
+}
+
+// parseKBField converts a "1234 kB" value to bytes, returning 0 if it is absent or invalid
+func parseKBField(value string) int64 {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+	kb, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return kb * 1024
+}
+
+// getProcessInfoPs reads process info using `ps -o` (macOS)
+func getProcessInfoPs(pid int) (ProcessInfo, error) {
+	output, err := commandOutput("ps", "-o", "pid=,pcpu=,rss=,vsz=,state=,comm=", "-p", strconv.Itoa(pid))
+	if err != nil {
+		return ProcessInfo{PID: pid}, fmt.Errorf("%s: %w", ErrCommandFailed, err)
+	}
+	return parsePsProcessLine(string(output))
+}
+
+// parsePsProcessLine parses a `ps -o pid=,pcpu=,rss=,vsz=,state=,comm=` line.
+// RSS and VSZ are in KiB; the command may contain spaces.
+func parsePsProcessLine(output string) (ProcessInfo, error) {
+	var info ProcessInfo
+
+	fields := strings.Fields(strings.TrimSpace(output))
+	if len(fields) < 6 {
+		return info, errors.New("invalid ps output")
+	}
+
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return info, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+	cpu, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return info, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+	rss, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return info, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+	vsz, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return info, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+
+	info.PID = pid
+	info.CPUPercent = cpu
+	info.RSSBytes = rss * 1024
+	info.VSZBytes = vsz * 1024
+	info.State = fields[4]
+	info.Command = strings.Join(fields[5:], " ")
+	info.Name = filepath.Base(info.Command)
+	return info, nil
+}
+
+// findProcessesByName returns the pids of processes whose executable name is name
+func findProcessesByName(name string) ([]int, error) {
+	if isMacOS() {
+		output, err := commandOutput("ps", "-A", "-o", "pid=,comm=")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrCommandFailed, err)
+		}
+		var pids []int
+		for _, line := range strings.Split(string(output), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || filepath.Base(strings.Join(fields[1:], " ")) != name {
+				continue
+			}
+			if pid, err := strconv.Atoi(fields[0]); err == nil {
+				pids = append(pids, pid)
+			}
+		}
+		return pids, nil
+	}
+
+	if !isLinux() {
+		return nil, errors.New(ErrNotSupported)
+	}
+	all, err := listProcPIDs()
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, pid := range all {
+		dir := filepath.Join("/proc", strconv.Itoa(pid))
+		// comm is truncated to 15 characters, so also compare the executable from cmdline
+		comm, err := readFile(filepath.Join(dir, "comm"))
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(comm) == name {
+			pids = append(pids, pid)
+			continue
+		}
+		if cmdline, err := readFile(filepath.Join(dir, "cmdline")); err == nil {
+			argv0, _, _ := strings.Cut(cmdline, "\x00")
+			if argv0 != "" && filepath.Base(argv0) == name {
+				pids = append(pids, pid)
+			}
+		}
+	}
+	return pids, nil
+}
+
+// listProcPIDs returns the pids of the numeric directories under /proc (Linux only)
+func listProcPIDs() ([]int, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrReadingFile, err)
+	}
+	var pids []int
+	for _, entry := range entries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+// GetProcessCount returns the number of processes visible to this process,
+// i.e. those in its container's pid namespace
+func (Toolbox) GetProcessCount() (int, error) {
+	if isMacOS() {
+		output, err := commandOutput("ps", "-A", "-o", "pid=")
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", ErrCommandFailed, err)
+		}
+		return countNonEmptyLines(string(output)), nil
+	}
+	if !isLinux() {
+		return 0, errors.New(ErrNotSupported)
+	}
+
+	pids, err := listProcPIDs()
+	if err != nil {
+		return 0, err
+	}
+	return len(pids), nil
+}
+
+// GetThreadCount returns the total number of threads of all visible processes
+func (Toolbox) GetThreadCount() (int, error) {
+	if isMacOS() {
+		// -M prints one line per thread after a header
+		output, err := commandOutput("ps", "-A", "-M")
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", ErrCommandFailed, err)
+		}
+		return max(countNonEmptyLines(string(output))-1, 0), nil
+	}
+	if !isLinux() {
+		return 0, errors.New(ErrNotSupported)
+	}
+
+	pids, err := listProcPIDs()
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, pid := range pids {
+		status, err := readFile(filepath.Join("/proc", strconv.Itoa(pid), "status"))
+		if err != nil {
+			// The process exited after it was listed
+			continue
+		}
+		if threads, err := strconv.Atoi(parseProcStatus(status)["Threads"]); err == nil {
+			total += threads
+		}
+	}
+	return total, nil
+}
+
+// countNonEmptyLines counts the lines of output that are not blank
+func countNonEmptyLines(output string) int {
+	count := 0
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// GetProcessList returns every process from `ps aux` as structured records
+func (Toolbox) GetProcessList() ([]ProcessInfo, error) {
+	output, err := commandOutput("ps", "aux")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrCommandFailed, err)
+	}
+	return parsePsAuxOutput(string(output))
+}
+
+// parsePsAuxOutput parses `ps aux` rows with the columns
+// USER PID %CPU %MEM VSZ RSS TTY STAT START TIME COMMAND. VSZ and RSS are in
+// KiB, and everything after TIME is the command, which may contain spaces.
+func parsePsAuxOutput(output string) ([]ProcessInfo, error) {
+	processes := []ProcessInfo{}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] == "USER" {
+			continue
+		}
+		if len(fields) < 11 {
+			return nil, fmt.Errorf("invalid ps aux line: %q", line)
+		}
+
+		pid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+		cpu, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+		mem, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+		vsz, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+		rss, err := strconv.ParseInt(fields[5], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+
+		command := strings.Join(fields[10:], " ")
+		processes = append(processes, ProcessInfo{
+			PID:        pid,
+			Name:       filepath.Base(fields[10]),
+			Command:    command,
+			State:      fields[7],
+			CPUPercent: cpu,
+			RSSBytes:   rss * 1024,
+			VSZBytes:   vsz * 1024,
+			User:       fields[0],
+			MemPercent: mem,
+			Start:      fields[8],
+			CPUTime:    fields[9],
+		})
+	}
+
+	return processes, nil
+}
+
+// ProcessNode is a process in the tree returned by GetProcessTree
+type ProcessNode struct {
+	PID      int           `json:"pid" js:"pid"`
+	PPID     int           `json:"ppid" js:"ppid"`
+	Command  string        `json:"command"` // Full command line, or the name if unavailable
+	Children []ProcessNode `json:"children"`
+}
+
+// GetProcessTree returns the visible processes as a tree, children ordered by
+// pid. The roots are processes with PPID 0 or 1, so init and its direct
+// children sit side by side, and processes whose parent exited or is outside the
+// pid namespace.
+func (Toolbox) GetProcessTree() ([]ProcessNode, error) {
+	var processes []ProcessNode
+	var err error
+	if isMacOS() {
+		processes, err = listProcessesPs()
+	} else {
+		processes, err = listProcessesProc()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buildProcessTree(processes), nil
+}
+
+// listProcessesProc reads the pid, parent and command of every process from /proc (Linux only)
+func listProcessesProc() ([]ProcessNode, error) {
+	if !isLinux() {
+		return nil, errors.New(ErrNotSupported)
+	}
+	pids, err := listProcPIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make([]ProcessNode, 0, len(pids))
+	for _, pid := range pids {
+		dir := filepath.Join("/proc", strconv.Itoa(pid))
+		content, err := readFile(filepath.Join(dir, "stat"))
+		if err != nil {
+			// The process exited after it was listed
+			continue
+		}
+		stat, err := parseProcPIDStat(content)
+		if err != nil {
+			return nil, err
+		}
+
+		node := ProcessNode{PID: pid, PPID: stat.PPID, Command: stat.Name}
+		if cmdline, err := readFile(filepath.Join(dir, "cmdline")); err == nil {
+			if command := strings.TrimSpace(strings.ReplaceAll(cmdline, "\x00", " ")); command != "" {
+				node.Command = command
+			}
+		}
+		processes = append(processes, node)
+	}
+	return processes, nil
+}
+
+// listProcessesPs reads the pid, parent and command of every process using `ps` (macOS)
+func listProcessesPs() ([]ProcessNode, error) {
+	output, err := commandOutput("ps", "-A", "-o", "pid=,ppid=,command=")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrCommandFailed, err)
+	}
+	return parsePsTreeOutput(string(output))
+}
+
+// parsePsTreeOutput parses `ps -o pid=,ppid=,command=` lines; the command may contain spaces
+func parsePsTreeOutput(output string) ([]ProcessNode, error) {
+	var processes []ProcessNode
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("invalid ps line: %q", line)
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+		processes = append(processes, ProcessNode{PID: pid, PPID: ppid, Command: strings.Join(fields[2:], " ")})
+	}
+	return processes, nil
+}
+
+// buildProcessTree links processes to their parents and returns the roots,
+// ordered by pid like every list of children. Roots are processes with PPID 0
+// or 1, so init's children are listed at the top level next to it rather than
+// nested under it, plus any process whose parent isn't listed.
+func buildProcessTree(processes []ProcessNode) []ProcessNode {
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+
+	listed := make(map[int]bool, len(processes))
+	for _, process := range processes {
+		listed[process.PID] = true
+	}
+	children := make(map[int][]ProcessNode)
+	var roots []ProcessNode
+	for _, process := range processes {
+		if process.PPID == 0 || process.PPID == 1 || process.PPID == process.PID || !listed[process.PPID] {
+			roots = append(roots, process)
+		} else {
+			children[process.PPID] = append(children[process.PPID], process)
+		}
+	}
+
+	var attach func(node ProcessNode) ProcessNode
+	attach = func(node ProcessNode) ProcessNode {
+		node.Children = make([]ProcessNode, 0, len(children[node.PID]))
+		for _, child := range children[node.PID] {
+			node.Children = append(node.Children, attach(child))
+		}
+		return node
+	}
+
+	tree := make([]ProcessNode, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, attach(root))
+	}
+	return tree
+}
@@ -0,0 +1,303 @@
+package toolbox
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const procPIDStatFixture = "1234 (my (weird) app) S 1 1234 1234 0 -1 4194560 1000 0 0 0 300 200 0 0 20 0 4 0 1000 104857600 2560 18446744073709551615"
+
+func TestParseProcPIDStat(t *testing.T) {
+	stat, err := parseProcPIDStat(procPIDStatFixture)
+	if err != nil {
+		t.Fatalf("parseProcPIDStat failed: %v", err)
+	}
+
+	if stat.Name != "my (weird) app" {
+		t.Errorf("Expected name 'my (weird) app', got '%s'", stat.Name)
+	}
+	if stat.State != "S" || stat.PPID != 1 {
+		t.Errorf("Expected state S and ppid 1, got %s and %d", stat.State, stat.PPID)
+	}
+	if stat.UTime != 300 || stat.STime != 200 || stat.StartTime != 1000 {
+		t.Errorf("Unexpected times: %+v", stat)
+	}
+
+	if _, err := parseProcPIDStat("1234 app S 1"); err == nil {
+		t.Error("Expected error without a parenthesized name")
+	}
+	if _, err := parseProcPIDStat("1234 (app) S 1 2 3"); err == nil {
+		t.Error("Expected error for insufficient fields")
+	}
+}
+
+func TestLifetimeCPUPercent(t *testing.T) {
+	// Started 10s after boot, 5s of CPU time, uptime 20s: 50% of one core
+	hz := userHZ()
+	stat := procStat{UTime: 3 * hz, STime: 2 * hz, StartTime: 10 * hz}
+	if cpu := lifetimeCPUPercent(stat, 20); cpu != 50 {
+		t.Errorf("Expected 50%%, got %f", cpu)
+	}
+	if cpu := lifetimeCPUPercent(stat, 10); cpu != 0 {
+		t.Errorf("Expected 0%% without elapsed time, got %f", cpu)
+	}
+}
+
+func TestGetClockTicks(t *testing.T) {
+	ticks, err := Toolbox{}.GetClockTicks()
+	if !isLinux() {
+		if err == nil {
+			t.Error("Expected error on non-Linux platforms")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("GetClockTicks() error: %v", err)
+	}
+	if ticks <= 0 || ticks > 10000 {
+		t.Errorf("Expected a plausible tick rate, got %d", ticks)
+	}
+	if userHZ() != float64(ticks) {
+		t.Errorf("Expected /proc CPU times to use the tick rate %d, got %f", ticks, userHZ())
+	}
+}
+
+func TestParsePsProcessLine(t *testing.T) {
+	info, err := parsePsProcessLine("  4321   12.5  20480 409600 Ss   /Applications/My App.app/Contents/MacOS/My App\n")
+	if err != nil {
+		t.Fatalf("parsePsProcessLine failed: %v", err)
+	}
+
+	if info.PID != 4321 || info.CPUPercent != 12.5 || info.State != "Ss" {
+		t.Errorf("Unexpected process info: %+v", info)
+	}
+	if info.RSSBytes != 20480*1024 || info.VSZBytes != 409600*1024 {
+		t.Errorf("Unexpected sizes: rss %d, vsz %d", info.RSSBytes, info.VSZBytes)
+	}
+	if info.Command != "/Applications/My App.app/Contents/MacOS/My App" {
+		t.Errorf("Unexpected command '%s'", info.Command)
+	}
+
+	if _, err := parsePsProcessLine("4321 12.5"); err == nil {
+		t.Error("Expected error for truncated output")
+	}
+}
+
+func TestGetProcessInfo(t *testing.T) {
+	toolbox := Toolbox{}
+
+	if _, err := toolbox.GetProcessInfo(0); err == nil {
+		t.Error("Expected error for invalid pid")
+	}
+
+	info, err := toolbox.GetProcessInfo(os.Getpid())
+	if err != nil {
+		t.Logf("GetProcessInfo failed (expected in test environment): %v", err)
+		return
+	}
+	if info.PID != os.Getpid() || info.RSSBytes <= 0 || info.Command == "" {
+		t.Errorf("Unexpected process info: %+v", info)
+	}
+
+	// The test binary should be found by its own name
+	processes, err := toolbox.GetProcessInfoByName(filepath.Base(os.Args[0]))
+	if err != nil {
+		t.Fatalf("GetProcessInfoByName failed: %v", err)
+	}
+	found := false
+	for _, process := range processes {
+		found = found || process.PID == os.Getpid()
+	}
+	if !found {
+		t.Errorf("Expected to find pid %d by name, got %+v", os.Getpid(), processes)
+	}
+
+	t.Logf("Process info: %+v", info)
+}
+
+func TestGetProcessAndThreadCount(t *testing.T) {
+	toolbox := Toolbox{}
+
+	processes, err := toolbox.GetProcessCount()
+	if err != nil {
+		t.Logf("GetProcessCount failed (expected in test environment): %v", err)
+		return
+	}
+	if processes < 1 {
+		t.Errorf("Expected at least the test process, got %d", processes)
+	}
+
+	threads, err := toolbox.GetThreadCount()
+	if err != nil {
+		t.Fatalf("GetThreadCount failed: %v", err)
+	}
+	// Every process has at least one thread, and the Go runtime starts several
+	if threads < processes {
+		t.Errorf("Expected at least %d threads, got %d", processes, threads)
+	}
+
+	t.Logf("Processes: %d, threads: %d", processes, threads)
+}
+
+func TestCountNonEmptyLines(t *testing.T) {
+	if count := countNonEmptyLines("  1\n  22\n\n 333\n"); count != 3 {
+		t.Errorf("Expected 3 lines, got %d", count)
+	}
+	if count := countNonEmptyLines(""); count != 0 {
+		t.Errorf("Expected 0 lines, got %d", count)
+	}
+}
+
+func TestParsePsAuxOutput(t *testing.T) {
+	output := `USER         PID %CPU %MEM    VSZ   RSS TTY      STAT START   TIME COMMAND
+root           1  0.0  0.1 168000 11520 ?        Ss   Oct14   0:05 /sbin/init splash
+postgres    4242 12.5  3.2 421000 65536 ?        Sl   10:30  12:34 postgres: writer process   
+root           2  0.0  0.0      0     0 ?        S    Oct14   0:00 [kthreadd]
+`
+	processes, err := parsePsAuxOutput(output)
+	if err != nil {
+		t.Fatalf("parsePsAuxOutput() error: %v", err)
+	}
+	if len(processes) != 3 {
+		t.Fatalf("Expected 3 processes, got %d", len(processes))
+	}
+
+	expected := ProcessInfo{
+		PID:        4242,
+		Name:       "postgres:",
+		Command:    "postgres: writer process",
+		State:      "Sl",
+		CPUPercent: 12.5,
+		RSSBytes:   65536 * 1024,
+		VSZBytes:   421000 * 1024,
+		User:       "postgres",
+		MemPercent: 3.2,
+		Start:      "10:30",
+		CPUTime:    "12:34",
+	}
+	if processes[1] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, processes[1])
+	}
+	if processes[0].Name != "init" || processes[0].Command != "/sbin/init splash" {
+		t.Errorf("Expected init with arguments, got %q / %q", processes[0].Name, processes[0].Command)
+	}
+	if processes[2].Name != "[kthreadd]" {
+		t.Errorf("Expected kernel thread name, got %q", processes[2].Name)
+	}
+
+	if _, err := parsePsAuxOutput("root 1 0.0\n"); err == nil {
+		t.Error("Expected error for a truncated line")
+	}
+	if processes, err := parsePsAuxOutput(""); err != nil || len(processes) != 0 {
+		t.Errorf("Expected empty list for empty output, got %v, %v", processes, err)
+	}
+}
+
+func TestGetProcessList(t *testing.T) {
+	toolbox := Toolbox{}
+
+	processes, err := toolbox.GetProcessList()
+	if err != nil {
+		t.Logf("GetProcessList failed (ps may not be available): %v", err)
+		return
+	}
+	found := false
+	for _, process := range processes {
+		found = found || process.PID == os.Getpid()
+	}
+	if !found {
+		t.Errorf("Expected the test process %d in the list", os.Getpid())
+	}
+}
+
+func TestBuildProcessTree(t *testing.T) {
+	processes, err := parsePsTreeOutput(`    1     0 /sbin/init
+  300     1 /usr/bin/k6 run script.js
+  200     1 sh -c ./start.sh
+  301   300 /usr/bin/worker --id 1
+  302   300 /usr/bin/worker --id 2
+  999   555 orphan
+`)
+	if err != nil {
+		t.Fatalf("parsePsTreeOutput failed: %v", err)
+	}
+
+	// Children of init are roots next to it, as in a container where
+	// everything runs under PID 1
+	tree := buildProcessTree(processes)
+	var roots []int
+	for _, root := range tree {
+		roots = append(roots, root.PID)
+	}
+	if !slices.Equal(roots, []int{1, 200, 300, 999}) {
+		t.Fatalf("Expected roots 1, 200, 300 and the orphan 999, got %v", roots)
+	}
+	if len(tree[0].Children) != 0 {
+		t.Errorf("Expected init's children at the top level instead of under it, got %+v", tree[0].Children)
+	}
+	k6 := tree[2]
+	if k6.Command != "/usr/bin/k6 run script.js" || len(k6.Children) != 2 || k6.Children[0].PID != 301 || k6.Children[1].PPID != 300 {
+		t.Errorf("Unexpected k6 node: %+v", k6)
+	}
+	if tree[1].Children == nil {
+		t.Error("Expected an empty, non-nil children list for leaves")
+	}
+
+	if _, err := parsePsTreeOutput("abc 1 cmd\n"); err == nil {
+		t.Error("Expected error for a non-numeric pid")
+	}
+}
+
+func TestGetProcessTree(t *testing.T) {
+	tree, err := Toolbox{}.GetProcessTree()
+	if err != nil {
+		t.Logf("GetProcessTree failed (expected outside Linux and macOS): %v", err)
+		return
+	}
+
+	var find func(nodes []ProcessNode) bool
+	find = func(nodes []ProcessNode) bool {
+		for _, node := range nodes {
+			if node.PID == os.Getpid() || find(node.Children) {
+				return true
+			}
+		}
+		return false
+	}
+	if !find(tree) {
+		t.Errorf("Expected the test process %d in the tree", os.Getpid())
+	}
+}
+
+func TestGetProcStatus(t *testing.T) {
+	toolbox := Toolbox{}
+
+	for _, field := range []string{"", "../stat", "Vm RSS", "Name:"} {
+		if _, err := toolbox.GetProcStatus(os.Getpid(), field); err == nil {
+			t.Errorf("Expected field name %q to be rejected", field)
+		}
+	}
+	if _, err := toolbox.GetProcStatus(0, "Name"); err == nil {
+		t.Error("Expected error for pid 0")
+	}
+	if !isLinux() {
+		if _, err := toolbox.GetProcStatus(os.Getpid(), "Name"); err == nil {
+			t.Error("Expected error on non-Linux platforms")
+		}
+		return
+	}
+
+	switches, err := toolbox.GetProcStatus(os.Getpid(), "voluntary_ctxt_switches")
+	if err != nil || switches == "" {
+		t.Errorf("Expected voluntary_ctxt_switches of the test process, got %q (%v)", switches, err)
+	}
+	if _, err := toolbox.GetProcStatus(os.Getpid(), "NoSuchField"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a not found error for an unknown field, got %v", err)
+	}
+	if _, err := toolbox.GetProcStatus(1<<22+1, "Name"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a not found error for a missing pid, got %v", err)
+	}
+}
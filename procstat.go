@@ -0,0 +1,241 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCPUSampleInterval is the interval between the two /proc/stat reads of a CPU sample
+const defaultCPUSampleInterval = 100 * time.Millisecond
+
+// cpuTimes holds the cumulative counters of a /proc/stat cpu line, in clock
+// ticks (see GetClockTicks). Percentages are ratios of tick deltas, so they
+// don't depend on the tick rate; converting to seconds does.
+type cpuTimes struct {
+	User    float64
+	Nice    float64
+	System  float64
+	Idle    float64
+	IOWait  float64
+	IRQ     float64
+	SoftIRQ float64
+	Steal   float64
+}
+
+// total returns the sum of all counters. Guest time is already included in user time.
+func (c cpuTimes) total() float64 {
+	return c.User + c.Nice + c.System + c.Idle + c.IOWait + c.IRQ + c.SoftIRQ + c.Steal
+}
+
+// parseProcStatCPUTimes parses the cpu lines of /proc/stat keyed by name ("cpu", "cpu0", ...)
+func parseProcStatCPUTimes(content string) (map[string]cpuTimes, error) {
+	times := make(map[string]cpuTimes)
+
+	for _, line := range strings.Split(content, "\n") {
+		if !strings.HasPrefix(line, "cpu") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("insufficient CPU fields in /proc/stat line: %q", line)
+		}
+
+		// user nice system idle [iowait irq softirq steal guest guest_nice]
+		var values [8]float64
+		for i := 0; i < len(values) && i+1 < len(fields); i++ {
+			value, err := strconv.ParseFloat(fields[i+1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", ErrParsingValue, err)
+			}
+			values[i] = value
+		}
+
+		times[fields[0]] = cpuTimes{
+			User:    values[0],
+			Nice:    values[1],
+			System:  values[2],
+			Idle:    values[3],
+			IOWait:  values[4],
+			IRQ:     values[5],
+			SoftIRQ: values[6],
+			Steal:   values[7],
+		}
+	}
+
+	if _, ok := times["cpu"]; !ok {
+		return nil, errors.New("invalid /proc/stat format")
+	}
+	return times, nil
+}
+
+// readProcStatCPUTimes reads and parses the cpu lines of /proc/stat
+func readProcStatCPUTimes() (map[string]cpuTimes, error) {
+	content, err := readFile("/proc/stat")
+	if err != nil {
+		return nil, err
+	}
+	return parseProcStatCPUTimes(content)
+}
+
+// sampleProcStatCPUTimes reads /proc/stat twice, interval apart, and returns both reads
+func sampleProcStatCPUTimes(interval time.Duration) (before, after map[string]cpuTimes, err error) {
+	if !isLinux() {
+		return nil, nil, errors.New(ErrNotSupported)
+	}
+
+	before, err = readProcStatCPUTimes()
+	if err != nil {
+		return nil, nil, err
+	}
+	time.Sleep(interval)
+	after, err = readProcStatCPUTimes()
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+// stealPercent returns the share of elapsed ticks between two reads that was stolen by the hypervisor
+func stealPercent(before, after cpuTimes) float64 {
+	totalDelta := after.total() - before.total()
+	if totalDelta <= 0 {
+		return 0
+	}
+	return (after.Steal - before.Steal) / totalDelta * 100
+}
+
+// sampleCPUStealPercent samples the aggregate CPU steal percentage over interval
+func sampleCPUStealPercent(interval time.Duration) (float64, error) {
+	before, after, err := sampleProcStatCPUTimes(interval)
+	if err != nil {
+		return 0, err
+	}
+	return stealPercent(before["cpu"], after["cpu"]), nil
+}
+
+// GetCPUStealPercent samples /proc/stat over a short interval and returns the
+// percentage of CPU time stolen by the hypervisor. Sustained steal explains a
+// generator missing its target rate on a cloud VM. Linux only.
+func (Toolbox) GetCPUStealPercent() (float64, error) {
+	return sampleCPUStealPercent(defaultCPUSampleInterval)
+}
+
+// IsCPUStealed samples CPU steal time and reports whether it exceeds thresholdPercent,
+// along with the measured steal percentage
+func (Toolbox) IsCPUStealed(thresholdPercent float64) (bool, float64, error) {
+	if thresholdPercent < 0 || thresholdPercent > 100 {
+		return false, 0, fmt.Errorf("threshold must be between 0 and 100, got %f", thresholdPercent)
+	}
+
+	steal, err := sampleCPUStealPercent(defaultCPUSampleInterval)
+	if err != nil {
+		return false, 0, err
+	}
+	return steal > thresholdPercent, steal, nil
+}
+
+// GetCPUUsagePerCore samples CPU time over a short interval and returns the
+// usage percentage of each core, in CPU number order. One core near 100% while
+// the others idle points at a single-threaded bottleneck the average hides.
+// On cgroup v1 the cgroup's own per-CPU time from cpuacct.usage_percpu is
+// used, otherwise host-wide /proc/stat. Linux only.
+func (Toolbox) GetCPUUsagePerCore() ([]float64, error) {
+	if before, err := readCgroupPerCPUUsage(); err == nil {
+		start := time.Now()
+		time.Sleep(defaultCPUSampleInterval)
+		after, err := readCgroupPerCPUUsage()
+		if err != nil {
+			return nil, err
+		}
+		return cgroupPerCoreBusyPercents(before, after, time.Since(start)), nil
+	}
+
+	before, after, err := sampleProcStatCPUTimes(defaultCPUSampleInterval)
+	if err != nil {
+		return nil, err
+	}
+	return perCoreBusyPercents(before, after), nil
+}
+
+// readCgroupPerCPUUsage reads cgroup v1 cpuacct.usage_percpu
+func readCgroupPerCPUUsage() ([]float64, error) {
+	if !isLinux() {
+		return nil, errors.New(ErrNotSupported)
+	}
+	content, err := readFile(cgroupFile("cpuacct/cpuacct.usage_percpu"))
+	if err != nil {
+		return nil, err
+	}
+	return parseCgroupPerCPUUsage(content)
+}
+
+// parseCgroupPerCPUUsage parses the space separated nanoseconds of CPU time
+// the cgroup used on each CPU, which may end with a trailing space
+func parseCgroupPerCPUUsage(content string) ([]float64, error) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return nil, errors.New("no per-CPU usage in cpuacct.usage_percpu")
+	}
+	usage := make([]float64, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+		usage[i] = value
+	}
+	return usage, nil
+}
+
+// cgroupPerCoreBusyPercents converts the growth of per-CPU nanosecond counters
+// over elapsed into usage percentages. If the CPU count changed between reads,
+// only the CPUs in both are reported.
+func cgroupPerCoreBusyPercents(before, after []float64, elapsed time.Duration) []float64 {
+	percents := make([]float64, min(len(before), len(after)))
+	if elapsed <= 0 {
+		return percents
+	}
+	for i := range percents {
+		percent := (after[i] - before[i]) / float64(elapsed.Nanoseconds()) * 100
+		percents[i] = max(0, min(percent, 100))
+	}
+	return percents
+}
+
+// perCoreBusyPercents returns busyPercent of every numbered cpu line present in
+// both reads, ordered by CPU number. Offline CPUs have no line and are left out.
+func perCoreBusyPercents(before, after map[string]cpuTimes) []float64 {
+	var cores []int
+	for name := range after {
+		// The aggregate "cpu" line has no number
+		number, err := strconv.Atoi(strings.TrimPrefix(name, "cpu"))
+		if err != nil {
+			continue
+		}
+		if _, ok := before[name]; ok {
+			cores = append(cores, number)
+		}
+	}
+	sort.Ints(cores)
+
+	percents := make([]float64, 0, len(cores))
+	for _, core := range cores {
+		name := "cpu" + strconv.Itoa(core)
+		percents = append(percents, busyPercent(before[name], after[name]))
+	}
+	return percents
+}
+
+// busyPercent returns the share of elapsed ticks between two reads spent doing work
+func busyPercent(before, after cpuTimes) float64 {
+	totalDelta := after.total() - before.total()
+	if totalDelta <= 0 {
+		return 0
+	}
+	idleDelta := (after.Idle + after.IOWait) - (before.Idle + before.IOWait)
+	return (totalDelta - idleDelta) / totalDelta * 100
+}
@@ -0,0 +1,234 @@
+package toolbox
+
+import (
+	"testing"
+	"time"
+)
+
+const procStatFixture = `cpu  10132153 290696 3084719 46828483 16683 0 25195 1000 0 0
+cpu0 1393280 32966 572056 13343292 6130 0 17875 500 0 0
+cpu1 1335985 28476 545315 13477283 5125 0 4223 500 0 0
+intr 1462898 0 0 0
+ctxt 2520233
+btime 1700000000
+`
+
+func TestParseProcStatCPUTimes(t *testing.T) {
+	times, err := parseProcStatCPUTimes(procStatFixture)
+	if err != nil {
+		t.Fatalf("parseProcStatCPUTimes failed: %v", err)
+	}
+
+	if len(times) != 3 {
+		t.Errorf("Expected 3 cpu lines, got %d", len(times))
+	}
+
+	total := times["cpu"]
+	if total.User != 10132153 || total.Idle != 46828483 || total.Steal != 1000 {
+		t.Errorf("Unexpected aggregate times: %+v", total)
+	}
+	if times["cpu1"].SoftIRQ != 4223 {
+		t.Errorf("Expected cpu1 softirq 4223, got %f", times["cpu1"].SoftIRQ)
+	}
+
+	// Old kernels only report user, nice, system and idle
+	times, err = parseProcStatCPUTimes("cpu 100 0 50 850\n")
+	if err != nil {
+		t.Fatalf("parseProcStatCPUTimes failed on short line: %v", err)
+	}
+	if times["cpu"].total() != 1000 {
+		t.Errorf("Expected total 1000, got %f", times["cpu"].total())
+	}
+
+	if _, err := parseProcStatCPUTimes("intr 1 2 3\n"); err == nil {
+		t.Error("Expected error when the aggregate cpu line is missing")
+	}
+	if _, err := parseProcStatCPUTimes("cpu 1 2\n"); err == nil {
+		t.Error("Expected error for insufficient fields")
+	}
+}
+
+func TestStealPercent(t *testing.T) {
+	before := cpuTimes{User: 100, System: 100, Idle: 700, Steal: 100}
+	after := cpuTimes{User: 150, System: 150, Idle: 750, Steal: 150}
+
+	steal := stealPercent(before, after)
+	if steal != 25 {
+		t.Errorf("Expected 25%% steal, got %f", steal)
+	}
+
+	// No elapsed ticks
+	if steal := stealPercent(before, before); steal != 0 {
+		t.Errorf("Expected 0%% steal without elapsed ticks, got %f", steal)
+	}
+}
+
+func TestGetCPUStealPercent(t *testing.T) {
+	steal, err := Toolbox{}.GetCPUStealPercent()
+	if !isLinux() {
+		if err == nil {
+			t.Error("Expected error on non-Linux platforms")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("GetCPUStealPercent() error: %v", err)
+	}
+	if steal < 0 || steal > 100 {
+		t.Errorf("Expected steal between 0-100, got %f", steal)
+	}
+}
+
+func TestGetCPUInfoCgroupStealPercent(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	root := t.TempDir()
+	writeCgroupFile(t, root, "cpu.max", "200000 100000\n")
+	writeCgroupFile(t, root, "cpu.stat", "usage_usec 1000000\n")
+	Configure(Options{CgroupRoot: root})
+	t.Cleanup(func() { Configure(Options{}) })
+
+	info, err := getCPUInfoCgroup()
+	if err != nil {
+		t.Fatalf("getCPUInfoCgroup() error: %v", err)
+	}
+	if info.StealPercent < 0 || info.StealPercent > 100 {
+		t.Errorf("Expected steal between 0-100, got %f", info.StealPercent)
+	}
+}
+
+func TestIsCPUStealed(t *testing.T) {
+	toolbox := Toolbox{}
+
+	if _, _, err := toolbox.IsCPUStealed(150); err == nil {
+		t.Error("Expected error for threshold above 100")
+	}
+
+	stealed, steal, err := toolbox.IsCPUStealed(100)
+	if err != nil {
+		t.Logf("IsCPUStealed failed (expected outside Linux): %v", err)
+		return
+	}
+	if stealed {
+		t.Errorf("Expected steal to never exceed 100%%, got %f", steal)
+	}
+	if steal < 0 || steal > 100 {
+		t.Errorf("Expected steal between 0-100, got %f", steal)
+	}
+
+	t.Logf("CPU steal: %.2f%%", steal)
+}
+
+func TestBusyPercent(t *testing.T) {
+	before := cpuTimes{User: 100, System: 100, Idle: 750, IOWait: 50}
+	after := cpuTimes{User: 160, System: 120, Idle: 860, IOWait: 60}
+
+	// 200 elapsed ticks, 120 of them idle or waiting on IO
+	busy := busyPercent(before, after)
+	if busy != 40 {
+		t.Errorf("Expected 40%% busy, got %f", busy)
+	}
+
+	if busy := busyPercent(after, after); busy != 0 {
+		t.Errorf("Expected 0%% busy without elapsed ticks, got %f", busy)
+	}
+}
+
+func TestPerCoreBusyPercents(t *testing.T) {
+	before := map[string]cpuTimes{
+		"cpu":   {User: 300, Idle: 700},
+		"cpu0":  {User: 100, Idle: 100},
+		"cpu2":  {User: 100, Idle: 100},
+		"cpu10": {User: 100, Idle: 100},
+	}
+	after := map[string]cpuTimes{
+		"cpu":   {User: 500, Idle: 900},
+		"cpu0":  {User: 200, Idle: 100},
+		"cpu2":  {User: 125, Idle: 175},
+		"cpu10": {User: 150, Idle: 150},
+		"cpu11": {User: 100, Idle: 100}, // Came online between the reads
+	}
+
+	percents := perCoreBusyPercents(before, after)
+	expected := []float64{100, 25, 50}
+	if len(percents) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, percents)
+	}
+	for i := range expected {
+		if percents[i] != expected[i] {
+			t.Errorf("Core %d: expected %f, got %f", i, expected[i], percents[i])
+		}
+	}
+}
+
+func TestGetCPUUsagePerCore(t *testing.T) {
+	toolbox := Toolbox{}
+	percents, err := toolbox.GetCPUUsagePerCore()
+	if err != nil {
+		t.Logf("GetCPUUsagePerCore failed (expected in test environment): %v", err)
+		return
+	}
+	if len(percents) == 0 {
+		t.Error("Expected at least one core")
+	}
+	for i, percent := range percents {
+		if percent < 0 || percent > 100 {
+			t.Errorf("Core %d: expected usage between 0-100, got %f", i, percent)
+		}
+	}
+}
+
+func TestParseCgroupPerCPUUsage(t *testing.T) {
+	usage, err := parseCgroupPerCPUUsage("1000000 2000000 0 3000000 \n")
+	if err != nil {
+		t.Fatalf("parseCgroupPerCPUUsage failed: %v", err)
+	}
+	if len(usage) != 4 || usage[1] != 2000000 || usage[3] != 3000000 {
+		t.Errorf("Expected 4 per-CPU values despite the trailing space, got %v", usage)
+	}
+
+	if _, err := parseCgroupPerCPUUsage(" \n"); err == nil {
+		t.Error("Expected error for an empty file")
+	}
+	if _, err := parseCgroupPerCPUUsage("100 abc\n"); err == nil {
+		t.Error("Expected error for an invalid value")
+	}
+}
+
+func TestCgroupPerCoreBusyPercents(t *testing.T) {
+	before := []float64{0, 1e9, 5e8}
+	after := []float64{5e7, 1.1e9, 5e8, 7e8} // A CPU came online between reads
+
+	percents := cgroupPerCoreBusyPercents(before, after, 100*time.Millisecond)
+	if len(percents) != 3 {
+		t.Fatalf("Expected only the CPUs present in both reads, got %v", percents)
+	}
+	if percents[0] != 50 || percents[1] != 100 || percents[2] != 0 {
+		t.Errorf("Expected [50 100 0], got %v", percents)
+	}
+
+	// Counters growing faster than wall time are capped
+	if percents := cgroupPerCoreBusyPercents([]float64{0}, []float64{2e8}, 100*time.Millisecond); percents[0] != 100 {
+		t.Errorf("Expected usage capped at 100%%, got %v", percents)
+	}
+}
+
+func TestGetCPUUsagePerCoreCgroupV1(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	root := t.TempDir()
+	writeCgroupFile(t, root, "cpuacct/cpuacct.usage_percpu", "1000 2000 \n")
+	Configure(Options{CgroupRoot: root})
+	t.Cleanup(func() { Configure(Options{}) })
+
+	percents, err := Toolbox{}.GetCPUUsagePerCore()
+	if err != nil {
+		t.Fatalf("GetCPUUsagePerCore() error: %v", err)
+	}
+	// The fixture doesn't change, so the cgroup used no CPU time
+	if len(percents) != 2 || percents[0] != 0 || percents[1] != 0 {
+		t.Errorf("Expected 2 idle cores from cpuacct.usage_percpu, got %v", percents)
+	}
+}
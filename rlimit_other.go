@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package toolbox
+
+import "errors"
+
+// getResourceLimits is not supported on this platform
+func getResourceLimits() (map[string]RLimit, error) {
+	return nil, errors.New(ErrNotSupported)
+}
+
+// getNoFileLimit is not supported on this platform
+func getNoFileLimit() (soft, hard int64, err error) {
+	return 0, 0, errors.New(ErrNotSupported)
+}
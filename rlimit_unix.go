@@ -0,0 +1,59 @@
+//go:build linux || darwin
+
+package toolbox
+
+import (
+	"fmt"
+	"math"
+
+	"golang.org/x/sys/unix"
+)
+
+// rlimitResources are the getrlimit resources reported by GetResourceLimits,
+// keyed by the name ulimit and prlimit use for them
+var rlimitResources = map[string]int{
+	"as":      unix.RLIMIT_AS,      // Address space size in bytes
+	"core":    unix.RLIMIT_CORE,    // Core file size in bytes
+	"cpu":     unix.RLIMIT_CPU,     // CPU time in seconds
+	"data":    unix.RLIMIT_DATA,    // Data segment size in bytes
+	"fsize":   unix.RLIMIT_FSIZE,   // Size of files the process may write, in bytes
+	"memlock": unix.RLIMIT_MEMLOCK, // Locked memory in bytes
+	"nofile":  unix.RLIMIT_NOFILE,  // Open file descriptors
+	"nproc":   unix.RLIMIT_NPROC,   // Processes (threads on Linux) of the user
+	"stack":   unix.RLIMIT_STACK,   // Stack size in bytes
+}
+
+// getResourceLimits reads every resource in rlimitResources
+func getResourceLimits() (map[string]RLimit, error) {
+	limits := make(map[string]RLimit, len(rlimitResources))
+	for name, resource := range rlimitResources {
+		soft, hard, err := getRlimit(resource)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		limits[name] = RLimit{Soft: soft, Hard: hard}
+	}
+	return limits, nil
+}
+
+// getNoFileLimit returns the soft and hard RLIMIT_NOFILE limits, -1 meaning unlimited
+func getNoFileLimit() (soft, hard int64, err error) {
+	return getRlimit(unix.RLIMIT_NOFILE)
+}
+
+// getRlimit returns the soft and hard limits of resource, -1 meaning unlimited
+func getRlimit(resource int) (soft, hard int64, err error) {
+	var limit unix.Rlimit
+	if err := unix.Getrlimit(resource, &limit); err != nil {
+		return 0, 0, fmt.Errorf("getrlimit failed: %w", err)
+	}
+	return rlimitValue(limit.Cur), rlimitValue(limit.Max), nil
+}
+
+// rlimitValue converts a raw rlimit to int64, mapping RLIM_INFINITY to -1
+func rlimitValue(value uint64) int64 {
+	if value > math.MaxInt64 {
+		return -1
+	}
+	return int64(value)
+}
@@ -0,0 +1,42 @@
+//go:build linux || darwin
+
+package toolbox
+
+import (
+	"testing"
+)
+
+func TestRlimitValue(t *testing.T) {
+	if value := rlimitValue(1024); value != 1024 {
+		t.Errorf("Expected 1024, got %d", value)
+	}
+	if value := rlimitValue(^uint64(0)); value != -1 {
+		t.Errorf("Expected -1 for RLIM_INFINITY, got %d", value)
+	}
+}
+
+func TestGetResourceLimits(t *testing.T) {
+	toolbox := Toolbox{}
+	limits, err := toolbox.GetResourceLimits()
+	if err != nil {
+		t.Fatalf("GetResourceLimits failed: %v", err)
+	}
+
+	if len(limits) != len(rlimitResources) {
+		t.Errorf("Expected %d limits, got %v", len(rlimitResources), limits)
+	}
+	for name, limit := range limits {
+		if limit.Hard != -1 && (limit.Soft == -1 || limit.Soft > limit.Hard) {
+			t.Errorf("%s: expected soft limit within the hard limit, got %+v", name, limit)
+		}
+	}
+
+	// nofile matches what GetFileDescriptorInfo reports
+	soft, hard, err := getNoFileLimit()
+	if err != nil {
+		t.Fatalf("getNoFileLimit failed: %v", err)
+	}
+	if nofile := limits["nofile"]; nofile.Soft != soft || nofile.Hard != hard {
+		t.Errorf("Expected nofile %d/%d, got %+v", soft, hard, nofile)
+	}
+}
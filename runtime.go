@@ -0,0 +1,29 @@
+package toolbox
+
+import "runtime"
+
+// RuntimeStats describes the memory and goroutines of the k6 process itself
+type RuntimeStats struct {
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes"` // Bytes of allocated heap objects
+	HeapInuseBytes uint64 `json:"heap_inuse_bytes"` // Bytes in in-use heap spans
+	SysBytes       uint64 `json:"sys_bytes"`        // Total bytes obtained from the OS
+	NumGC          uint32 `json:"num_gc" js:"num_gc"`
+	PauseTotalNs   uint64 `json:"pause_total_ns"` // Cumulative GC stop-the-world pause time
+	NumGoroutine   int    `json:"num_goroutine"`
+}
+
+// GetGoRuntimeStats returns Go runtime memory statistics and the goroutine
+// count of the k6 process, to tell generator-side growth from target load
+func (Toolbox) GetGoRuntimeStats() (RuntimeStats, error) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return RuntimeStats{
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapInuseBytes: mem.HeapInuse,
+		SysBytes:       mem.Sys,
+		NumGC:          mem.NumGC,
+		PauseTotalNs:   mem.PauseTotalNs,
+		NumGoroutine:   runtime.NumGoroutine(),
+	}, nil
+}
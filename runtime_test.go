@@ -0,0 +1,30 @@
+package toolbox
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGetGoRuntimeStats(t *testing.T) {
+	toolbox := Toolbox{}
+
+	before, err := toolbox.GetGoRuntimeStats()
+	if err != nil {
+		t.Fatalf("GetGoRuntimeStats() error: %v", err)
+	}
+	runtime.GC()
+	after, err := toolbox.GetGoRuntimeStats()
+	if err != nil {
+		t.Fatalf("GetGoRuntimeStats() error: %v", err)
+	}
+
+	if after.NumGC <= before.NumGC {
+		t.Errorf("Expected NumGC to grow after a GC, got %d then %d", before.NumGC, after.NumGC)
+	}
+	if after.HeapAllocBytes == 0 || after.SysBytes < after.HeapInuseBytes {
+		t.Errorf("Unexpected memory stats: %+v", after)
+	}
+	if after.NumGoroutine < 1 {
+		t.Errorf("Expected at least one goroutine, got %d", after.NumGoroutine)
+	}
+}
@@ -0,0 +1,55 @@
+package toolbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// maxCollectSamples bounds how many samples CollectSamples takes in one call
+const maxCollectSamples = 1000
+
+// CollectSamples collects count SystemInfo samples, starting one every intervalMs
+// milliseconds, and returns them in order with their timestamp_ms set. It blocks
+// the calling VU for the whole window; if the VU's iteration is cancelled it
+// stops early and returns the samples collected so far with an error.
+func (tb Toolbox) CollectSamples(count int, intervalMs int) ([]SystemInfo, error) {
+	ctx := context.Background()
+	if tb.vu != nil {
+		ctx = tb.vu.Context()
+	}
+	return collectSamples(ctx, count, time.Duration(intervalMs)*time.Millisecond, tb.GetSystemInfo)
+}
+
+// collectSamples calls collect count times, interval apart, until ctx is done
+func collectSamples(ctx context.Context, count int, interval time.Duration, collect func() (SystemInfo, error)) ([]SystemInfo, error) {
+	if count < 1 || count > maxCollectSamples {
+		return nil, fmt.Errorf("count must be between 1 and %d", maxCollectSamples)
+	}
+	if interval < minResourceWatchInterval {
+		return nil, fmt.Errorf("interval must be at least %dms", minResourceWatchInterval.Milliseconds())
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	samples := make([]SystemInfo, 0, count)
+	for {
+		start := time.Now()
+		info, err := collect()
+		if err != nil {
+			return samples, fmt.Errorf("sample %d: %w", len(samples)+1, err)
+		}
+		info.TimestampMs = start.UnixMilli()
+		samples = append(samples, info)
+		if len(samples) == count {
+			return samples, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return samples, fmt.Errorf("collection stopped after %d of %d samples: %w", len(samples), count, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
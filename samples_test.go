@@ -0,0 +1,65 @@
+package toolbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCollectSamples(t *testing.T) {
+	calls := 0
+	collect := func() (SystemInfo, error) {
+		calls++
+		return SystemInfo{Method: "cgroup"}, nil
+	}
+
+	samples, err := collectSamples(context.Background(), 3, 100*time.Millisecond, collect)
+	if err != nil {
+		t.Fatalf("collectSamples() error: %v", err)
+	}
+	if len(samples) != 3 || calls != 3 {
+		t.Fatalf("Expected 3 samples, got %d from %d calls", len(samples), calls)
+	}
+	for i := 1; i < len(samples); i++ {
+		if gap := samples[i].TimestampMs - samples[i-1].TimestampMs; gap < 90 || gap > 500 {
+			t.Errorf("Expected samples about 100ms apart, got %dms", gap)
+		}
+	}
+
+	if _, err := collectSamples(context.Background(), 0, 100*time.Millisecond, collect); err == nil {
+		t.Error("Expected error for zero count")
+	}
+	if _, err := collectSamples(context.Background(), 2, 10*time.Millisecond, collect); err == nil {
+		t.Error("Expected error for an interval below the minimum")
+	}
+}
+
+func TestCollectSamplesCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	collect := func() (SystemInfo, error) {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+		return SystemInfo{}, nil
+	}
+
+	samples, err := collectSamples(ctx, 10, 100*time.Millisecond, collect)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+	if len(samples) != 2 {
+		t.Errorf("Expected the 2 samples collected before cancellation, got %d", len(samples))
+	}
+}
+
+func TestCollectSamplesError(t *testing.T) {
+	collect := func() (SystemInfo, error) {
+		return SystemInfo{}, errors.New("boom")
+	}
+	if _, err := collectSamples(context.Background(), 2, 100*time.Millisecond, collect); err == nil {
+		t.Error("Expected the collection error")
+	}
+}
@@ -0,0 +1,242 @@
+package toolbox
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// maxCPUBaselineAge is how old a previous CPU counter read may be and still be
+// reused as the baseline of an adaptive CPU sample
+const maxCPUBaselineAge = 5 * time.Second
+
+// cpuSnapshot is a timestamped read of the counter sampleCPUUsage uses: the
+// cgroup's CPU seconds, or the aggregate /proc/stat cpu line without a cgroup
+type cpuSnapshot struct {
+	at      time.Time
+	cgroup  bool
+	seconds float64  // cgroup CPU seconds, if cgroup
+	times   cpuTimes // /proc/stat cpu line, if not cgroup
+}
+
+// adaptiveCPU holds the latest read made by GetCPUUsageAdaptive and the
+// usage computed between it and the read before it
+var adaptiveCPU struct {
+	sync.Mutex
+	last  cpuSnapshot
+	usage float64
+	valid bool // Whether usage was computed from a completed sample
+}
+
+// smoothedCPU holds the exponential moving average kept by GetSmoothedCPUUsage
+var smoothedCPU struct {
+	sync.Mutex
+	value float64
+	valid bool // Whether value has been seeded with a first reading
+}
+
+// MemoryWindowPeak summarizes memory usage sampled over a short window
+type MemoryWindowPeak struct {
+	PeakBytes    int64 `json:"peak_bytes"`
+	MinBytes     int64 `json:"min_bytes"`
+	Samples      int   `json:"samples"`
+	WindowMs     int   `json:"window_ms"`
+	PeakOffsetMs int64 `json:"peak_offset_ms"` // Time from the start of the window to the peak sample
+}
+
+// GetMemoryPeakOverWindow samples memory usage every sampleEveryMs for windowMs and
+// returns the peak observed. This blocks the calling VU for the whole window.
+func (Toolbox) GetMemoryPeakOverWindow(windowMs int, sampleEveryMs int) (MemoryWindowPeak, error) {
+	return sampleMemoryPeak(time.Duration(windowMs)*time.Millisecond, time.Duration(sampleEveryMs)*time.Millisecond, readMemoryUsageBytes)
+}
+
+// sampleMemoryPeak calls read every interval for window and tracks the extremes
+func sampleMemoryPeak(window, interval time.Duration, read func() (int64, error)) (MemoryWindowPeak, error) {
+	var result MemoryWindowPeak
+
+	if window <= 0 || interval <= 0 {
+		return result, errors.New("window and sample interval must be greater than 0")
+	}
+	if interval > window {
+		return result, errors.New("sample interval must not exceed the window")
+	}
+	result.WindowMs = int(window / time.Millisecond)
+
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		usage, err := read()
+		if err != nil {
+			return result, err
+		}
+		if result.Samples == 0 || usage > result.PeakBytes {
+			result.PeakBytes = usage
+			result.PeakOffsetMs = time.Since(start).Milliseconds()
+		}
+		if result.Samples == 0 || usage < result.MinBytes {
+			result.MinBytes = usage
+		}
+		result.Samples++
+
+		if time.Since(start)+interval > window {
+			return result, nil
+		}
+		<-ticker.C
+	}
+}
+
+// readMemoryUsageBytes reads current memory usage in bytes, preferring the cheap cgroup files
+func readMemoryUsageBytes() (int64, error) {
+	usage, err := getMemoryUsage()
+	if err != nil {
+		memInfo, err := getMemoryInfoHost()
+		if err != nil {
+			return 0, err
+		}
+		return memInfo.UsageBytes, nil
+	}
+	return usage, nil
+}
+
+// GetCPUUsageAdaptive returns CPU usage computed from the two most recent reads
+// of the cgroup CPU counter (/proc/stat outside a cgroup), as a percentage of
+// the CPU limit like GetCPUUsage. A fresh read is only taken when the last one
+// is older than maxStalenessMs, and a recent previous read is reused as the
+// baseline so back-to-back calls don't each pay a full sample interval.
+func (Toolbox) GetCPUUsageAdaptive(maxStalenessMs int) (float64, error) {
+	if maxStalenessMs < 0 {
+		return 0, errors.New("max staleness must not be negative")
+	}
+	if !isLinux() {
+		cpuInfo, err := getCPUInfoCommand()
+		if err != nil {
+			return 0, err
+		}
+		return cpuInfo.UsagePercent, nil
+	}
+	return sampleCPUUsageAdaptive(time.Duration(maxStalenessMs)*time.Millisecond, readCPUSnapshot, cpuPercentBetween)
+}
+
+// sampleCPUUsageAdaptive implements GetCPUUsageAdaptive using read to take
+// counter reads and percent to turn two of them into usage. The lock is only
+// held to read and store the shared state, never while waiting for a sample,
+// so VUs that can use the cached result don't queue behind one that samples.
+func sampleCPUUsageAdaptive(maxStaleness time.Duration, read func() (cpuSnapshot, error), percent func(before, after cpuSnapshot) (float64, error)) (float64, error) {
+	adaptiveCPU.Lock()
+	if adaptiveCPU.valid && time.Since(adaptiveCPU.last.at) <= maxStaleness {
+		usage := adaptiveCPU.usage
+		adaptiveCPU.Unlock()
+		return usage, nil
+	}
+	baseline := adaptiveCPU.last
+	adaptiveCPU.Unlock()
+
+	current, err := read()
+	if err != nil {
+		return 0, err
+	}
+	// Start over when the baseline is missing, too old or from another counter
+	if baseline.at.IsZero() || current.at.Sub(baseline.at) > maxCPUBaselineAge || baseline.cgroup != current.cgroup {
+		baseline = current
+	}
+	// Only wait for whatever part of the sample interval hasn't already elapsed
+	if wait := defaultCPUSampleInterval - current.at.Sub(baseline.at); wait > 0 {
+		time.Sleep(wait)
+		if current, err = read(); err != nil {
+			return 0, err
+		}
+	}
+
+	usage, err := percent(baseline, current)
+	if err != nil {
+		return 0, err
+	}
+
+	adaptiveCPU.Lock()
+	defer adaptiveCPU.Unlock()
+	// A concurrent caller may have stored a newer sample in the meantime
+	if current.at.After(adaptiveCPU.last.at) {
+		adaptiveCPU.last = current
+		adaptiveCPU.usage = usage
+		adaptiveCPU.valid = true
+	}
+	return usage, nil
+}
+
+// readCPUSnapshot reads the cgroup CPU counter, or /proc/stat without one
+func readCPUSnapshot() (cpuSnapshot, error) {
+	if seconds, err := readCgroupCPUUsageSeconds(); err == nil {
+		return cpuSnapshot{at: time.Now(), cgroup: true, seconds: seconds}, nil
+	}
+	times, err := readProcStatCPUTimes()
+	if err != nil {
+		return cpuSnapshot{}, err
+	}
+	return cpuSnapshot{at: time.Now(), times: times["cpu"]}, nil
+}
+
+// cpuPercentBetween returns the usage between two snapshots of the same counter
+// as a percentage of the CPU limit, computed like sampleCPUUsagePercent
+func cpuPercentBetween(before, after cpuSnapshot) (float64, error) {
+	if before.cgroup {
+		return percentOfCPULimit(coresUsed(before.seconds, after.seconds, after.at.Sub(before.at)))
+	}
+
+	numCPUs, err := getNumCPUs()
+	if err != nil {
+		return 0, err
+	}
+	return percentOfCPULimit(busyPercent(before.times, after.times) / 100 * numCPUs)
+}
+
+// resetAdaptiveCPU discards the reads kept by GetCPUUsageAdaptive
+func resetAdaptiveCPU() {
+	adaptiveCPU.Lock()
+	defer adaptiveCPU.Unlock()
+
+	adaptiveCPU.last = cpuSnapshot{}
+	adaptiveCPU.usage = 0
+	adaptiveCPU.valid = false
+}
+
+// GetSmoothedCPUUsage samples CPU usage and returns it smoothed with an exponential
+// moving average kept across calls: avg = alpha*sample + (1-alpha)*avg. Lower
+// alpha (0 < alpha <= 1) smooths more; 1 returns the raw sample. The first call
+// returns the raw sample.
+func (tb Toolbox) GetSmoothedCPUUsage(alpha float64) (float64, error) {
+	return smoothCPUUsage(alpha, tb.GetCPUUsage)
+}
+
+// smoothCPUUsage folds a reading from read into the moving average
+func smoothCPUUsage(alpha float64, read func() (float64, error)) (float64, error) {
+	if !(alpha > 0 && alpha <= 1) {
+		return 0, errors.New("alpha must be greater than 0 and at most 1")
+	}
+
+	usage, err := read()
+	if err != nil {
+		return 0, err
+	}
+
+	smoothedCPU.Lock()
+	defer smoothedCPU.Unlock()
+
+	if !smoothedCPU.valid {
+		smoothedCPU.value = usage
+		smoothedCPU.valid = true
+	} else {
+		smoothedCPU.value = alpha*usage + (1-alpha)*smoothedCPU.value
+	}
+	return smoothedCPU.value, nil
+}
+
+// resetSmoothedCPU discards the moving average kept by GetSmoothedCPUUsage
+func resetSmoothedCPU() {
+	smoothedCPU.Lock()
+	defer smoothedCPU.Unlock()
+
+	smoothedCPU.value = 0
+	smoothedCPU.valid = false
+}
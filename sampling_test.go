@@ -0,0 +1,259 @@
+package toolbox
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSampleMemoryPeak(t *testing.T) {
+	values := []int64{100, 300, 200, 50, 250}
+	calls := 0
+	read := func() (int64, error) {
+		value := values[calls%len(values)]
+		calls++
+		return value, nil
+	}
+
+	result, err := sampleMemoryPeak(50*time.Millisecond, 10*time.Millisecond, read)
+	if err != nil {
+		t.Fatalf("sampleMemoryPeak failed: %v", err)
+	}
+
+	if result.Samples != calls {
+		t.Errorf("Expected %d samples, got %d", calls, result.Samples)
+	}
+	if result.Samples < 2 {
+		t.Errorf("Expected several samples in the window, got %d", result.Samples)
+	}
+	if result.PeakBytes != 300 {
+		t.Errorf("Expected peak 300, got %d", result.PeakBytes)
+	}
+	if result.WindowMs != 50 {
+		t.Errorf("Expected window 50ms, got %d", result.WindowMs)
+	}
+}
+
+func TestSampleMemoryPeakErrors(t *testing.T) {
+	read := func() (int64, error) { return 0, nil }
+
+	if _, err := sampleMemoryPeak(0, 10*time.Millisecond, read); err == nil {
+		t.Error("Expected error for zero window")
+	}
+	if _, err := sampleMemoryPeak(10*time.Millisecond, 20*time.Millisecond, read); err == nil {
+		t.Error("Expected error for interval larger than window")
+	}
+
+	failing := func() (int64, error) { return 0, errors.New("boom") }
+	if _, err := sampleMemoryPeak(10*time.Millisecond, 5*time.Millisecond, failing); err == nil {
+		t.Error("Expected read error to be returned")
+	}
+}
+
+func TestGetMemoryPeakOverWindow(t *testing.T) {
+	toolbox := Toolbox{}
+	result, err := toolbox.GetMemoryPeakOverWindow(30, 10)
+	if err != nil {
+		t.Logf("GetMemoryPeakOverWindow failed (expected in test environment): %v", err)
+		return
+	}
+
+	if result.PeakBytes < result.MinBytes {
+		t.Errorf("Expected peak >= min, got %d < %d", result.PeakBytes, result.MinBytes)
+	}
+
+	t.Logf("Memory peak over window: %+v", result)
+}
+
+func TestSampleCPUUsageAdaptive(t *testing.T) {
+	resetAdaptiveCPU()
+	t.Cleanup(resetAdaptiveCPU)
+
+	reads := 0
+	read := func() (cpuSnapshot, error) {
+		reads++
+		return cpuSnapshot{at: time.Now(), cgroup: true, seconds: float64(reads)}, nil
+	}
+	var pairs [][2]float64
+	percent := func(before, after cpuSnapshot) (float64, error) {
+		pairs = append(pairs, [2]float64{before.seconds, after.seconds})
+		return 25, nil
+	}
+
+	// First call needs a baseline and a second read
+	usage, err := sampleCPUUsageAdaptive(time.Minute, read, percent)
+	if err != nil {
+		t.Fatalf("sampleCPUUsageAdaptive failed: %v", err)
+	}
+	if usage != 25 {
+		t.Errorf("Expected 25%% usage, got %f", usage)
+	}
+	if reads != 2 || len(pairs) != 1 || pairs[0] != [2]float64{1, 2} {
+		t.Errorf("Expected the first sample to span reads 1 and 2, got %d reads and %v", reads, pairs)
+	}
+
+	// Within the staleness bound the last result is reused without reading
+	if _, err := sampleCPUUsageAdaptive(time.Minute, read, percent); err != nil {
+		t.Fatalf("sampleCPUUsageAdaptive failed: %v", err)
+	}
+	if reads != 2 {
+		t.Errorf("Expected no new reads within the staleness bound, got %d", reads)
+	}
+
+	// Once stale, the last read is reused as the baseline so only one read is
+	// taken, without waiting since a full sample interval has already passed
+	time.Sleep(defaultCPUSampleInterval)
+	start := time.Now()
+	if _, err := sampleCPUUsageAdaptive(0, read, percent); err != nil {
+		t.Fatalf("sampleCPUUsageAdaptive failed: %v", err)
+	}
+	if reads != 3 || pairs[len(pairs)-1] != [2]float64{2, 3} {
+		t.Errorf("Expected a single fresh read against the last one, got %d reads and %v", reads, pairs)
+	}
+	if elapsed := time.Since(start); elapsed > defaultCPUSampleInterval/2 {
+		t.Errorf("Expected the reused baseline to shorten the wait, took %v", elapsed)
+	}
+
+	// A baseline from the other counter is not compared against
+	time.Sleep(defaultCPUSampleInterval)
+	hostRead := func() (cpuSnapshot, error) {
+		reads++
+		return cpuSnapshot{at: time.Now(), seconds: float64(reads)}, nil
+	}
+	if _, err := sampleCPUUsageAdaptive(0, hostRead, percent); err != nil {
+		t.Fatalf("sampleCPUUsageAdaptive failed: %v", err)
+	}
+	if pairs[len(pairs)-1] != [2]float64{4, 5} {
+		t.Errorf("Expected a fresh baseline after the counter changed, got %v", pairs)
+	}
+}
+
+func TestSampleCPUUsageAdaptiveDoesNotBlockCachedReaders(t *testing.T) {
+	resetAdaptiveCPU()
+	t.Cleanup(resetAdaptiveCPU)
+
+	read := func() (cpuSnapshot, error) { return cpuSnapshot{at: time.Now(), cgroup: true}, nil }
+	percent := func(before, after cpuSnapshot) (float64, error) { return 10, nil }
+	if _, err := sampleCPUUsageAdaptive(time.Minute, read, percent); err != nil {
+		t.Fatalf("sampleCPUUsageAdaptive failed: %v", err)
+	}
+
+	// A caller with a tight staleness bound waits out the rest of the sample
+	// interval against the read just stored
+	started := make(chan struct{})
+	slowRead := func() (cpuSnapshot, error) {
+		select {
+		case <-started:
+		default:
+			close(started)
+		}
+		return read()
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = sampleCPUUsageAdaptive(0, slowRead, percent)
+	}()
+	<-started
+
+	// Meanwhile a caller happy with the cached result returns immediately
+	start := time.Now()
+	usage, err := sampleCPUUsageAdaptive(time.Minute, read, percent)
+	if err != nil {
+		t.Fatalf("sampleCPUUsageAdaptive failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > defaultCPUSampleInterval/2 {
+		t.Errorf("Expected the cached result without waiting for the sampler, took %v", elapsed)
+	}
+	if usage != 10 {
+		t.Errorf("Expected the cached 10%%, got %f", usage)
+	}
+	<-done
+}
+
+func TestCPUPercentBetween(t *testing.T) {
+	root := t.TempDir()
+	writeCgroupFile(t, root, "cpu.max", "200000 100000\n")
+	Configure(Options{CgroupRoot: root})
+	t.Cleanup(func() { Configure(Options{}) })
+	if !isLinux() {
+		t.Skip("cgroup fixtures are Linux only")
+	}
+
+	// One core busy for the whole interval is half of a two-core limit
+	now := time.Now()
+	before := cpuSnapshot{at: now, cgroup: true, seconds: 10}
+	after := cpuSnapshot{at: now.Add(100 * time.Millisecond), cgroup: true, seconds: 10.1}
+	usage, err := cpuPercentBetween(before, after)
+	if err != nil {
+		t.Fatalf("cpuPercentBetween failed: %v", err)
+	}
+	if usage < 49.99 || usage > 50.01 {
+		t.Errorf("Expected 50%% of the limit, got %f", usage)
+	}
+}
+
+func TestGetCPUUsageAdaptive(t *testing.T) {
+	resetAdaptiveCPU()
+	t.Cleanup(resetAdaptiveCPU)
+
+	toolbox := Toolbox{}
+	if _, err := toolbox.GetCPUUsageAdaptive(-1); err == nil {
+		t.Error("Expected error for negative staleness")
+	}
+
+	usage, err := toolbox.GetCPUUsageAdaptive(1000)
+	if err != nil {
+		t.Logf("GetCPUUsageAdaptive failed (expected in test environment): %v", err)
+		return
+	}
+	if usage < 0 || usage > 100 {
+		t.Errorf("Expected CPU usage between 0-100, got %f", usage)
+	}
+
+	t.Logf("Adaptive CPU usage: %.2f%%", usage)
+}
+
+func TestSmoothCPUUsage(t *testing.T) {
+	resetSmoothedCPU()
+	t.Cleanup(resetSmoothedCPU)
+
+	readings := []float64{40, 80, 0}
+	read := func() (float64, error) {
+		usage := readings[0]
+		readings = readings[1:]
+		return usage, nil
+	}
+
+	// The first reading seeds the average, later ones are blended in
+	for i, expected := range []float64{40, 60, 30} {
+		usage, err := smoothCPUUsage(0.5, read)
+		if err != nil {
+			t.Fatalf("smoothCPUUsage failed: %v", err)
+		}
+		if usage != expected {
+			t.Errorf("Reading %d: expected %f, got %f", i, expected, usage)
+		}
+	}
+
+	for _, alpha := range []float64{0, -0.1, 1.5} {
+		if _, err := smoothCPUUsage(alpha, read); err == nil {
+			t.Errorf("Expected error for alpha %f", alpha)
+		}
+	}
+}
+
+func TestGetSmoothedCPUUsage(t *testing.T) {
+	resetSmoothedCPU()
+	t.Cleanup(resetSmoothedCPU)
+
+	toolbox := Toolbox{}
+	usage, err := toolbox.GetSmoothedCPUUsage(0.3)
+	if err != nil {
+		t.Logf("GetSmoothedCPUUsage failed (expected in test environment): %v", err)
+		return
+	}
+	if usage < 0 {
+		t.Errorf("Expected CPU usage >= 0, got %f", usage)
+	}
+}
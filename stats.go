@@ -0,0 +1,78 @@
+package toolbox
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ResourceStats summarizes the samples buffered by a resource watch
+type ResourceStats struct {
+	Samples       int         `json:"samples"`
+	CPUPercent    StatSummary `json:"cpu_percent"`
+	MemoryPercent StatSummary `json:"memory_percent"`
+}
+
+// StatSummary holds summary statistics of a series of values
+type StatSummary struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50" js:"p50"`
+	P95  float64 `json:"p95" js:"p95"`
+	P99  float64 `json:"p99" js:"p99"`
+}
+
+// GetResourceStats returns min, max, mean and percentiles of CPU and memory
+// usage percent across all samples currently buffered by a watch
+func (Toolbox) GetResourceStats(watchID int) (ResourceStats, error) {
+	watch, err := getResourceWatch(watchID)
+	if err != nil {
+		return ResourceStats{}, err
+	}
+
+	cpu, memory := watch.usagePercents()
+	if len(cpu) == 0 {
+		return ResourceStats{}, errors.New("no samples collected yet")
+	}
+
+	return ResourceStats{
+		Samples:       len(cpu),
+		CPUPercent:    summarize(cpu),
+		MemoryPercent: summarize(memory),
+	}, nil
+}
+
+// summarize computes summary statistics of values, sorting it in place
+func summarize(values []float64) StatSummary {
+	if len(values) == 0 {
+		return StatSummary{}
+	}
+	sort.Float64s(values)
+
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+
+	return StatSummary{
+		Min:  values[0],
+		Max:  values[len(values)-1],
+		Mean: sum / float64(len(values)),
+		P50:  percentile(values, 50),
+		P95:  percentile(values, 95),
+		P99:  percentile(values, 99),
+	}
+}
+
+// percentile returns the p-th percentile of sorted values, interpolating
+// linearly between the closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,93 @@
+package toolbox
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	values := make([]float64, 0, 101)
+	// Insert 0..100 out of order to check that summarize sorts
+	for i := 100; i >= 0; i-- {
+		values = append(values, float64(i))
+	}
+
+	summary := summarize(values)
+	expected := StatSummary{Min: 0, Max: 100, Mean: 50, P50: 50, P95: 95, P99: 99}
+	if summary != expected {
+		t.Errorf("Expected %+v, got %+v", expected, summary)
+	}
+
+	if summary := summarize(nil); summary != (StatSummary{}) {
+		t.Errorf("Expected zero summary for no values, got %+v", summary)
+	}
+}
+
+func TestPercentileInterpolates(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40}
+
+	tests := []struct {
+		p        float64
+		expected float64
+	}{
+		{0, 10},
+		{50, 25},
+		{95, 38.5},
+		{100, 40},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("percentile(%v) = %v, expected %v", tt.p, got, tt.expected)
+		}
+	}
+
+	if got := percentile([]float64{7}, 99); got != 7 {
+		t.Errorf("Expected single value 7, got %v", got)
+	}
+}
+
+func TestGetResourceStats(t *testing.T) {
+	toolbox := Toolbox{}
+
+	watch := &resourceWatch{samples: make([]SystemInfo, 0, 4)}
+	for _, usage := range []float64{40, 10, 30, 20} {
+		watch.record(SystemInfo{
+			CPU:    CPUInfo{UsagePercent: usage},
+			Memory: MemoryInfo{UsagePercent: usage * 2},
+		}, nil)
+	}
+
+	resourceWatchesMu.Lock()
+	nextResourceWatchID++
+	id := nextResourceWatchID
+	resourceWatches[id] = watch
+	resourceWatchesMu.Unlock()
+	t.Cleanup(func() {
+		resourceWatchesMu.Lock()
+		delete(resourceWatches, id)
+		resourceWatchesMu.Unlock()
+	})
+
+	stats, err := toolbox.GetResourceStats(id)
+	if err != nil {
+		t.Fatalf("GetResourceStats() error: %v", err)
+	}
+	if stats.Samples != 4 {
+		t.Errorf("Expected 4 samples, got %d", stats.Samples)
+	}
+	if stats.CPUPercent.Min != 10 || stats.CPUPercent.Max != 40 || stats.CPUPercent.Mean != 25 || stats.CPUPercent.P50 != 25 {
+		t.Errorf("Unexpected CPU summary: %+v", stats.CPUPercent)
+	}
+	if stats.MemoryPercent.Min != 20 || stats.MemoryPercent.Max != 80 {
+		t.Errorf("Unexpected memory summary: %+v", stats.MemoryPercent)
+	}
+
+	// Summarizing must not reorder the watch's buffer
+	if latest, _ := watch.latest(); latest.CPU.UsagePercent != 20 {
+		t.Errorf("Expected buffer to be left untouched, latest is %v", latest.CPU.UsagePercent)
+	}
+
+	if _, err := toolbox.GetResourceStats(-1); err == nil {
+		t.Error("Expected error for unknown watch ID")
+	}
+}
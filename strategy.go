@@ -0,0 +1,288 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Collection strategies that can be listed in Options.CollectionStrategies
+const (
+	strategyCgroupV2 = "cgroup_v2"
+	strategyCgroupV1 = "cgroup_v1"
+	strategyCommand  = "command"
+	strategyProc     = "proc"
+)
+
+// strategyError combines what each collection strategy of a failed chain reported
+type strategyError []error
+
+func (e strategyError) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return ErrStrategiesFailed + ": " + strings.Join(messages, "; ")
+}
+
+func (e strategyError) Unwrap() []error {
+	return e
+}
+
+// collectionStrategies returns the configured strategy chain, nil for the default
+func collectionStrategies() []string {
+	return currentOptions().CollectionStrategies
+}
+
+// cgroupReaders reads the files of a single cgroup version, so the cgroup_v2 and
+// cgroup_v1 strategies never fall back to each other's files
+type cgroupReaders struct {
+	cpuLimit        func() (float64, error)
+	cpuUsageSeconds func() (float64, error)
+	memoryLimit     func() (int64, bool, error)
+	memoryUsage     func() (int64, error)
+	swap            func() (int64, int64, error)
+	memoryStat      string // memory.stat path under the cgroup root
+}
+
+var (
+	cgroupV2Readers = cgroupReaders{
+		cpuLimit:        readCgroupV2CPULimit,
+		cpuUsageSeconds: readCgroupV2CPUUsageSeconds,
+		memoryLimit:     readCgroupV2MemoryLimit,
+		memoryUsage:     readCgroupV2MemoryUsage,
+		swap:            readCgroupV2Swap,
+		memoryStat:      "memory.stat",
+	}
+	cgroupV1Readers = cgroupReaders{
+		cpuLimit:        readCgroupV1CPULimit,
+		cpuUsageSeconds: readCgroupV1CPUUsageSeconds,
+		memoryLimit:     readCgroupV1MemoryLimit,
+		memoryUsage:     readCgroupV1MemoryUsage,
+		swap:            readCgroupV1Swap,
+		memoryStat:      "memory/memory.stat",
+	}
+)
+
+// cpuInfo collects CPU info from this cgroup version's files only
+func (c cgroupReaders) cpuInfo() (CPUInfo, error) {
+	var info CPUInfo
+
+	limit, err := c.cpuLimit()
+	if err != nil {
+		return info, err
+	}
+	info.LimitCores = limit
+
+	before, err := c.cpuUsageSeconds()
+	if err != nil {
+		return info, err
+	}
+	usage, steal, err := sampleCgroupCPUUsage(before, c.cpuUsageSeconds, defaultCPUSampleInterval)
+	if err != nil {
+		return info, err
+	}
+	applyCgroupCPUUsage(&info, usage)
+	info.StealPercent = steal
+	applyLoadAverage(&info)
+
+	return info, nil
+}
+
+// memoryInfo collects memory info from this cgroup version's files only
+func (c cgroupReaders) memoryInfo() (MemoryInfo, error) {
+	var info MemoryInfo
+
+	limit, source, err := c.memoryLimitWithSource()
+	if err != nil {
+		return info, err
+	}
+	info.LimitBytes = limit
+	info.LimitSource = source
+	info.LimitIsSet = source == "cgroup"
+
+	usage, err := c.memoryUsage()
+	if err != nil {
+		return info, err
+	}
+	applyCgroupMemoryUsage(&info, usage)
+
+	if swapUsage, swapLimit, err := c.swap(); err == nil {
+		applySwap(&info, swapUsage, swapLimit)
+	}
+	if stat, err := readMemoryStat(c.memoryStat); err == nil {
+		info.CachedBytes, _ = cachedBytesFromStat(stat)
+	}
+
+	return info, nil
+}
+
+// memoryLimitWithSource reads this cgroup version's memory limit, see getMemoryLimitWithSource
+func (c cgroupReaders) memoryLimitWithSource() (int64, string, error) {
+	limit, unlimited, err := c.memoryLimit()
+	if err != nil {
+		return 0, "", err
+	}
+	return memoryLimitWithSource(limit, unlimited)
+}
+
+// runStrategies tries each strategy in order and returns the first result along
+// with the index of the strategy that produced it. cgroup_v2 and cgroup_v1 run
+// the cgroup collector with that version's readers, and only when the cgroup
+// root holds that version.
+func runStrategies[T any](strategies []string, cgroup func(cgroupReaders) (T, error), command, proc func() (T, error)) (T, int, error) {
+	var zero T
+	var errs strategyError
+	for i, strategy := range strategies {
+		var value T
+		var err error
+		switch strategy {
+		case strategyCgroupV2:
+			if err = requireCgroupVersion(strategy); err == nil {
+				value, err = cgroup(cgroupV2Readers)
+			}
+		case strategyCgroupV1:
+			if err = requireCgroupVersion(strategy); err == nil {
+				value, err = cgroup(cgroupV1Readers)
+			}
+		case strategyCommand:
+			value, err = command()
+		case strategyProc:
+			value, err = proc()
+		default:
+			err = fmt.Errorf("unknown collection strategy: expected %q, %q, %q or %q", strategyCgroupV2, strategyCgroupV1, strategyCommand, strategyProc)
+		}
+		if err == nil {
+			return value, i, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", strategy, err))
+	}
+	if len(errs) == 0 {
+		return zero, 0, errors.New(ErrStrategiesFailed)
+	}
+	return zero, 0, errs
+}
+
+// requireCgroupVersion fails unless the cgroup root is mounted as the hierarchy
+// strategy names. A v2 root has cgroup.controllers; a v1 or hybrid root has
+// per-controller directories instead.
+func requireCgroupVersion(strategy string) error {
+	if !isLinux() {
+		return errors.New(ErrNotSupported)
+	}
+
+	root := cgroupRoot()
+	v2 := fileExists(filepath.Join(root, "cgroup.controllers"))
+	v1 := fileExists(filepath.Join(root, "memory")) || fileExists(filepath.Join(root, "cpu")) || fileExists(filepath.Join(root, "cpuacct"))
+	switch {
+	case strategy == strategyCgroupV2 && !v2:
+		return fmt.Errorf("%s: no cgroup v2 hierarchy at %s", ErrInvalidCgroupV, root)
+	case strategy == strategyCgroupV1 && (v2 || !v1):
+		return fmt.Errorf("%s: no cgroup v1 hierarchy at %s", ErrInvalidCgroupV, root)
+	}
+	return nil
+}
+
+// collectCPUInfo returns CPU info from the configured strategy chain, or from
+// cgroup files then the host when none is configured
+func collectCPUInfo() (CPUInfo, error) {
+	if strategies := collectionStrategies(); len(strategies) > 0 {
+		info, _, err := runStrategies(strategies, cgroupReaders.cpuInfo, getCPUInfoCommand, getCPUInfoProc)
+		return info, err
+	}
+
+	if !isMacOS() {
+		if info, err := getCPUInfoCgroup(); err == nil {
+			return info, nil
+		}
+	}
+	return getCPUInfoHost()
+}
+
+// collectMemoryInfo returns memory info from the configured strategy chain, or
+// from cgroup files then the host when none is configured
+func collectMemoryInfo() (MemoryInfo, error) {
+	if strategies := collectionStrategies(); len(strategies) > 0 {
+		info, _, err := runStrategies(strategies, cgroupReaders.memoryInfo, getMemoryInfoCommand, getMemoryInfoProc)
+		return info, err
+	}
+
+	if !isMacOS() {
+		if info, err := getMemoryInfoCgroup(); err == nil {
+			return info, nil
+		}
+	}
+	return getMemoryInfoHost()
+}
+
+// collectSystemInfo collects CPU and memory together from the first strategy of
+// the chain that provides both
+func collectSystemInfo(strategies []string) (SystemInfo, error) {
+	collect := func(getCPU func() (CPUInfo, error), getMemory func() (MemoryInfo, error)) (SystemInfo, error) {
+		var info SystemInfo
+		var err error
+		if info.CPU, err = getCPU(); err != nil {
+			return info, err
+		}
+		info.Memory, err = getMemory()
+		return info, err
+	}
+
+	info, index, err := runStrategies(strategies,
+		func(cgroup cgroupReaders) (SystemInfo, error) { return collect(cgroup.cpuInfo, cgroup.memoryInfo) },
+		func() (SystemInfo, error) { return collect(getCPUInfoCommand, getMemoryInfoCommand) },
+		func() (SystemInfo, error) { return collect(getCPUInfoProc, getMemoryInfoProc) },
+	)
+	if err != nil {
+		return info, err
+	}
+	info.Method = strings.TrimSuffix(strings.TrimSuffix(strategies[index], "_v2"), "_v1")
+	info.Fallback = index > 0
+	return info, nil
+}
+
+// collectCPULimit returns the CPU limit in cores from the configured strategy
+// chain, or from cgroup files then the cpuset when none is configured
+func collectCPULimit() (float64, error) {
+	if strategies := collectionStrategies(); len(strategies) > 0 {
+		limit, _, err := runStrategies(strategies,
+			func(cgroup cgroupReaders) (float64, error) { return cgroup.cpuLimit() },
+			getCPUCoresCommand, getNumCPUs)
+		return limit, err
+	}
+
+	limit, err := getCPULimit()
+	if err != nil && isLinux() {
+		return getAvailableCPUs()
+	}
+	return limit, err
+}
+
+// collectMemoryLimit returns the memory limit in bytes and its source from the
+// configured strategy chain, or from cgroup files then system memory when none
+// is configured. The command and proc strategies report system memory.
+func collectMemoryLimit() (int64, string, error) {
+	if strategies := collectionStrategies(); len(strategies) > 0 {
+		host := func(getMemory func() (MemoryInfo, error)) func() (memoryLimit, error) {
+			return func() (memoryLimit, error) {
+				info, err := getMemory()
+				return memoryLimit{info.LimitBytes, "system"}, err
+			}
+		}
+		limit, _, err := runStrategies(strategies,
+			func(cgroup cgroupReaders) (memoryLimit, error) {
+				bytes, source, err := cgroup.memoryLimitWithSource()
+				return memoryLimit{bytes, source}, err
+			},
+			host(getMemoryInfoCommand), host(getMemoryInfoProc))
+		return limit.bytes, limit.source, err
+	}
+
+	limit, source, err := getMemoryLimitWithSource()
+	if err != nil && isLinux() {
+		limit, err = getSystemMemory()
+		return limit, "system", err
+	}
+	return limit, source, err
+}
@@ -0,0 +1,198 @@
+package toolbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunStrategies(t *testing.T) {
+	var calls []string
+	strategy := func(name string, err error) func() (string, error) {
+		return func() (string, error) {
+			calls = append(calls, name)
+			return name, err
+		}
+	}
+	cgroup := func(cgroupReaders) (string, error) {
+		calls = append(calls, "cgroup")
+		return "cgroup", nil
+	}
+	failed := errors.New("unavailable")
+
+	value, index, err := runStrategies([]string{"command", "proc"}, cgroup, strategy("command", failed), strategy("proc", nil))
+	if err != nil || value != "proc" || index != 1 {
+		t.Errorf("Expected proc after command failed, got %q at %d (err=%v)", value, index, err)
+	}
+	if strings.Join(calls, ",") != "command,proc" {
+		t.Errorf("Expected strategies tried in the configured order, got %v", calls)
+	}
+
+	_, _, err = runStrategies([]string{"command", "top"}, cgroup, strategy("command", failed), strategy("proc", nil))
+	if err == nil {
+		t.Fatal("Expected error when every strategy fails")
+	}
+	message := err.Error()
+	if !strings.HasPrefix(message, ErrStrategiesFailed) || !strings.Contains(message, "command: unavailable") || !strings.Contains(message, "top: unknown collection strategy") {
+		t.Errorf("Expected each strategy's error in the message, got %q", message)
+	}
+	if !errors.Is(err, failed) {
+		t.Error("Expected the combined error to wrap each strategy's error")
+	}
+}
+
+func TestRequireCgroupVersion(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	t.Cleanup(func() { Configure(Options{}) })
+
+	v2 := t.TempDir()
+	writeCgroupFile(t, v2, "cgroup.controllers", "cpu memory\n")
+	Configure(Options{CgroupRoot: v2})
+	if err := requireCgroupVersion(strategyCgroupV2); err != nil {
+		t.Errorf("Expected a v2 root to satisfy cgroup_v2: %v", err)
+	}
+	if err := requireCgroupVersion(strategyCgroupV1); err == nil {
+		t.Error("Expected a v2 root to fail cgroup_v1")
+	}
+
+	v1 := t.TempDir()
+	if err := os.Mkdir(filepath.Join(v1, "memory"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	Configure(Options{CgroupRoot: v1})
+	if err := requireCgroupVersion(strategyCgroupV1); err != nil {
+		t.Errorf("Expected a v1 root to satisfy cgroup_v1: %v", err)
+	}
+	if err := requireCgroupVersion(strategyCgroupV2); err == nil {
+		t.Error("Expected a v1 root to fail cgroup_v2")
+	}
+}
+
+func TestCollectionStrategies(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroup and /proc strategies are Linux only")
+	}
+	root := t.TempDir()
+	writeCgroupFile(t, root, "cgroup.controllers", "cpu memory\n")
+	writeCgroupFile(t, root, "cpu.max", "200000 100000\n")
+	writeCgroupFile(t, root, "cpu.stat", "usage_usec 1000000\n")
+	writeCgroupFile(t, root, "memory.max", "1073741824\n")
+	writeCgroupFile(t, root, "memory.current", "536870912\n")
+	t.Cleanup(func() { Configure(Options{}) })
+	useFakeRunner(t, fakeCommands)
+	toolbox := Toolbox{}
+
+	// Command first on a v2 host
+	Configure(Options{CgroupRoot: root, CollectionStrategies: []string{"command", "cgroup_v2"}})
+	info, err := toolbox.GetSystemInfo()
+	if err != nil {
+		t.Fatalf("GetSystemInfo() error: %v", err)
+	}
+	if info.Method != "command" || info.Fallback {
+		t.Errorf("Expected the first strategy to be used, got method %q (fallback %v)", info.Method, info.Fallback)
+	}
+
+	Configure(Options{CgroupRoot: root, CollectionStrategies: []string{"cgroup_v1", "cgroup_v2"}})
+	info, err = toolbox.GetSystemInfo()
+	if err != nil {
+		t.Fatalf("GetSystemInfo() error: %v", err)
+	}
+	if info.Method != "cgroup" || !info.Fallback || info.Memory.LimitBytes != 1073741824 {
+		t.Errorf("Expected cgroup_v2 after cgroup_v1 failed, got method %q (fallback %v), limit %d", info.Method, info.Fallback, info.Memory.LimitBytes)
+	}
+
+	// Without the command fallback, a broken cgroup fails loudly
+	Configure(Options{CgroupRoot: t.TempDir(), CollectionStrategies: []string{"cgroup_v2", "cgroup_v1"}})
+	_, err = toolbox.GetMemoryInfo()
+	if err == nil {
+		t.Fatal("Expected error when no configured strategy works")
+	}
+	if !strings.Contains(err.Error(), "cgroup_v2: ") || !strings.Contains(err.Error(), "cgroup_v1: ") {
+		t.Errorf("Expected both strategies in the error, got %q", err)
+	}
+	if _, err := toolbox.GetCPUUsage(); err == nil {
+		t.Error("Expected usage getters to follow the configured chain")
+	}
+}
+
+func TestCgroupStrategiesReadOnlyTheirVersion(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroups are Linux only")
+	}
+	// A v2 root that only has v1 files must not satisfy cgroup_v2
+	root := t.TempDir()
+	writeCgroupFile(t, root, "cgroup.controllers", "cpu memory\n")
+	writeCgroupFile(t, root, "cpu,cpuacct/cpu.cfs_quota_us", "200000\n")
+	writeCgroupFile(t, root, "cpu,cpuacct/cpu.cfs_period_us", "100000\n")
+	writeCgroupFile(t, root, "cpuacct/cpuacct.usage", "1000000000\n")
+	writeCgroupFile(t, root, "memory/memory.limit_in_bytes", "1073741824\n")
+	writeCgroupFile(t, root, "memory/memory.usage_in_bytes", "536870912\n")
+	Configure(Options{CgroupRoot: root, CollectionStrategies: []string{"cgroup_v2"}})
+	t.Cleanup(func() { Configure(Options{}) })
+
+	if _, err := cgroupV2Readers.memoryInfo(); err == nil {
+		t.Error("Expected the v2 readers to ignore v1 memory files")
+	}
+	if _, err := cgroupV2Readers.cpuInfo(); err == nil {
+		t.Error("Expected the v2 readers to ignore v1 CPU files")
+	}
+	if _, err := cgroupV1Readers.memoryInfo(); err != nil {
+		t.Errorf("Expected the v1 readers to read v1 files: %v", err)
+	}
+
+	toolbox := Toolbox{}
+	if _, err := toolbox.GetMemoryInfo(); err == nil {
+		t.Error("Expected cgroup_v2 to fail without v2 files")
+	}
+}
+
+func TestLimitGettersFollowCollectionStrategies(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroup and /proc strategies are Linux only")
+	}
+	root := t.TempDir()
+	writeCgroupFile(t, root, "cgroup.controllers", "cpu memory\n")
+	writeCgroupFile(t, root, "cpu.max", "200000 100000\n")
+	writeCgroupFile(t, root, "memory.max", "1073741824\n")
+	t.Cleanup(func() { Configure(Options{}) })
+	useFakeRunner(t, fakeCommands)
+	toolbox := Toolbox{}
+
+	Configure(Options{CgroupRoot: root, CollectionStrategies: []string{"cgroup_v2"}})
+	if limit, err := toolbox.GetCPULimit(); err != nil || limit != 2 {
+		t.Errorf("Expected 2 cores from cpu.max, got %f (%v)", limit, err)
+	}
+	if limit, err := toolbox.GetMemoryLimit(); err != nil || limit != 1073741824 {
+		t.Errorf("Expected the memory.max limit, got %d (%v)", limit, err)
+	}
+	if source, err := toolbox.GetMemoryLimitSource(); err != nil || source != "cgroup" {
+		t.Errorf("Expected a cgroup limit source, got %q (%v)", source, err)
+	}
+
+	Configure(Options{CgroupRoot: root, CollectionStrategies: []string{"command"}})
+	if limit, err := toolbox.GetCPULimit(); err != nil || limit != 4 {
+		t.Errorf("Expected 4 cores from nproc, got %f (%v)", limit, err)
+	}
+	if limit, err := toolbox.GetMemoryLimit(); err != nil || limit != 8000000000 {
+		t.Errorf("Expected total memory from free, got %d (%v)", limit, err)
+	}
+	if source, err := toolbox.GetMemoryLimitSource(); err != nil || source != "system" {
+		t.Errorf("Expected a system limit source, got %q (%v)", source, err)
+	}
+
+	// No silent fallback to host values when every strategy fails
+	Configure(Options{CgroupRoot: t.TempDir(), CollectionStrategies: []string{"cgroup_v2", "cgroup_v1"}})
+	if _, err := toolbox.GetCPULimit(); err == nil || !strings.HasPrefix(err.Error(), ErrStrategiesFailed) {
+		t.Errorf("Expected the combined strategy error for the CPU limit, got %v", err)
+	}
+	if _, err := toolbox.GetMemoryLimit(); err == nil || !strings.HasPrefix(err.Error(), ErrStrategiesFailed) {
+		t.Errorf("Expected the combined strategy error for the memory limit, got %v", err)
+	}
+	if _, err := toolbox.GetMemoryLimitSource(); err == nil {
+		t.Error("Expected the memory limit source to fail with the chain")
+	}
+}
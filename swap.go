@@ -0,0 +1,103 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GetSwapUsage returns swap usage in bytes: the cgroup's swap usage when swap
+// accounting is enabled, otherwise host swap usage
+func (Toolbox) GetSwapUsage() (int64, error) {
+	if usage, _, err := readCgroupSwap(); err == nil {
+		return usage, nil
+	}
+
+	memInfo, err := getMemoryInfoHost()
+	if err != nil {
+		return 0, err
+	}
+	return memInfo.SwapUsageBytes, nil
+}
+
+// readCgroupSwap reads cgroup swap usage and limit in bytes, from
+// memory.swap.* on cgroup v2 or memory.memsw.* on cgroup v1. The limit is 0
+// when swap is unlimited.
+func readCgroupSwap() (usage, limit int64, err error) {
+	if !isLinux() {
+		return 0, 0, errors.New(ErrNotSupported)
+	}
+
+	if fileExists(cgroupFile("memory.swap.current")) {
+		return readCgroupV2Swap()
+	}
+	return readCgroupV1Swap()
+}
+
+// readCgroupV2Swap reads swap usage and limit from cgroup v2 memory.swap.*
+func readCgroupV2Swap() (usage, limit int64, err error) {
+	content, err := readFile(cgroupFile("memory.swap.current"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", ErrCgroupNotFound, err)
+	}
+	usage, _, err = parseCgroupMemoryLimit(content)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+	if content, err := readFile(cgroupFile("memory.swap.max")); err == nil {
+		limit, _, err = parseCgroupMemoryLimit(content)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+	}
+	return usage, limit, nil
+}
+
+// readCgroupV1Swap derives swap usage and limit from the cgroup v1 memsw
+// counters, which cover memory plus swap
+func readCgroupV1Swap() (usage, limit int64, err error) {
+	memsw, err := readCgroupInt64(cgroupFile("memory/memory.memsw.usage_in_bytes"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", ErrCgroupNotFound, err)
+	}
+	mem, err := readCgroupInt64(cgroupFile("memory/memory.usage_in_bytes"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", ErrCgroupNotFound, err)
+	}
+	usage = max(memsw-mem, 0)
+
+	memswLimit, memswUnlimited, err := readCgroupLimit(cgroupFile("memory/memory.memsw.limit_in_bytes"))
+	if err != nil {
+		return usage, 0, nil
+	}
+	memLimit, memUnlimited, err := readCgroupLimit(cgroupFile("memory/memory.limit_in_bytes"))
+	if err != nil || memswUnlimited || memUnlimited {
+		return usage, 0, nil
+	}
+	return usage, max(memswLimit-memLimit, 0), nil
+}
+
+// readCgroupInt64 reads a single integer cgroup file
+func readCgroupInt64(path string) (int64, error) {
+	value, _, err := readCgroupLimit(path)
+	return value, err
+}
+
+// readCgroupLimit reads a cgroup file holding a single value that may be unlimited
+func readCgroupLimit(path string) (int64, bool, error) {
+	content, err := readFile(path)
+	if err != nil {
+		return 0, false, err
+	}
+	return parseCgroupMemoryLimit(content)
+}
+
+// applySwap sets the swap fields of info. The percentage is 0 when swap is
+// disabled or unlimited.
+func applySwap(info *MemoryInfo, usage, limit int64) {
+	info.SwapUsageBytes = usage
+	info.SwapLimitBytes = limit
+	info.SwapUsagePercent = 0
+	if limit > 0 {
+		info.SwapUsagePercent = (float64(usage) / float64(limit)) * 100
+	}
+}
@@ -0,0 +1,80 @@
+package toolbox
+
+import (
+	"testing"
+)
+
+func TestReadCgroupSwap(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{}) })
+
+	// cgroup v2
+	root := t.TempDir()
+	writeCgroupFile(t, root, "memory.swap.current", "1048576\n")
+	writeCgroupFile(t, root, "memory.swap.max", "4194304\n")
+	Configure(Options{CgroupRoot: root})
+
+	usage, limit, err := readCgroupSwap()
+	if err != nil {
+		t.Fatalf("readCgroupSwap failed on v2: %v", err)
+	}
+	if usage != 1048576 || limit != 4194304 {
+		t.Errorf("Expected swap 1048576/4194304, got %d/%d", usage, limit)
+	}
+
+	// cgroup v2 with unlimited swap
+	writeCgroupFile(t, root, "memory.swap.max", "max\n")
+	if _, limit, _ := readCgroupSwap(); limit != 0 {
+		t.Errorf("Expected limit 0 for unlimited swap, got %d", limit)
+	}
+
+	// cgroup v1 memsw counters include memory
+	root = t.TempDir()
+	writeCgroupFile(t, root, "memory/memory.usage_in_bytes", "3000\n")
+	writeCgroupFile(t, root, "memory/memory.memsw.usage_in_bytes", "3500\n")
+	writeCgroupFile(t, root, "memory/memory.limit_in_bytes", "4000\n")
+	writeCgroupFile(t, root, "memory/memory.memsw.limit_in_bytes", "6000\n")
+	Configure(Options{CgroupRoot: root})
+
+	usage, limit, err = readCgroupSwap()
+	if err != nil {
+		t.Fatalf("readCgroupSwap failed on v1: %v", err)
+	}
+	if usage != 500 || limit != 2000 {
+		t.Errorf("Expected swap 500/2000, got %d/%d", usage, limit)
+	}
+
+	// No swap accounting
+	Configure(Options{CgroupRoot: t.TempDir()})
+	if _, _, err := readCgroupSwap(); err == nil {
+		t.Error("Expected error without swap accounting")
+	}
+}
+
+func TestApplySwap(t *testing.T) {
+	var info MemoryInfo
+
+	applySwap(&info, 256, 1024)
+	if info.SwapUsagePercent != 25 {
+		t.Errorf("Expected 25%% swap usage, got %f", info.SwapUsagePercent)
+	}
+
+	// Unlimited or disabled swap must not divide by zero
+	applySwap(&info, 256, 0)
+	if info.SwapUsagePercent != 0 || info.SwapLimitBytes != 0 {
+		t.Errorf("Expected zero percent and limit, got %f and %d", info.SwapUsagePercent, info.SwapLimitBytes)
+	}
+}
+
+func TestGetSwapUsage(t *testing.T) {
+	toolbox := Toolbox{}
+	usage, err := toolbox.GetSwapUsage()
+	if err != nil {
+		t.Logf("GetSwapUsage failed (expected in test environment): %v", err)
+		return
+	}
+	if usage < 0 {
+		t.Errorf("Expected swap usage >= 0, got %d", usage)
+	}
+
+	t.Logf("Swap usage: %d bytes", usage)
+}
@@ -0,0 +1,175 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tcpStateNames maps /proc/net/tcp "st" values to TCP state names
+var tcpStateNames = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+	"0C": "NEW_SYN_RECV",
+}
+
+// TCPStats counts the TCP sockets of this network namespace by state
+type TCPStats struct {
+	Total  int            `json:"total"`
+	States map[string]int `json:"states"` // Keyed by state name, e.g. "ESTABLISHED" or "TIME_WAIT"; every state is present
+}
+
+// GetTCPConnectionStats counts TCP sockets by state from /proc/net/tcp and
+// /proc/net/tcp6 (Linux only). A growing TIME_WAIT count is an early sign of
+// ephemeral port exhaustion.
+func (Toolbox) GetTCPConnectionStats() (TCPStats, error) {
+	return getTCPConnectionStats()
+}
+
+// getTCPConnectionStats counts sockets across the IPv4 and IPv6 tables
+func getTCPConnectionStats() (TCPStats, error) {
+	stats := TCPStats{States: make(map[string]int, len(tcpStateNames))}
+	for _, name := range tcpStateNames {
+		stats.States[name] = 0
+	}
+
+	if !isLinux() {
+		return stats, errors.New(ErrNotSupported)
+	}
+
+	found := false
+	for _, protocol := range []string{"tcp", "tcp6"} {
+		content, err := readFile(filepath.Join("/proc/net", protocol))
+		if err != nil {
+			// tcp6 is missing when IPv6 is disabled
+			continue
+		}
+		found = true
+		countTCPStates(content, &stats)
+	}
+	if !found {
+		return stats, fmt.Errorf("%s: /proc/net/tcp", ErrReadingFile)
+	}
+	return stats, nil
+}
+
+// countTCPStates adds the sockets of a /proc/net/tcp or tcp6 table to stats
+func countTCPStates(content string, stats *TCPStats) {
+	for _, line := range strings.Split(content, "\n") {
+		// sl local_address rem_address st ...
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] == "sl" {
+			continue
+		}
+		name, ok := tcpStateNames[strings.ToUpper(fields[3])]
+		if !ok {
+			name = "UNKNOWN"
+		}
+		stats.States[name]++
+		stats.Total++
+	}
+}
+
+// PortInfo describes the ephemeral port range and how much of it is in use
+type PortInfo struct {
+	RangeStart int `json:"range_start"`
+	RangeEnd   int `json:"range_end"`
+	Range      int `json:"range"`     // Number of ports in the range
+	Used       int `json:"used"`      // Distinct local ports in the range held by non-listening TCP sockets
+	Available  int `json:"available"` // Range minus Used
+}
+
+// GetEphemeralPortInfo returns the size of the ephemeral port range from
+// /proc/sys/net/ipv4/ip_local_port_range and how many of its ports TCP sockets
+// in /proc/net/tcp and tcp6 currently use (Linux only). Available is roughly how
+// many more outgoing connections can be opened before connects fail with
+// EADDRNOTAVAIL.
+func (Toolbox) GetEphemeralPortInfo() (PortInfo, error) {
+	if !isLinux() {
+		return PortInfo{}, errors.New(ErrNotSupported)
+	}
+
+	content, err := readFile("/proc/sys/net/ipv4/ip_local_port_range")
+	if err != nil {
+		return PortInfo{}, err
+	}
+	start, end, err := parsePortRange(content)
+	if err != nil {
+		return PortInfo{}, err
+	}
+
+	used := make(map[int]struct{})
+	found := false
+	for _, protocol := range []string{"tcp", "tcp6"} {
+		content, err := readFile(filepath.Join("/proc/net", protocol))
+		if err != nil {
+			// tcp6 is missing when IPv6 is disabled
+			continue
+		}
+		found = true
+		collectEphemeralPorts(content, start, end, used)
+	}
+	if !found {
+		return PortInfo{}, fmt.Errorf("%s: /proc/net/tcp", ErrReadingFile)
+	}
+
+	return newPortInfo(start, end, len(used)), nil
+}
+
+// parsePortRange parses ip_local_port_range, e.g. "32768\t60999"
+func parsePortRange(content string) (start, end int, err error) {
+	fields := strings.Fields(content)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("invalid port range: %q", content)
+	}
+	if start, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+	if end, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("invalid port range: %q", content)
+	}
+	return start, end, nil
+}
+
+// collectEphemeralPorts adds the local ports between start and end of the
+// non-listening sockets in a /proc/net/tcp or tcp6 table to used
+func collectEphemeralPorts(content string, start, end int, used map[int]struct{}) {
+	for _, line := range strings.Split(content, "\n") {
+		// sl local_address rem_address st ...
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] == "sl" || fields[3] == tcpStateListen {
+			continue
+		}
+		_, port, err := parseProcNetAddress(fields[1])
+		if err != nil || port < start || port > end {
+			continue
+		}
+		used[port] = struct{}{}
+	}
+}
+
+// newPortInfo builds a PortInfo for the range start-end with used ports taken
+func newPortInfo(start, end, used int) PortInfo {
+	size := end - start + 1
+	return PortInfo{
+		RangeStart: start,
+		RangeEnd:   end,
+		Range:      size,
+		Used:       used,
+		Available:  max(size-used, 0),
+	}
+}
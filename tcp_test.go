@@ -0,0 +1,108 @@
+package toolbox
+
+import (
+	"net"
+	"testing"
+)
+
+func TestCountTCPStates(t *testing.T) {
+	content := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 12345 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F90 0100007F:C350 01 00000000:00000000 00:00000000 00000000     0        0 12346 1 0000000000000000 20 4 30 10 -1
+   2: 0100007F:C350 0100007F:1F90 01 00000000:00000000 00:00000000 00000000     0        0 12347 1 0000000000000000 20 4 30 10 -1
+   3: 0100007F:C351 0100007F:1F90 06 00000000:00000000 03:00001234 00000000     0        0 0 0 0000000000000000
+`
+	stats := TCPStats{States: map[string]int{}}
+	countTCPStates(content, &stats)
+
+	if stats.Total != 4 {
+		t.Errorf("Expected 4 sockets, got %d", stats.Total)
+	}
+	expected := map[string]int{"LISTEN": 1, "ESTABLISHED": 2, "TIME_WAIT": 1}
+	for state, count := range expected {
+		if stats.States[state] != count {
+			t.Errorf("Expected %d %s, got %d", count, state, stats.States[state])
+		}
+	}
+}
+
+func TestGetTCPConnectionStats(t *testing.T) {
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	toolbox := Toolbox{}
+	stats, err := toolbox.GetTCPConnectionStats()
+	if err != nil {
+		t.Logf("GetTCPConnectionStats failed (expected outside Linux): %v", err)
+		return
+	}
+
+	if stats.States["LISTEN"] < 1 {
+		t.Errorf("Expected at least one LISTEN socket, got %v", stats.States)
+	}
+	if _, ok := stats.States["TIME_WAIT"]; !ok {
+		t.Error("Expected every state to be present, TIME_WAIT missing")
+	}
+	t.Logf("TCP stats: %+v", stats)
+}
+
+func TestParsePortRange(t *testing.T) {
+	start, end, err := parsePortRange("32768\t60999\n")
+	if err != nil || start != 32768 || end != 60999 {
+		t.Errorf("Expected 32768-60999, got %d-%d (err=%v)", start, end, err)
+	}
+
+	for _, content := range []string{"", "32768", "abc 60999", "60999 32768"} {
+		if _, _, err := parsePortRange(content); err == nil {
+			t.Errorf("Expected error for %q", content)
+		}
+	}
+}
+
+func TestCollectEphemeralPorts(t *testing.T) {
+	content := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:C352 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 12345 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F90 0100007F:C350 01 00000000:00000000 00:00000000 00000000     0        0 12346 1 0000000000000000 20 4 30 10 -1
+   2: 0100007F:C350 0100007F:1F90 01 00000000:00000000 00:00000000 00000000     0        0 12347 1 0000000000000000 20 4 30 10 -1
+   3: 0100007F:C351 0100007F:1F90 06 00000000:00000000 03:00001234 00000000     0        0 0 0 0000000000000000
+   4: 0100007F:C350 0200007F:1F90 01 00000000:00000000 00:00000000 00000000     0        0 12348 1 0000000000000000 20 4 30 10 -1
+`
+	used := map[int]struct{}{}
+	collectEphemeralPorts(content, 32768, 60999, used)
+
+	// 50000 is shared by two connections to different peers, 50002 only
+	// listens and 8080 is outside the range
+	if len(used) != 2 {
+		t.Errorf("Expected ports 50000 and 50001 in use, got %v", used)
+	}
+	if _, ok := used[50001]; !ok {
+		t.Error("Expected the TIME_WAIT socket's port to count as used")
+	}
+
+	info := newPortInfo(32768, 60999, len(used))
+	if info.Range != 28232 || info.Used != 2 || info.Available != 28230 {
+		t.Errorf("Unexpected port info: %+v", info)
+	}
+	if info := newPortInfo(50000, 50000, 3); info.Available != 0 {
+		t.Errorf("Expected available ports to never go negative, got %d", info.Available)
+	}
+}
+
+func TestGetEphemeralPortInfo(t *testing.T) {
+	info, err := Toolbox{}.GetEphemeralPortInfo()
+	if !isLinux() {
+		if err == nil {
+			t.Error("Expected error on non-Linux platforms")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("GetEphemeralPortInfo() error: %v", err)
+	}
+	if info.Range <= 0 || info.Used+info.Available < info.Range {
+		t.Errorf("Unexpected port info: %+v", info)
+	}
+}
@@ -0,0 +1,86 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TimeSync describes whether the system clock is disciplined by NTP
+type TimeSync struct {
+	Synchronized bool `json:"synchronized"`
+	// Estimated offset from the reference clock, positive when the local clock is
+	// behind. 0 when Source is "timedatectl", which doesn't report it.
+	OffsetSeconds float64 `json:"offset_seconds"`
+	// Upper bound on the clock error the kernel reports, 0 unless Source is "adjtimex"
+	MaxErrorSeconds float64 `json:"max_error_seconds"`
+	Source          string  `json:"source"` // "adjtimex", "chronyc" or "timedatectl"
+}
+
+// GetTimeSyncStatus reports whether the clock is synchronized and its estimated
+// offset, from the kernel's adjtimex state on Linux, then `chronyc tracking`,
+// then `timedatectl`. Returns an ErrNotSupported error when none is available.
+func (Toolbox) GetTimeSyncStatus() (TimeSync, error) {
+	if sync, err := readAdjtimexTimeSync(); err == nil {
+		return sync, nil
+	}
+
+	if output, err := commandOutput("chronyc", "tracking"); err == nil {
+		if sync, err := parseChronycTracking(string(output)); err == nil {
+			return sync, nil
+		}
+	}
+
+	if isLinux() {
+		output, err := commandOutput("timedatectl", "show", "--property=NTPSynchronized")
+		if err == nil {
+			if _, synced, ok := parseTimedatectlOutput(string(output)); ok {
+				return TimeSync{Synchronized: synced, Source: "timedatectl"}, nil
+			}
+		}
+	}
+
+	return TimeSync{}, fmt.Errorf("%s: no time sync source available", ErrNotSupported)
+}
+
+// parseChronycTracking parses `chronyc tracking` output. The clock is
+// synchronized unless the leap status is "Not synchronised"; the offset comes
+// from the "System time" line, e.g. "0.000003291 seconds slow of NTP time".
+func parseChronycTracking(output string) (TimeSync, error) {
+	sync := TimeSync{Source: "chronyc"}
+	var foundLeap, foundOffset bool
+
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case "Leap status":
+			sync.Synchronized = value != "Not synchronised"
+			foundLeap = true
+		case "System time":
+			fields := strings.Fields(value)
+			if len(fields) < 3 {
+				return TimeSync{}, fmt.Errorf("%s: system time %q", ErrParsingValue, value)
+			}
+			offset, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil {
+				return TimeSync{}, fmt.Errorf("%s: %w", ErrParsingValue, err)
+			}
+			if fields[2] == "fast" {
+				offset = -offset
+			}
+			sync.OffsetSeconds = offset
+			foundOffset = true
+		}
+	}
+
+	if !foundLeap || !foundOffset {
+		return TimeSync{}, errors.New("leap status or system time not found in chronyc output")
+	}
+	return sync, nil
+}
@@ -0,0 +1,31 @@
+//go:build linux
+
+package toolbox
+
+import (
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestAdjtimexTimeSync(t *testing.T) {
+	sync := adjtimexTimeSync(unix.TIME_OK, unix.STA_PLL, -250, 16000)
+	if !sync.Synchronized || sync.Source != "adjtimex" {
+		t.Errorf("Expected a synchronized clock, got %+v", sync)
+	}
+	if sync.OffsetSeconds != -0.00025 || sync.MaxErrorSeconds != 0.016 {
+		t.Errorf("Expected offset -0.00025s and max error 0.016s, got %+v", sync)
+	}
+
+	// STA_NANO switches the offset to nanoseconds
+	if sync := adjtimexTimeSync(unix.TIME_OK, unix.STA_PLL|unix.STA_NANO, 500000, 0); sync.OffsetSeconds != 0.0005 {
+		t.Errorf("Expected offset 0.0005s in nanosecond mode, got %g", sync.OffsetSeconds)
+	}
+
+	if sync := adjtimexTimeSync(unix.TIME_ERROR, unix.STA_UNSYNC, 0, 16000000); sync.Synchronized {
+		t.Error("Expected TIME_ERROR with STA_UNSYNC to be unsynchronized")
+	}
+	if sync := adjtimexTimeSync(unix.TIME_OK, unix.STA_UNSYNC, 0, 0); sync.Synchronized {
+		t.Error("Expected STA_UNSYNC alone to be unsynchronized")
+	}
+}
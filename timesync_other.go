@@ -0,0 +1,11 @@
+//go:build !linux
+
+package toolbox
+
+import "errors"
+
+// readAdjtimexTimeSync is only implemented on Linux; other platforms fall back
+// to chronyc
+func readAdjtimexTimeSync() (TimeSync, error) {
+	return TimeSync{}, errors.New(ErrNotSupported)
+}
@@ -0,0 +1,63 @@
+package toolbox
+
+import (
+	"testing"
+)
+
+const chronycTrackingFixture = `Reference ID    : A9FEA97B (169.254.169.123)
+Stratum         : 4
+Ref time (UTC)  : Tue Oct 14 09:12:45 2025
+System time     : 0.000003291 seconds slow of NTP time
+Last offset     : -0.000002100 seconds
+RMS offset      : 0.000011452 seconds
+Frequency       : 12.345 ppm fast
+Root delay      : 0.000432 seconds
+Root dispersion : 0.000275 seconds
+Update interval : 16.1 seconds
+Leap status     : Normal
+`
+
+func TestParseChronycTracking(t *testing.T) {
+	sync, err := parseChronycTracking(chronycTrackingFixture)
+	if err != nil {
+		t.Fatalf("parseChronycTracking failed: %v", err)
+	}
+	if !sync.Synchronized || sync.Source != "chronyc" {
+		t.Errorf("Expected a synchronized chronyc clock, got %+v", sync)
+	}
+	// Slow of NTP time means the local clock is behind
+	if sync.OffsetSeconds != 0.000003291 {
+		t.Errorf("Expected offset 0.000003291, got %g", sync.OffsetSeconds)
+	}
+
+	sync, err = parseChronycTracking("System time     : 1.500000000 seconds fast of NTP time\nLeap status     : Not synchronised\n")
+	if err != nil {
+		t.Fatalf("parseChronycTracking failed: %v", err)
+	}
+	if sync.Synchronized || sync.OffsetSeconds != -1.5 {
+		t.Errorf("Expected an unsynchronized clock 1.5s ahead, got %+v", sync)
+	}
+
+	if _, err := parseChronycTracking("506 Cannot talk to daemon\n"); err == nil {
+		t.Error("Expected error when chronyd is not running")
+	}
+	if _, err := parseChronycTracking("System time     : abc seconds slow of NTP time\nLeap status     : Normal\n"); err == nil {
+		t.Error("Expected error for an invalid offset")
+	}
+}
+
+func TestGetTimeSyncStatus(t *testing.T) {
+	sync, err := Toolbox{}.GetTimeSyncStatus()
+	if err != nil {
+		t.Logf("GetTimeSyncStatus failed (expected without a time sync source): %v", err)
+		return
+	}
+	if sync.Source == "" {
+		t.Error("Expected the source to be reported")
+	}
+	if sync.MaxErrorSeconds < 0 {
+		t.Errorf("Expected a non-negative max error, got %f", sync.MaxErrorSeconds)
+	}
+
+	t.Logf("Time sync: %+v", sync)
+}
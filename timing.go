@@ -0,0 +1,177 @@
+package toolbox
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+// HTTPTiming breaks down where the time of one HTTP request went. Phases that
+// didn't happen, such as TLS for plain HTTP, are 0.
+type HTTPTiming struct {
+	URL        string  `json:"url" js:"url"`
+	StatusCode int     `json:"status_code"`
+	DNSMs      float64 `json:"dns_ms" js:"dns_ms"`   // Resolving the host name
+	ConnectMs  float64 `json:"connect_ms"`           // Establishing the TCP connection
+	TLSMs      float64 `json:"tls_ms" js:"tls_ms"`   // TLS handshake
+	TTFBMs     float64 `json:"ttfb_ms" js:"ttfb_ms"` // From the start until the first response byte
+	ServerMs   float64 `json:"server_ms"`            // From sending the request until the first response byte
+	TotalMs    float64 `json:"total_ms"`             // From the start until the body was read
+}
+
+// TCPPingResult summarizes repeated TCP connects to one address. Times are of
+// the successful connects only.
+type TCPPingResult struct {
+	Host         string  `json:"host"`
+	Port         string  `json:"port"`
+	Address      string  `json:"address"` // Resolved IP that was dialed
+	Count        int     `json:"count"`
+	Successes    int     `json:"successes"`
+	SuccessRatio float64 `json:"success_ratio"` // Successes / Count, from 0 to 1
+	MinMs        float64 `json:"min_ms"`
+	AvgMs        float64 `json:"avg_ms"`
+	MaxMs        float64 `json:"max_ms"`
+	LastError    string  `json:"last_error"` // Error of the last failed connect, empty if all succeeded
+}
+
+// maxTCPPingCount bounds how many connects TCPPing makes in one call
+const maxTCPPingCount = 1000
+
+// MeasureHTTPTiming sends a GET request to url on a new connection and returns
+// how long DNS, TCP connect, TLS, the first response byte and the whole
+// response took. timeoutSeconds: limit on the whole request (default 5 if <=0)
+func (Toolbox) MeasureHTTPTiming(url string, timeoutSeconds int) (HTTPTiming, error) {
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = 5
+	}
+	return measureHTTPTiming(url, time.Duration(timeoutSeconds)*time.Second)
+}
+
+// measureHTTPTiming implements MeasureHTTPTiming using an httptrace.ClientTrace
+func measureHTTPTiming(url string, timeout time.Duration) (HTTPTiming, error) {
+	timing := HTTPTiming{URL: url}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var start, dnsStart, connectStart, tlsStart, wroteRequest time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			timing.DNSMs = elapsedMs(dnsStart)
+		},
+		ConnectStart: func(string, string) { connectStart = time.Now() },
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				timing.ConnectMs = elapsedMs(connectStart)
+			}
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				timing.TLSMs = elapsedMs(tlsStart)
+			}
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) { wroteRequest = time.Now() },
+		GotFirstResponseByte: func() {
+			timing.TTFBMs = elapsedMs(start)
+			if !wroteRequest.IsZero() {
+				timing.ServerMs = elapsedMs(wroteRequest)
+			}
+		},
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, url, nil)
+	if err != nil {
+		return timing, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// A dedicated transport without keep-alives makes every measurement pay for
+	// DNS, connect and TLS instead of reusing a pooled connection
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	defer transport.CloseIdleConnections()
+
+	start = time.Now()
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return timing, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	timing.StatusCode = resp.StatusCode
+
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return timing, fmt.Errorf("failed to read response body: %w", err)
+	}
+	timing.TotalMs = elapsedMs(start)
+	return timing, nil
+}
+
+// elapsedMs returns the time since start in milliseconds
+func elapsedMs(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
+
+// TCPPing resolves host once and then opens and immediately closes count TCP
+// connections to it, back to back, measuring each connect. It works where ICMP
+// is blocked. An empty port and timeoutSeconds <=0 (per connect) use the
+// configured connectivity defaults. Failed connects lower the success ratio
+// rather than failing the call; only an invalid count or a failed lookup do.
+func (Toolbox) TCPPing(host string, port string, count int, timeoutSeconds int) (TCPPingResult, error) {
+	if port == "" {
+		port = defaultConnectivityPort()
+	}
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = defaultConnectivityTimeoutSeconds()
+	}
+	return tcpPing(host, port, count, time.Duration(timeoutSeconds)*time.Second)
+}
+
+// tcpPing implements TCPPing
+func tcpPing(host, port string, count int, timeout time.Duration) (TCPPingResult, error) {
+	result := TCPPingResult{Host: host, Port: port, Count: count}
+
+	if count <= 0 || count > maxTCPPingCount {
+		return result, fmt.Errorf("count must be between 1 and %d", maxTCPPingCount)
+	}
+	if host == "" {
+		return result, errors.New("host must not be empty")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	cancel()
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	result.Address = ips[0].String()
+	address := net.JoinHostPort(result.Address, port)
+
+	dialer := net.Dialer{Timeout: timeout}
+	latencies := make([]float64, 0, count)
+	for range count {
+		start := time.Now()
+		conn, err := dialer.Dial("tcp", address)
+		if err != nil {
+			result.LastError = err.Error()
+			continue
+		}
+		latencies = append(latencies, elapsedMs(start))
+		conn.Close()
+	}
+
+	summary := summarize(latencies)
+	result.Successes = len(latencies)
+	result.SuccessRatio = float64(result.Successes) / float64(count)
+	result.MinMs = summary.Min
+	result.AvgMs = summary.Mean
+	result.MaxMs = summary.Max
+	return result, nil
+}
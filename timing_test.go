@@ -0,0 +1,113 @@
+package toolbox
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMeasureHTTPTiming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("body"))
+	}))
+	t.Cleanup(server.Close)
+
+	toolbox := Toolbox{}
+	timing, err := toolbox.MeasureHTTPTiming(server.URL, 2)
+	if err != nil {
+		t.Fatalf("MeasureHTTPTiming failed: %v", err)
+	}
+	if timing.StatusCode != http.StatusTeapot {
+		t.Errorf("Expected status 418, got %d", timing.StatusCode)
+	}
+	if timing.ConnectMs <= 0 {
+		t.Errorf("Expected a TCP connect time, got %f", timing.ConnectMs)
+	}
+	if timing.TLSMs != 0 {
+		t.Errorf("Expected no TLS time for plain HTTP, got %f", timing.TLSMs)
+	}
+	if timing.ServerMs < 20 || timing.TTFBMs < timing.ServerMs || timing.TotalMs < timing.TTFBMs {
+		t.Errorf("Expected server >= 20ms <= ttfb <= total, got %+v", timing)
+	}
+
+	t.Logf("HTTP timing: %+v", timing)
+}
+
+func TestMeasureHTTPTimingTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	// The test server's certificate isn't trusted, so the TLS handshake fails
+	toolbox := Toolbox{}
+	if _, err := toolbox.MeasureHTTPTiming(server.URL, 2); err == nil {
+		t.Error("Expected error for an untrusted certificate")
+	}
+}
+
+func TestMeasureHTTPTimingErrors(t *testing.T) {
+	toolbox := Toolbox{}
+	if _, err := toolbox.MeasureHTTPTiming("://bad", 1); err == nil {
+		t.Error("Expected error for an invalid URL")
+	}
+	if _, err := toolbox.MeasureHTTPTiming("http://127.0.0.1:1/", 1); err == nil {
+		t.Error("Expected error for a refused connection")
+	}
+}
+
+func TestTCPPing(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to open listener: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	result, err := Toolbox{}.TCPPing("127.0.0.1", port, 5, 2)
+	if err != nil {
+		t.Fatalf("TCPPing() error: %v", err)
+	}
+	if result.Successes != 5 || result.SuccessRatio != 1 || result.LastError != "" {
+		t.Errorf("Expected 5 successful connects, got %+v", result)
+	}
+	if result.MinMs <= 0 || result.MinMs > result.AvgMs || result.AvgMs > result.MaxMs {
+		t.Errorf("Expected 0 < min <= avg <= max, got %+v", result)
+	}
+}
+
+func TestTCPPingFailures(t *testing.T) {
+	// Grab a free port and close it so nothing is listening
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to open listener: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	result, err := tcpPing("127.0.0.1", port, 3, time.Second)
+	if err != nil {
+		t.Fatalf("Expected refused connects to be counted, not returned: %v", err)
+	}
+	if result.Successes != 0 || result.SuccessRatio != 0 || result.LastError == "" {
+		t.Errorf("Expected 3 failed connects, got %+v", result)
+	}
+
+	if _, err := tcpPing("127.0.0.1", port, 0, time.Second); err == nil {
+		t.Error("Expected error for zero count")
+	}
+	if _, err := tcpPing("", port, 1, time.Second); err == nil {
+		t.Error("Expected error for an empty host")
+	}
+}
@@ -1,13 +1,9 @@
 package toolbox
 
 import (
-	"context"
 	"errors"
 	"fmt"
-	"net"
-	"net/http"
 	"os"
-	"os/exec"
 	"regexp"
 	"runtime"
 	"strconv"
@@ -19,22 +15,29 @@ import (
 
 // Error messages
 const (
-	ErrReadingFile     = "failed to read file"
-	ErrParsingValue    = "failed to parse value"
-	ErrCgroupNotFound  = "cgroup information not found"
-	ErrMemoryNotFound  = "memory information not found"
-	ErrCPUNotFound     = "CPU information not found"
-	ErrInvalidCgroupV  = "unsupported cgroup version"
-	ErrCommandFailed   = "command execution failed"
-	ErrCommandNotFound = "command not found"
+	ErrReadingFile       = "failed to read file"
+	ErrParsingValue      = "failed to parse value"
+	ErrCgroupNotFound    = "cgroup information not found"
+	ErrMemoryNotFound    = "memory information not found"
+	ErrCPUNotFound       = "CPU information not found"
+	ErrInvalidCgroupV    = "unsupported cgroup version"
+	ErrCommandFailed     = "command execution failed"
+	ErrCommandNotFound   = "command not found"
+	ErrCommandNotAllowed = "command not allowed"
+	ErrNotSupported      = "not supported on this platform"
+	ErrContainerNotFound = "container ID not found"
+	ErrStrategiesFailed  = "all collection strategies failed"
 )
 
 // SystemInfo represents the current system resource information
 type SystemInfo struct {
 	CPU      CPUInfo    `json:"cpu"`
 	Memory   MemoryInfo `json:"memory"`
-	Method   string     `json:"method"`   // How the data was collected
+	Method   string     `json:"method"`   // How the data was collected: "cgroup", "command" or "proc"
 	Fallback bool       `json:"fallback"` // Whether fallback methods were used
+	// TimestampMs is the Unix time in milliseconds the sample was started, set
+	// by CollectSamples and 0 otherwise
+	TimestampMs int64 `json:"timestamp_ms" js:"timestamp_ms"`
 }
 
 // CPUInfo contains CPU usage and limit information
@@ -42,8 +45,15 @@ type CPUInfo struct {
 	UsagePercent float64 `json:"usage_percent"`
 	LimitCores   float64 `json:"limit_cores"`
 	UsedCores    float64 `json:"used_cores"`
-	Available    float64 `json:"available_cores"`
-	LoadAverage  string  `json:"load_average"`
+	Available    float64 `json:"available_cores" js:"available_cores"`
+	LoadAverage  string  `json:"load_average"` // e.g. "0.52, 0.58, 0.59", kept for compatibility
+	// StealPercent is the share of host CPU time stolen by the hypervisor during
+	// the usage sample, from /proc/stat; 0 where it isn't available (macOS)
+	StealPercent float64     `json:"steal_percent"`
+	Load         LoadAverage `json:"load"`
+	// Clamped is true when usage exceeded the limit, as it briefly can under
+	// cgroup accounting lag, and UsagePercent was capped at 100 and Available at 0
+	Clamped bool `json:"clamped"`
 }
 
 // MemoryInfo contains memory usage and limit information
@@ -52,35 +62,46 @@ type MemoryInfo struct {
 	LimitBytes     int64   `json:"limit_bytes"`
 	AvailableBytes int64   `json:"available_bytes"`
 	UsagePercent   float64 `json:"usage_percent"`
-	UsageMB        float64 `json:"usage_mb"`
-	LimitMB        float64 `json:"limit_mb"`
-	AvailableMB    float64 `json:"available_mb"`
+	UsageMB        float64 `json:"usage_mb" js:"usage_mb"` // MiB (1024*1024 bytes) despite the name, as are LimitMB and AvailableMB, whatever SetMemoryUnit is set to
+	LimitMB        float64 `json:"limit_mb" js:"limit_mb"`
+	AvailableMB    float64 `json:"available_mb" js:"available_mb"`
 	FreeBytes      int64   `json:"free_bytes"`
 	BufferBytes    int64   `json:"buffer_bytes"`
 	CachedBytes    int64   `json:"cached_bytes"`
-}
-
-// ConnectivityReport represents the result of connectivity checks at different layers
-type ConnectivityReport struct {
-	Domain         string `json:"domain"`
-	Port           string `json:"port"`
-	TimeoutSeconds int    `json:"timeout_seconds"`
-	TCP            string `json:"tcp"`  // e.g. "success" or error message
-	HTTP           string `json:"http"` // e.g. "success" or error message
+	Unit           string  `json:"unit"`      // Unit of Usage, Limit and Available (see SetMemoryUnit), the only fields that follow it
+	Usage          float64 `json:"usage"`     // UsageBytes in Unit
+	Limit          float64 `json:"limit"`     // LimitBytes in Unit
+	Available      float64 `json:"available"` // AvailableBytes in Unit
+	// Swap is zero when disabled; the limit and percent are zero when swap is unlimited
+	SwapUsageBytes   int64   `json:"swap_usage_bytes"`
+	SwapLimitBytes   int64   `json:"swap_limit_bytes"`
+	SwapUsagePercent float64 `json:"swap_usage_percent"`
+	// LimitSource is "cgroup" when LimitBytes is a container limit, or "system"
+	// when there is none and LimitBytes is the host's total memory
+	LimitSource string `json:"limit_source"`
+	LimitIsSet  bool   `json:"limit_is_set"` // Whether a cgroup memory limit applies
+	// Clamped is true when usage exceeded the limit, as it briefly can under
+	// cgroup accounting lag, and UsagePercent was capped at 100 and AvailableBytes at 0
+	Clamped bool `json:"clamped"`
 }
 
 func init() {
-	modules.Register("k6/x/toolbox", new(Toolbox))
+	modules.Register("k6/x/toolbox", New())
 }
 
 // Toolbox is the main module exposed to k6 JavaScript.
 // It provides functions for monitoring system resources in containerized environments.
-type Toolbox struct{}
+// Each VU gets its own Toolbox holding the VU and its memory unit, but module state such as options
+// and caches is shared by all VUs, so it must be guarded by a lock.
+type Toolbox struct {
+	vu         modules.VU      // nil outside a k6 VU, e.g. in unit tests
+	metrics    *toolboxMetrics // Custom metrics written by RecordMetrics, nil with vu
+	memoryUnit string          // Set by SetMemoryUnit, empty for bytes
+}
 
 // GetPsOutput returns raw output from the `ps` command
 func (Toolbox) GetPsOutput() (string, error) {
-	cmd := exec.Command("ps", "aux")
-	output, err := cmd.Output()
+	output, err := commandOutput("ps", "aux")
 	if err != nil {
 		return "", fmt.Errorf("%s: %w", ErrCommandFailed, err)
 	}
@@ -89,100 +110,202 @@ func (Toolbox) GetPsOutput() (string, error) {
 
 // GetUptimeOutput returns raw output from the `uptime` command
 func (Toolbox) GetUptimeOutput() (string, error) {
-	cmd := exec.Command("uptime")
-	output, err := cmd.Output()
+	output, err := commandOutput("uptime")
 	if err != nil {
 		return "", fmt.Errorf("%s: %w", ErrCommandFailed, err)
 	}
 	return string(output), nil
 }
 
+// GetSystemInfo returns CPU and memory information collected together in one pass
+func (tb Toolbox) GetSystemInfo() (SystemInfo, error) {
+	info, err := getSystemInfo()
+	return tb.inMemoryUnit(info), err
+}
+
+// getSystemInfo reads CPU and memory from cgroup files, falling back to /proc
+// and then system commands on Linux, or to commands alone elsewhere. Both come
+// from the same source.
+func getSystemInfo() (SystemInfo, error) {
+	if strategies := collectionStrategies(); len(strategies) > 0 {
+		return collectSystemInfo(strategies)
+	}
+
+	var info SystemInfo
+
+	if !isMacOS() {
+		cpuInfo, cpuErr := getCPUInfoCgroup()
+		memInfo, memErr := getMemoryInfoCgroup()
+		if cpuErr == nil && memErr == nil {
+			info.CPU, info.Memory, info.Method = cpuInfo, memInfo, "cgroup"
+			return info, nil
+		}
+	}
+
+	// Commands are the primary method on macOS
+	info.Fallback = !isMacOS()
+
+	// /proc holds everything top and free report on Linux, without a subprocess
+	if isLinux() {
+		cpuInfo, cpuErr := getCPUInfoProc()
+		memInfo, memErr := getMemoryInfoProc()
+		if cpuErr == nil && memErr == nil {
+			info.CPU, info.Memory, info.Method = cpuInfo, memInfo, "proc"
+			return info, nil
+		}
+	}
+
+	cpuInfo, err := getCPUInfoCommand()
+	if err != nil {
+		return info, err
+	}
+	memInfo, err := getMemoryInfoCommand()
+	if err != nil {
+		return info, err
+	}
+	info.CPU, info.Memory, info.Method = cpuInfo, memInfo, "command"
+	return info, nil
+}
+
+// GetSystemInfoWithMethod collects SystemInfo using only the given method instead
+// of the automatic fallback chain. method: "cgroup", "command", "proc" or "auto"
+// (the same as GetSystemInfo).
+func (tb Toolbox) GetSystemInfoWithMethod(method string) (SystemInfo, error) {
+	info, err := getSystemInfoWithMethod(method)
+	return tb.inMemoryUnit(info), err
+}
+
+// getSystemInfoWithMethod collects CPU and memory info from a single source
+func getSystemInfoWithMethod(method string) (SystemInfo, error) {
+	info := SystemInfo{Method: method}
+
+	var getCPU func() (CPUInfo, error)
+	var getMemory func() (MemoryInfo, error)
+	switch method {
+	case "auto", "":
+		return getSystemInfo()
+	case "cgroup":
+		if isMacOS() {
+			return info, fmt.Errorf("%s: cgroups are not available on macOS", ErrNotSupported)
+		}
+		getCPU, getMemory = getCPUInfoCgroup, getMemoryInfoCgroup
+	case "command":
+		getCPU, getMemory = getCPUInfoCommand, getMemoryInfoCommand
+	case "proc":
+		if !isLinux() {
+			return info, fmt.Errorf("%s: /proc is only read on Linux", ErrNotSupported)
+		}
+		getCPU, getMemory = getCPUInfoProc, getMemoryInfoProc
+	default:
+		return info, fmt.Errorf("unknown method %q: expected \"cgroup\", \"command\", \"proc\" or \"auto\"", method)
+	}
+
+	var err error
+	if info.CPU, err = getCPU(); err != nil {
+		return info, err
+	}
+	if info.Memory, err = getMemory(); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+// GetCPUInfo returns the full CPU info from cgroup files, falling back to
+// /proc and then system commands, or from the configured collection strategies
+func (Toolbox) GetCPUInfo() (CPUInfo, error) {
+	return collectCPUInfo()
+}
+
+// GetMemoryInfo returns the full memory info from cgroup files, falling back to
+// /proc and then system commands, or from the configured collection strategies
+func (tb Toolbox) GetMemoryInfo() (MemoryInfo, error) {
+	info, err := collectMemoryInfo()
+	applyMemoryUnit(&info, tb.GetMemoryUnit())
+	return info, err
+}
+
 // GetCPUUsage returns current CPU usage percentage
 func (Toolbox) GetCPUUsage() (float64, error) {
+	cpuInfo, err := collectCPUInfo()
+	if err != nil {
+		return 0, err
+	}
+	return cpuInfo.UsagePercent, nil
+}
+
+// GetCPUUsageOverInterval samples CPU usage over ms milliseconds and returns it as a
+// percentage of the CPU limit (or of the host core count when there is no limit)
+func (Toolbox) GetCPUUsageOverInterval(ms int) (float64, error) {
+	if ms <= 0 {
+		return 0, errors.New("interval must be greater than 0")
+	}
 	if isMacOS() {
 		cpuInfo, err := getCPUInfoCommand()
 		if err != nil {
 			return 0, err
 		}
-		if cpuInfo.UsagePercent < 0 || cpuInfo.UsagePercent > 100 {
-			return 0, errors.New("invalid CPU usage percent")
-		}
 		return cpuInfo.UsagePercent, nil
 	}
-	cpuInfo, err := getCPUInfoCgroup()
-	if err != nil {
-		cpuInfo, err = getCPUInfoCommand()
-		if err != nil {
-			return 0, err
-		}
-	}
-	return cpuInfo.UsagePercent, nil
+	return sampleCPUUsagePercent(time.Duration(ms) * time.Millisecond)
 }
 
-// GetCPULimit returns the CPU limit in cores
+// GetCPULimit returns the CPU limit in cores, or the number of CPUs the cpuset allows
+// (the host core count without one) when no cgroup limit is readable. With
+// collection strategies configured, only those are tried.
 func (Toolbox) GetCPULimit() (float64, error) {
-	return getCPULimit()
+	return collectCPULimit()
 }
 
 // GetMemoryUsage returns current memory usage in bytes
 func (Toolbox) GetMemoryUsage() (int64, error) {
-	memInfo, err := getMemoryInfoCgroup()
+	memInfo, err := collectMemoryInfo()
 	if err != nil {
-		memInfo, err = getMemoryInfoCommand()
-		if err != nil {
-			return 0, err
-		}
+		return 0, err
 	}
 	return memInfo.UsageBytes, nil
 }
 
-// GetMemoryLimit returns the memory limit in bytes
+// GetMemoryLimit returns the memory limit in bytes, or total host memory when
+// no cgroup limit is readable. With collection strategies configured, only
+// those are tried.
 func (Toolbox) GetMemoryLimit() (int64, error) {
-	return getMemoryLimit()
+	limit, _, err := collectMemoryLimit()
+	if err != nil {
+		return 0, err
+	}
+	return limit, nil
+}
+
+// GetMemoryLimitSource returns "cgroup" when a container memory limit is set, or
+// "system" when GetMemoryLimit reports total host memory instead
+func (Toolbox) GetMemoryLimitSource() (string, error) {
+	_, source, err := collectMemoryLimit()
+	return source, err
 }
 
 // GetMemoryUsagePercent returns memory usage as a percentage
 func (Toolbox) GetMemoryUsagePercent() (float64, error) {
-	if isMacOS() {
-		memInfo, err := getMemoryInfoCommand()
-		if err != nil {
-			return 0, err
-		}
-		if memInfo.UsagePercent < 0 || memInfo.UsagePercent > 100 {
-			return 0, errors.New("invalid memory usage percent")
-		}
-		return memInfo.UsagePercent, nil
-	}
-	memInfo, err := getMemoryInfoCgroup()
+	memInfo, err := collectMemoryInfo()
 	if err != nil {
-		memInfo, err = getMemoryInfoCommand()
-		if err != nil {
-			return 0, err
-		}
+		return 0, err
 	}
 	return memInfo.UsagePercent, nil
 }
 
 // GetAvailableMemory returns available memory in bytes
 func (Toolbox) GetAvailableMemory() (int64, error) {
-	memInfo, err := getMemoryInfoCgroup()
+	memInfo, err := collectMemoryInfo()
 	if err != nil {
-		memInfo, err = getMemoryInfoCommand()
-		if err != nil {
-			return 0, err
-		}
+		return 0, err
 	}
 	return memInfo.AvailableBytes, nil
 }
 
 // GetAvailableCPU returns available CPU cores
 func (Toolbox) GetAvailableCPU() (float64, error) {
-	cpuInfo, err := getCPUInfoCgroup()
+	cpuInfo, err := collectCPUInfo()
 	if err != nil {
-		cpuInfo, err = getCPUInfoCommand()
-		if err != nil {
-			return 0, err
-		}
+		return 0, err
 	}
 	return cpuInfo.Available, nil
 }
@@ -218,10 +341,7 @@ func getCPUInfoCommand() (CPUInfo, error) {
 		info.UsedCores = (usage / 100.0) * cores
 		info.Available = cores - info.UsedCores
 
-		loadAvg, err := getLoadAverage()
-		if err == nil {
-			info.LoadAverage = loadAvg
-		}
+		applyLoadAverage(&info)
 		// Defensive: ensure all fields are set
 		if info.UsagePercent < 0 || info.UsagePercent > 100 {
 			return info, errors.New("invalid CPU usage percent")
@@ -239,18 +359,20 @@ func getCPUInfoCommand() (CPUInfo, error) {
 	}
 	info.LimitCores = cores
 
-	usage, err := getCPUUsageFromTop()
+	// /proc/stat deltas are cheaper and as accurate as top, which is the fallback
+	usage, steal, err := sampleProcStatCPUPercent(defaultCPUSampleInterval)
 	if err != nil {
-		return info, err
+		usage, err = getCPUUsageFromTop()
+		if err != nil {
+			return info, err
+		}
 	}
 	info.UsagePercent = usage
+	info.StealPercent = steal
 	info.UsedCores = (usage / 100.0) * cores
 	info.Available = cores - info.UsedCores
 
-	loadAvg, err := getLoadAverage()
-	if err == nil {
-		info.LoadAverage = loadAvg
-	}
+	applyLoadAverage(&info)
 
 	return info, nil
 }
@@ -261,7 +383,7 @@ func getMemoryInfoCommand() (MemoryInfo, error) {
 
 	if isMacOS() {
 		// macOS: use vm_stat and sysctl
-		output, err := exec.Command("vm_stat").Output()
+		output, err := commandOutput("vm_stat")
 		if err != nil {
 			return info, fmt.Errorf("%s: %w", ErrCommandFailed, err)
 		}
@@ -269,6 +391,7 @@ func getMemoryInfoCommand() (MemoryInfo, error) {
 		if err != nil {
 			return info, err
 		}
+		applyMemoryUnit(&info, defaultMemoryUnit)
 		// Defensive: ensure all fields are set
 		if info.UsagePercent < 0 || info.UsagePercent > 100 {
 			return info, errors.New("invalid memory usage percent")
@@ -280,18 +403,24 @@ func getMemoryInfoCommand() (MemoryInfo, error) {
 	}
 
 	// Linux (default):
-	output, err := exec.Command("free", "-b").Output()
+	output, err := commandOutput("free", "-b")
 	if err != nil {
 		return info, fmt.Errorf("%s: %w", ErrCommandFailed, err)
 	}
 
-	return parseFreeCmdOutput(string(output))
+	info, err = parseFreeCmdOutput(string(output))
+	if err != nil {
+		return info, err
+	}
+	applyMemoryUnit(&info, defaultMemoryUnit)
+
+	return info, nil
 }
 
 // getCPUCoresCommand gets number of CPU cores
 func getCPUCoresCommand() (float64, error) {
 	if isMacOS() {
-		output, err := exec.Command("sysctl", "-n", "hw.ncpu").Output()
+		output, err := commandOutput("sysctl", "-n", "hw.ncpu")
 		if err != nil {
 			return 0, fmt.Errorf("%s: %w", ErrCommandFailed, err)
 		}
@@ -302,7 +431,7 @@ func getCPUCoresCommand() (float64, error) {
 		return cores, nil
 	}
 	// Linux (default):
-	output, err := exec.Command("nproc").Output()
+	output, err := commandOutput("nproc")
 	if err != nil {
 		// Fallback to parsing /proc/cpuinfo
 		return getCPUCoresFromProcInfo()
@@ -318,78 +447,65 @@ func getCPUCoresCommand() (float64, error) {
 func getCPUUsageFromTop() (float64, error) {
 	if isMacOS() {
 		// macOS: top -l 1 | grep 'CPU usage'
-		cmd := exec.Command("sh", "-c", "top -l 1 | grep 'CPU usage'")
-		output, err := cmd.Output()
+		output, err := commandOutput("sh", "-c", "top -l 1 | grep 'CPU usage'")
 		if err != nil {
 			return 0, fmt.Errorf("%s: %w", ErrCommandFailed, err)
 		}
 		return parseTopCPUUsage(string(output))
 	}
-	// Linux (default):
-	cmd := exec.Command("top", "-b", "-n", "1")
-	output, err := cmd.Output()
+	// Linux (default): the first iteration of top reports usage since boot,
+	// so take a second one half a second later
+	output, err := commandOutput("top", "-b", "-n", "2", "-d", "0.5")
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", ErrCommandFailed, err)
 	}
 	return parseTopCPUUsage(string(output))
 }
 
-// parseTopCPUUsage extracts CPU usage from top output
-func parseTopCPUUsage(output string) (float64, error) {
-	lines := strings.Split(output, "\n")
+// topIdlePatterns capture the idle percentage from the CPU summary line of the
+// top variants we run into, tried in order on each line
+var topIdlePatterns = []*regexp.Regexp{
+	// procps: "%Cpu(s):  2.3 us,  1.0 sy, ... 96.5 id, ..." or older "Cpu(s):  2.3%us, ... 96.5%id"
+	regexp.MustCompile(`Cpu\(s\):.*?` + topNumberPattern + `\s*%?\s*id\b`),
+	// BusyBox: "CPU:   2% usr   1% sys   0% nic  96% idle   0% io ..."
+	regexp.MustCompile(`^\s*CPU:.*?` + topNumberPattern + `%\s*idle`),
+	// macOS: "CPU usage: 7.98% user, 5.32% sys, 86.69% idle"
+	regexp.MustCompile(`CPU usage:.*?` + topNumberPattern + `%\s*idle`),
+}
 
-	if isMacOS() {
-		// macOS: look for 'CPU usage: xx.x% user, yy.y% sys, zz.z% idle'
-		for _, line := range lines {
-			if strings.Contains(line, "CPU usage:") {
-				// Example: CPU usage: 7.98% user, 5.32% sys, 86.69% idle
-				parts := strings.Split(line, ",")
-				var idle float64
-				for _, part := range parts {
-					if strings.Contains(part, "idle") {
-						fields := strings.Fields(part)
-						if len(fields) > 0 {
-							val := strings.TrimSuffix(fields[0], "%")
-							idle, _ = strconv.ParseFloat(val, 64)
-						}
-					}
-				}
-				return 100 - idle, nil
-			}
-		}
-	}
+// topNumberPattern matches a percentage with a dot or, in some locales, a comma as the decimal separator
+const topNumberPattern = `([0-9]+(?:[.,][0-9]+)?)`
 
-	// Linux (default):
-	cpuRegex := regexp.MustCompile(`%Cpu\(s\):\s*([0-9.]+)\s*us,\s*([0-9.]+)\s*sy,.*?([0-9.]+)\s*id`)
-	for _, line := range lines {
-		if strings.Contains(line, "Cpu(s)") || strings.Contains(line, "%Cpu") {
-			matches := cpuRegex.FindStringSubmatch(line)
-			if len(matches) >= 4 {
-				idle, err := strconv.ParseFloat(matches[3], 64)
-				if err != nil {
-					continue
-				}
-				return 100 - idle, nil
+// parseTopCPUUsage extracts CPU usage (100 - idle) from top output. When top ran
+// several iterations the last CPU line is used, since the first covers the time since boot.
+func parseTopCPUUsage(output string) (float64, error) {
+	var usage float64
+	found := false
+
+	for _, line := range strings.Split(output, "\n") {
+		for _, pattern := range topIdlePatterns {
+			matches := pattern.FindStringSubmatch(line)
+			if matches == nil {
+				continue
 			}
-		}
-		// Alternative parsing for different top formats
-		if strings.Contains(line, "CPU usage:") {
-			parts := strings.Fields(line)
-			for i, part := range parts {
-				if part == "idle" && i > 0 {
-					idleStr := strings.TrimSuffix(parts[i-1], "%")
-					idle, err := strconv.ParseFloat(idleStr, 64)
-					if err == nil {
-						return 100 - idle, nil
-					}
-				}
+			idle, err := strconv.ParseFloat(strings.Replace(matches[1], ",", ".", 1), 64)
+			if err != nil {
+				continue
 			}
+			usage, found = 100-idle, true
+			break
 		}
 	}
+	if found {
+		return usage, nil
+	}
 	return 0, errors.New("could not parse CPU usage from top output")
 }
 
-// parseFreeCmdOutput parses the output of the free command (Linux only)
+// parseFreeCmdOutput parses the output of the free command (Linux only).
+// Columns are located by the header row, which differs between versions:
+// older procps and BusyBox print "buffers" and "cached", newer procps print
+// "buff/cache" and "available", and `free -w` prints "buffers" and "cache".
 func parseFreeCmdOutput(output string) (MemoryInfo, error) {
 	var info MemoryInfo
 
@@ -398,77 +514,118 @@ func parseFreeCmdOutput(output string) (MemoryInfo, error) {
 		return info, errors.New("invalid free command output")
 	}
 
-	// Parse the "Mem:" line
+	// Without a header, only the leading total, used and free columns are certain
+	columns := map[string]int{"total": 0, "used": 1, "free": 2}
+	foundMem := false
 	for _, line := range lines {
-		if strings.HasPrefix(line, "Mem:") {
-			fields := strings.Fields(line)
-			if len(fields) < 4 {
-				return info, errors.New("invalid memory line format")
-			}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
 
-			total, err := strconv.ParseInt(fields[1], 10, 64)
-			if err != nil {
-				return info, fmt.Errorf("failed to parse total memory: %w", err)
+		if fields[0] == "total" {
+			columns = make(map[string]int, len(fields))
+			for i, name := range fields {
+				columns[name] = i
 			}
+			continue
+		}
 
-			used, err := strconv.ParseInt(fields[2], 10, 64)
+		if fields[0] == "Swap:" {
+			// Swap: total used free
+			swapTotal, _, err := freeColumn(fields[1:], columns, "total")
 			if err != nil {
-				return info, fmt.Errorf("failed to parse used memory: %w", err)
+				return info, fmt.Errorf("failed to parse total swap: %w", err)
 			}
-
-			free, err := strconv.ParseInt(fields[3], 10, 64)
+			swapUsed, _, err := freeColumn(fields[1:], columns, "used")
 			if err != nil {
-				return info, fmt.Errorf("failed to parse free memory: %w", err)
+				return info, fmt.Errorf("failed to parse used swap: %w", err)
 			}
+			applySwap(&info, swapUsed, swapTotal)
+			continue
+		}
 
-			info.LimitBytes = total
-			info.UsageBytes = used
-			info.FreeBytes = free
-			info.AvailableBytes = free
-
-			var buffers, cached int64
+		if fields[0] != "Mem:" {
+			continue
+		}
+		values := fields[1:]
 
-			// Parse buffers and cached if available
-			if len(fields) >= 6 {
-				if buf, err := strconv.ParseInt(fields[5], 10, 64); err == nil {
-					buffers = buf
-					info.BufferBytes = buffers
-				}
-			}
-			if len(fields) >= 7 {
-				if cach, err := strconv.ParseInt(fields[6], 10, 64); err == nil {
-					cached = cach
-					info.CachedBytes = cached
-				}
-			}
+		total, _, err := freeColumn(values, columns, "total")
+		if err != nil {
+			return info, fmt.Errorf("failed to parse total memory: %w", err)
+		}
+		used, _, err := freeColumn(values, columns, "used")
+		if err != nil {
+			return info, fmt.Errorf("failed to parse used memory: %w", err)
+		}
+		free, _, err := freeColumn(values, columns, "free")
+		if err != nil {
+			return info, fmt.Errorf("failed to parse free memory: %w", err)
+		}
 
-			// Available memory includes buffers and cache
-			info.AvailableBytes = free + buffers + cached
+		info.LimitBytes = total
+		info.LimitSource = "system"
+		info.UsageBytes = used
+		info.FreeBytes = free
 
-			info.UsagePercent = (float64(used) / float64(total)) * 100
-			info.UsageMB = float64(used) / (1024 * 1024)
-			info.LimitMB = float64(total) / (1024 * 1024)
-			info.AvailableMB = float64(info.AvailableBytes) / (1024 * 1024)
+		// Optional columns are ignored if unparsable
+		info.BufferBytes, _, _ = freeColumn(values, columns, "buffers")
+		info.CachedBytes, _, _ = freeColumn(values, columns, "cached")
+		if cache, ok, _ := freeColumn(values, columns, "cache"); ok {
+			info.CachedBytes = cache
+		}
+		if buffCache, ok, _ := freeColumn(values, columns, "buff/cache"); ok {
+			// Buffers and cache are reported together
+			info.CachedBytes = buffCache
+		}
 
-			return info, nil
+		// Prefer the kernel's estimate of available memory when free reports it
+		if available, ok, _ := freeColumn(values, columns, "available"); ok {
+			info.AvailableBytes = available
+		} else {
+			info.AvailableBytes = free + info.BufferBytes + info.CachedBytes
 		}
+
+		info.UsagePercent = (float64(used) / float64(total)) * 100
+		info.UsageMB = float64(used) / (1024 * 1024)
+		info.LimitMB = float64(total) / (1024 * 1024)
+		info.AvailableMB = float64(info.AvailableBytes) / (1024 * 1024)
+		foundMem = true
 	}
 
+	if foundMem {
+		return info, nil
+	}
 	return info, errors.New("memory information not found in free output")
 }
 
-// parseVMStatOutput parses the output of vm_stat (macOS only)
+// freeColumn returns the value of the named column of a free data row
+// (without its "Mem:" or "Swap:" label). ok is false if the column is absent.
+func freeColumn(values []string, columns map[string]int, name string) (value int64, ok bool, err error) {
+	index, found := columns[name]
+	if !found || index >= len(values) {
+		return 0, false, fmt.Errorf("column %q not found", name)
+	}
+	value, err = strconv.ParseInt(values[index], 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return value, true, nil
+}
+
+// vmStatPageSizePattern matches the page size in the vm_stat header
+var vmStatPageSizePattern = regexp.MustCompile(`page size of (\d+) bytes`)
+
+// parseVMStatOutput parses the output of vm_stat (macOS only), following
+// Activity Monitor's model: used memory is app memory (anonymous pages that
+// aren't purgeable) plus wired and compressed memory, the compressor's pages
+// being a large share on modern macOS. File-backed and purgeable pages are
+// reported as cached and count as available. The total is hw.memsize, or the
+// sum of the physical page classes if it can't be read.
 func parseVMStatOutput(output string) (MemoryInfo, error) {
 	var info MemoryInfo
-	pageSize := int64(4096) // default page size
 
-	// Get page size from sysctl
-	out, err := exec.Command("sysctl", "-n", "hw.pagesize").Output()
-	if err == nil {
-		if sz, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64); err == nil {
-			pageSize = sz
-		}
-	}
+	pageSize := vmStatPageSize(output)
 
 	lines := strings.Split(output, "\n")
 	stats := make(map[string]int64)
@@ -482,33 +639,74 @@ func parseVMStatOutput(output string) (MemoryInfo, error) {
 			}
 		}
 	}
+	if len(stats) == 0 {
+		return info, fmt.Errorf("%s: no page counts in vm_stat output", ErrParsingValue)
+	}
 
-	totalPages := stats["Pages free"] + stats["Pages active"] + stats["Pages inactive"] + stats["Pages speculative"] + stats["Pages wired down"] + stats["Pages throttled"] + stats["Pages purgeable"] + stats["File-backed pages"] + stats["Anonymous pages"]
+	appPages := max(stats["Anonymous pages"]-stats["Pages purgeable"], 0)
+	usedPages := appPages + stats["Pages wired down"] + stats["Pages occupied by compressor"]
 	freePages := stats["Pages free"] + stats["Pages speculative"]
-	usedPages := totalPages - freePages
+	cachedPages := stats["File-backed pages"] + stats["Pages purgeable"]
 
-	total := totalPages * pageSize
-	used := usedPages * pageSize
+	// Physical pages are free, active, inactive, speculative, throttled, wired or
+	// held by the compressor; anonymous, file-backed and purgeable overlap these
+	total := getMacOSMemSize()
+	if total <= 0 {
+		total = (stats["Pages free"] + stats["Pages active"] + stats["Pages inactive"] + stats["Pages speculative"] +
+			stats["Pages throttled"] + stats["Pages wired down"] + stats["Pages occupied by compressor"]) * pageSize
+	}
+	used := min(usedPages*pageSize, total)
 	free := freePages * pageSize
 
 	info.LimitBytes = total
+	info.LimitSource = "system"
 	info.UsageBytes = used
 	info.FreeBytes = free
-	info.AvailableBytes = free
+	info.AvailableBytes = total - used
 	info.UsagePercent = (float64(used) / float64(total)) * 100
 	info.UsageMB = float64(used) / (1024 * 1024)
 	info.LimitMB = float64(total) / (1024 * 1024)
-	info.AvailableMB = float64(free) / (1024 * 1024)
-	// macOS does not have buffer/cache in the same way
+	info.AvailableMB = float64(info.AvailableBytes) / (1024 * 1024)
+	// macOS has no buffers; cached files are file-backed and purgeable pages
 	info.BufferBytes = 0
-	info.CachedBytes = 0
+	info.CachedBytes = cachedPages * pageSize
 
 	return info, nil
 }
 
-// getLoadAverage gets system load average
+// vmStatPageSize returns the page size from the vm_stat header, then from
+// sysctl hw.pagesize, defaulting to 4096
+func vmStatPageSize(output string) int64 {
+	if match := vmStatPageSizePattern.FindStringSubmatch(output); match != nil {
+		if size, err := strconv.ParseInt(match[1], 10, 64); err == nil && size > 0 {
+			return size
+		}
+	}
+	if out, err := commandOutput("sysctl", "-n", "hw.pagesize"); err == nil {
+		if size, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64); err == nil && size > 0 {
+			return size
+		}
+	}
+	return 4096
+}
+
+// getMacOSMemSize returns the physical memory size from sysctl hw.memsize, or 0
+// if it can't be read
+func getMacOSMemSize() int64 {
+	out, err := commandOutput("sysctl", "-n", "hw.memsize")
+	if err != nil {
+		return 0
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// getLoadAverage gets the system load average string from `uptime`
 func getLoadAverage() (string, error) {
-	output, err := exec.Command("uptime").Output()
+	output, err := commandOutput("uptime")
 	if err != nil {
 		return "", err
 	}
@@ -559,47 +757,88 @@ func getCPUInfoCgroup() (CPUInfo, error) {
 	info.LimitCores = limit
 
 	// Get CPU usage
-	usage, err := getCPUUsage()
+	usage, steal, err := getCPUUsageWithSteal()
 	if err != nil {
 		return info, err
 	}
-	info.UsedCores = usage
-	info.UsagePercent = (usage / limit) * 100
-	info.Available = limit - usage
+	applyCgroupCPUUsage(&info, usage)
+	info.StealPercent = steal
+	applyLoadAverage(&info)
 
 	return info, nil
 }
 
+// applyCgroupCPUUsage sets the used cores and derived fields of info, whose
+// LimitCores is set, clamping them when usage exceeds the limit
+func applyCgroupCPUUsage(info *CPUInfo, usage float64) {
+	info.UsedCores = usage
+	info.UsagePercent = (usage / info.LimitCores) * 100
+	info.Available = info.LimitCores - usage
+	if info.Available < 0 {
+		info.UsagePercent = 100
+		info.Available = 0
+		info.Clamped = true
+	}
+}
+
 // getMemoryInfoCgroup retrieves memory usage and limit information from cgroup
 func getMemoryInfoCgroup() (MemoryInfo, error) {
 	var info MemoryInfo
 
 	// Get memory limit from cgroup
-	limit, err := getMemoryLimit()
+	limit, source, err := getMemoryLimitWithSource()
 	if err != nil {
 		return info, err
 	}
 	info.LimitBytes = limit
+	info.LimitSource = source
+	info.LimitIsSet = source == "cgroup"
 
 	// Get memory usage from cgroup
 	usage, err := getMemoryUsage()
 	if err != nil {
 		return info, err
 	}
+	applyCgroupMemoryUsage(&info, usage)
+
+	// Swap is only reported when swap accounting is enabled
+	if swapUsage, swapLimit, err := readCgroupSwap(); err == nil {
+		applySwap(&info, swapUsage, swapLimit)
+	}
+
+	if stat, err := getMemoryStat(); err == nil {
+		info.CachedBytes, _ = cachedBytesFromStat(stat)
+	}
+
+	return info, nil
+}
+
+// applyCgroupMemoryUsage sets the usage and derived fields of info, whose
+// LimitBytes is set, clamping them when usage exceeds the limit
+func applyCgroupMemoryUsage(info *MemoryInfo, usage int64) {
 	info.UsageBytes = usage
-	info.AvailableBytes = limit - usage
-	info.UsagePercent = (float64(usage) / float64(limit)) * 100
+	info.AvailableBytes = info.LimitBytes - usage
+	info.UsagePercent = (float64(usage) / float64(info.LimitBytes)) * 100
+	if info.AvailableBytes < 0 {
+		info.AvailableBytes = 0
+		info.UsagePercent = 100
+		info.Clamped = true
+	}
 
 	// Convert to MB for convenience
 	info.UsageMB = float64(usage) / (1024 * 1024)
-	info.LimitMB = float64(limit) / (1024 * 1024)
+	info.LimitMB = float64(info.LimitBytes) / (1024 * 1024)
 	info.AvailableMB = float64(info.AvailableBytes) / (1024 * 1024)
-
-	return info, nil
+	applyMemoryUnit(info, defaultMemoryUnit)
 }
 
-// getCPULimit returns the CPU limit in cores
+// getCPULimit returns the CPU limit in cores, cached for the limit cache TTL
 func getCPULimit() (float64, error) {
+	return cpuLimitCache.get(readCPULimit)
+}
+
+// readCPULimit reads the CPU limit in cores
+func readCPULimit() (float64, error) {
 	if isMacOS() {
 		return getCPUCoresCommand()
 	}
@@ -612,40 +851,64 @@ func getCPULimit() (float64, error) {
 	return readCgroupV1CPULimit()
 }
 
-// getCPUUsage calculates current CPU usage
-func getCPUUsage() (float64, error) {
+// getCPUUsageWithSteal returns the number of cores in use, sampled over
+// defaultCPUSampleInterval, along with the host steal percentage over the same sample
+func getCPUUsageWithSteal() (float64, float64, error) {
 	if isMacOS() {
 		cpuInfo, err := getCPUInfoCommand()
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		return cpuInfo.UsedCores, nil
-	}
-	// Read CPU usage from cgroup
-	if usage, err := readCgroupCPUUsage(); err == nil {
-		return usage, nil
+		return cpuInfo.UsedCores, cpuInfo.StealPercent, nil
 	}
-
-	// Fall back to /proc/stat method
-	return readProcStatCPUUsage()
+	return sampleCPUUsageWithSteal(defaultCPUSampleInterval)
 }
 
 // getMemoryLimit returns the memory limit in bytes
 func getMemoryLimit() (int64, error) {
+	limit, _, err := getMemoryLimitWithSource()
+	return limit, err
+}
+
+// getMemoryLimitWithSource returns the memory limit in bytes and whether it is a
+// cgroup limit ("cgroup") or total system memory because none is set ("system").
+// The result is cached for the limit cache TTL.
+func getMemoryLimitWithSource() (int64, string, error) {
+	limit, err := memoryLimitCache.get(func() (memoryLimit, error) {
+		bytes, source, err := readMemoryLimitWithSource()
+		return memoryLimit{bytes, source}, err
+	})
+	return limit.bytes, limit.source, err
+}
+
+// readMemoryLimitWithSource reads the memory limit, see getMemoryLimitWithSource
+func readMemoryLimitWithSource() (int64, string, error) {
 	if isMacOS() {
 		memInfo, err := getMemoryInfoCommand()
 		if err != nil {
-			return 0, err
+			return 0, "", err
 		}
-		return memInfo.LimitBytes, nil
+		return memInfo.LimitBytes, "system", nil
 	}
-	// Try cgroup v2 first
-	if limit, err := readCgroupV2MemoryLimit(); err == nil {
-		return limit, nil
+	// Try cgroup v2 first, then fall back to cgroup v1
+	limit, unlimited, err := readCgroupV2MemoryLimit()
+	if err != nil {
+		limit, unlimited, err = readCgroupV1MemoryLimit()
+		if err != nil {
+			return 0, "", err
+		}
 	}
+	return memoryLimitWithSource(limit, unlimited)
+}
 
-	// Fall back to cgroup v1
-	return readCgroupV1MemoryLimit()
+// memoryLimitWithSource returns a cgroup memory limit with its source, or
+// system memory when the cgroup has no limit
+func memoryLimitWithSource(limit int64, unlimited bool) (int64, string, error) {
+	if unlimited {
+		total, err := getSystemMemory()
+		return total, "system", err
+	}
+	return limit, "cgroup", nil
 }
 
 // getMemoryUsage returns the memory usage in bytes
@@ -668,42 +931,66 @@ func getMemoryUsage() (int64, error) {
 
 // readCgroupV2CPULimit reads CPU limit from cgroup v2
 func readCgroupV2CPULimit() (float64, error) {
-	content, err := readFile("/sys/fs/cgroup/cpu.max")
+	content, err := readFile(cgroupFile("cpu.max"))
 	if err != nil {
 		return 0, err
 	}
 
-	parts := strings.Fields(strings.TrimSpace(content))
-	if len(parts) != 2 {
-		return 0, errors.New("invalid cpu.max format")
+	limit, unlimited, err := parseCgroupV2CPUMax(content)
+	if err != nil {
+		return 0, err
+	}
+	if unlimited {
+		// No CPU quota set, use the CPUs the cpuset allows
+		return getAvailableCPUs()
 	}
 
-	if parts[0] == "max" {
-		// No CPU limit set, use number of CPUs
-		return getNumCPUs()
+	return limit, nil
+}
+
+// parseCgroupV2CPUMax parses cpu.max ("$MAX $PERIOD") into a limit in cores.
+// unlimited is true when the quota is "max" (matched case-insensitively).
+// Surrounding whitespace is ignored and a missing period defaults to 100000.
+func parseCgroupV2CPUMax(content string) (limit float64, unlimited bool, err error) {
+	parts := strings.Fields(content)
+	if len(parts) == 0 || len(parts) > 2 {
+		return 0, false, fmt.Errorf("invalid cpu.max format: %q", strings.TrimSpace(content))
 	}
 
-	quota, err := strconv.ParseFloat(parts[0], 64)
-	if err != nil {
-		return 0, err
+	period := 100000.0
+	if len(parts) == 2 {
+		period, err = strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("%s: cpu.max period: %w", ErrParsingValue, err)
+		}
+		if period <= 0 {
+			return 0, false, fmt.Errorf("invalid cpu.max period: %s", parts[1])
+		}
+	}
+
+	if strings.EqualFold(parts[0], "max") {
+		return 0, true, nil
 	}
 
-	period, err := strconv.ParseFloat(parts[1], 64)
+	quota, err := strconv.ParseFloat(parts[0], 64)
 	if err != nil {
-		return 0, err
+		return 0, false, fmt.Errorf("%s: cpu.max quota: %w", ErrParsingValue, err)
+	}
+	if quota <= 0 {
+		return 0, false, fmt.Errorf("invalid cpu.max quota: %s", parts[0])
 	}
 
-	return quota / period, nil
+	return quota / period, false, nil
 }
 
 // readCgroupV1CPULimit reads CPU limit from cgroup v1
 func readCgroupV1CPULimit() (float64, error) {
-	quotaContent, err := readFile("/sys/fs/cgroup/cpu,cpuacct/cpu.cfs_quota_us")
+	quotaContent, err := readFile(cgroupFile("cpu,cpuacct/cpu.cfs_quota_us"))
 	if err != nil {
 		return 0, err
 	}
 
-	periodContent, err := readFile("/sys/fs/cgroup/cpu,cpuacct/cpu.cfs_period_us")
+	periodContent, err := readFile(cgroupFile("cpu,cpuacct/cpu.cfs_period_us"))
 	if err != nil {
 		return 0, err
 	}
@@ -714,8 +1001,8 @@ func readCgroupV1CPULimit() (float64, error) {
 	}
 
 	if quota == -1 {
-		// No CPU limit set, use number of CPUs
-		return getNumCPUs()
+		// No CPU quota set, use the CPUs the cpuset allows
+		return getAvailableCPUs()
 	}
 
 	period, err := strconv.ParseFloat(strings.TrimSpace(periodContent), 64)
@@ -726,107 +1013,155 @@ func readCgroupV1CPULimit() (float64, error) {
 	return quota / period, nil
 }
 
-// readCgroupCPUUsage reads CPU usage from cgroup
-func readCgroupCPUUsage() (float64, error) {
-	// This is a simplified implementation
-	// In practice, we'd need to calculate usage over time
-	content, err := readFile("/sys/fs/cgroup/cpuacct/cpuacct.usage")
+// readCgroupCPUUsageSeconds reads the cgroup's cumulative CPU time in seconds
+func readCgroupCPUUsageSeconds() (float64, error) {
+	if seconds, err := readCgroupV1CPUUsageSeconds(); err == nil {
+		return seconds, nil
+	}
+	return readCgroupV2CPUUsageSeconds()
+}
+
+// readCgroupV2CPUUsageSeconds reads cumulative CPU time from cgroup v2 cpu.stat
+func readCgroupV2CPUUsageSeconds() (float64, error) {
+	content, err := readFile(cgroupFile("cpu.stat"))
 	if err != nil {
-		// Try cgroup v2
-		content, err = readFile("/sys/fs/cgroup/cpu.stat")
-		if err != nil {
-			return 0, err
-		}
-		return parseCgroupV2CPUUsage(content)
+		return 0, err
+	}
+	return parseCgroupV2CPUUsage(content)
+}
+
+// readCgroupV1CPUUsageSeconds reads cumulative CPU time from cgroup v1 cpuacct.usage
+func readCgroupV1CPUUsageSeconds() (float64, error) {
+	content, err := readFile(cgroupFile("cpuacct/cpuacct.usage"))
+	if err != nil {
+		return 0, err
 	}
 
 	nanoseconds, err := strconv.ParseFloat(strings.TrimSpace(content), 64)
 	if err != nil {
 		return 0, err
 	}
+	return nanoseconds / 1e9, nil
+}
 
-	// Convert to cores (this is cumulative, so we'd need to track over time)
-	// For now, return a placeholder that would need proper implementation
-	return nanoseconds / 1e9 / 100, nil // Rough approximation
+// sampleCPUUsage returns the average number of cores in use over interval, from
+// the cgroup CPU time counter or, when it is unavailable, from /proc/stat
+func sampleCPUUsage(interval time.Duration) (float64, error) {
+	usage, _, err := sampleCPUUsageWithSteal(interval)
+	return usage, err
 }
 
-// readProcStatCPUUsage reads CPU usage from /proc/stat
-func readProcStatCPUUsage() (float64, error) {
-	content, err := readFile("/proc/stat")
+// sampleCPUUsageWithSteal samples cores in use like sampleCPUUsage, and the host
+// steal percentage from /proc/stat over the same interval. Steal is 0 if
+// /proc/stat can't be read.
+func sampleCPUUsageWithSteal(interval time.Duration) (float64, float64, error) {
+	before, err := readCgroupCPUUsageSeconds()
 	if err != nil {
-		return 0, err
+		return sampleProcStatCPUUsage(interval)
 	}
+	return sampleCgroupCPUUsage(before, readCgroupCPUUsageSeconds, interval)
+}
 
-	lines := strings.Split(content, "\n")
-	if len(lines) == 0 {
-		return 0, errors.New("empty /proc/stat")
+// sampleCgroupCPUUsage samples cores in use from a cgroup CPU seconds counter,
+// given its reading before the interval and how to read it again, along with
+// the host steal percentage over the interval
+func sampleCgroupCPUUsage(before float64, read func() (float64, error), interval time.Duration) (float64, float64, error) {
+	statBefore, statErr := readProcStatCPUTimes()
+	start := time.Now()
+	time.Sleep(interval)
+	after, err := read()
+	if err != nil {
+		return 0, 0, err
 	}
+	elapsed := time.Since(start)
 
-	cpuLine := lines[0]
-	if !strings.HasPrefix(cpuLine, "cpu ") {
-		return 0, errors.New("invalid /proc/stat format")
+	var steal float64
+	if statErr == nil {
+		if statAfter, err := readProcStatCPUTimes(); err == nil {
+			steal = stealPercent(statBefore["cpu"], statAfter["cpu"])
+		}
 	}
+	return coresUsed(before, after, elapsed), steal, nil
+}
 
-	fields := strings.Fields(cpuLine)
-	if len(fields) < 8 {
-		return 0, errors.New("insufficient CPU fields in /proc/stat")
+// coresUsed converts the growth of a cumulative CPU seconds counter over elapsed wall time into cores in use
+func coresUsed(before, after float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 || after < before {
+		return 0
 	}
+	return (after - before) / elapsed.Seconds()
+}
 
-	// This is a simplified calculation - proper implementation would track over time
-	user, _ := strconv.ParseFloat(fields[1], 64)
-	system, _ := strconv.ParseFloat(fields[3], 64)
-	idle, _ := strconv.ParseFloat(fields[4], 64)
-
-	total := user + system + idle
-	used := total - idle
+// sampleProcStatCPUUsage returns the average number of host cores in use over
+// interval, and the steal percentage over it
+func sampleProcStatCPUUsage(interval time.Duration) (float64, float64, error) {
+	percent, steal, err := sampleProcStatCPUPercent(interval)
+	if err != nil {
+		return 0, 0, err
+	}
 
 	numCPUs, err := getNumCPUs()
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
-
-	return (used / total) * numCPUs, nil
+	return percent / 100 * numCPUs, steal, nil
 }
 
-// readCgroupV2MemoryLimit reads memory limit from cgroup v2
-func readCgroupV2MemoryLimit() (int64, error) {
-	content, err := readFile("/sys/fs/cgroup/memory.max")
+// sampleProcStatCPUPercent returns host CPU usage and steal over interval as
+// percentages of all cores, from two /proc/stat reads
+func sampleProcStatCPUPercent(interval time.Duration) (float64, float64, error) {
+	before, after, err := sampleProcStatCPUTimes(interval)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
-
-	limitStr := strings.TrimSpace(content)
-	if limitStr == "max" {
-		// No memory limit, read from /proc/meminfo
-		return getSystemMemory()
-	}
-
-	return strconv.ParseInt(limitStr, 10, 64)
+	return busyPercent(before["cpu"], after["cpu"]), stealPercent(before["cpu"], after["cpu"]), nil
 }
 
-// readCgroupV1MemoryLimit reads memory limit from cgroup v1
-func readCgroupV1MemoryLimit() (int64, error) {
-	content, err := readFile("/sys/fs/cgroup/memory/memory.limit_in_bytes")
+// sampleCPUUsagePercent returns CPU usage over interval as a percentage of the
+// CPU limit, or of the host core count when no limit is readable
+func sampleCPUUsagePercent(interval time.Duration) (float64, error) {
+	used, err := sampleCPUUsage(interval)
 	if err != nil {
 		return 0, err
 	}
+	return percentOfCPULimit(used)
+}
 
-	limit, err := strconv.ParseInt(strings.TrimSpace(content), 10, 64)
+// percentOfCPULimit converts cores in use to a percentage of the CPU limit, or
+// of the host core count when no limit is readable
+func percentOfCPULimit(used float64) (float64, error) {
+	limit, err := getCPULimit()
 	if err != nil {
-		return 0, err
+		limit, err = getAvailableCPUs()
+		if err != nil {
+			return 0, err
+		}
 	}
+	return (used / limit) * 100, nil
+}
 
-	// Check if limit is set to a very large value (indicating no limit)
-	if limit > 9223372036854775807/2 { // Very large number indicating no limit
-		return getSystemMemory()
+// readCgroupV2MemoryLimit reads memory limit from cgroup v2. unlimited is true for "max".
+func readCgroupV2MemoryLimit() (limit int64, unlimited bool, err error) {
+	content, err := readFile(cgroupFile("memory.max"))
+	if err != nil {
+		return 0, false, err
 	}
+	return parseCgroupMemoryLimit(content)
+}
 
-	return limit, nil
+// readCgroupV1MemoryLimit reads memory limit from cgroup v1. unlimited is true
+// for the very large value v1 reports when no limit is set.
+func readCgroupV1MemoryLimit() (limit int64, unlimited bool, err error) {
+	content, err := readFile(cgroupFile("memory/memory.limit_in_bytes"))
+	if err != nil {
+		return 0, false, err
+	}
+	return parseCgroupMemoryLimit(content)
 }
 
 // readCgroupV2MemoryUsage reads memory usage from cgroup v2
 func readCgroupV2MemoryUsage() (int64, error) {
-	content, err := readFile("/sys/fs/cgroup/memory.current")
+	content, err := readFile(cgroupFile("memory.current"))
 	if err != nil {
 		return 0, err
 	}
@@ -836,7 +1171,7 @@ func readCgroupV2MemoryUsage() (int64, error) {
 
 // readCgroupV1MemoryUsage reads memory usage from cgroup v1
 func readCgroupV1MemoryUsage() (int64, error) {
-	content, err := readFile("/sys/fs/cgroup/memory/memory.usage_in_bytes")
+	content, err := readFile(cgroupFile("memory/memory.usage_in_bytes"))
 	if err != nil {
 		return 0, err
 	}
@@ -844,7 +1179,7 @@ func readCgroupV1MemoryUsage() (int64, error) {
 	return strconv.ParseInt(strings.TrimSpace(content), 10, 64)
 }
 
-// parseCgroupV2CPUUsage parses CPU usage from cgroup v2 cpu.stat
+// parseCgroupV2CPUUsage parses the cumulative CPU time in seconds from cgroup v2 cpu.stat
 func parseCgroupV2CPUUsage(content string) (float64, error) {
 	lines := strings.Split(content, "\n")
 	for _, line := range lines {
@@ -855,8 +1190,7 @@ func parseCgroupV2CPUUsage(content string) (float64, error) {
 				if err != nil {
 					return 0, err
 				}
-				// Convert to cores (simplified calculation)
-				return microseconds / 1e6 / 100, nil
+				return microseconds / 1e6, nil
 			}
 		}
 	}
@@ -925,65 +1259,6 @@ func fileExists(filename string) bool {
 	return !os.IsNotExist(err)
 }
 
-// CheckConnectivity checks connectivity to a domain at multiple layers (TCP, HTTP)
-// timeoutSeconds: timeout for each check in seconds (default 5 if <=0)
-// port: port to check (default "80" if empty)
-func CheckConnectivity(domain, port string, timeoutSeconds int) ConnectivityReport {
-	if timeoutSeconds <= 0 {
-		timeoutSeconds = 5
-	}
-	if port == "" {
-		port = "80"
-	}
-	address := net.JoinHostPort(domain, port)
-	report := ConnectivityReport{
-		Domain:         domain,
-		Port:           port,
-		TimeoutSeconds: timeoutSeconds,
-	}
-
-	// TCP check
-	dialer := net.Dialer{Timeout: time.Duration(timeoutSeconds) * time.Second}
-	tcpConn, err := dialer.Dial("tcp", address)
-	if err != nil {
-		report.TCP = err.Error()
-	} else {
-		report.TCP = "success"
-		tcpConn.Close()
-	}
-
-	// HTTP check (only if TCP succeeded)
-	if report.TCP == "success" {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
-		defer cancel()
-		url := "http://" + address
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			report.HTTP = err.Error()
-		} else {
-			client := &http.Client{
-				Timeout: time.Duration(timeoutSeconds) * time.Second,
-			}
-			resp, err := client.Do(req)
-			if err != nil {
-				report.HTTP = err.Error()
-			} else {
-				report.HTTP = resp.Status
-				resp.Body.Close()
-			}
-		}
-	} else {
-		report.HTTP = "skipped (TCP failed)"
-	}
-
-	return report
-}
-
-// CheckConnectivity exposes CheckConnectivity to k6 JavaScript
-func (Toolbox) CheckConnectivity(domain string, port string, timeoutSeconds int) ConnectivityReport {
-	return CheckConnectivity(domain, port, timeoutSeconds)
-}
-
 // IsMacOS returns true if the current OS is macOS (darwin)
 func (Toolbox) IsMacOS() bool {
 	return isMacOS()
@@ -1,11 +1,13 @@
 package toolbox
 
 import (
+	"math"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetCPUUsage(t *testing.T) {
@@ -120,6 +122,72 @@ func TestGetAvailableMemory(t *testing.T) {
 	t.Logf("Available Memory: %d bytes (%.2f MB)", available, float64(available)/(1024*1024))
 }
 
+func TestGetSystemInfo(t *testing.T) {
+	toolbox := Toolbox{}
+	info, err := toolbox.GetSystemInfo()
+
+	if err != nil {
+		t.Logf("GetSystemInfo failed (expected in test environment): %v", err)
+		return
+	}
+
+	switch info.Method {
+	case "cgroup":
+		if info.Fallback {
+			t.Error("Expected no fallback when cgroup files were used")
+		}
+	case "command", "proc":
+	default:
+		t.Errorf("Unexpected method '%s'", info.Method)
+	}
+	if info.CPU.LimitCores <= 0 {
+		t.Errorf("Expected positive CPU limit, got %f", info.CPU.LimitCores)
+	}
+	if info.Memory.LimitBytes <= 0 {
+		t.Errorf("Expected positive memory limit, got %d", info.Memory.LimitBytes)
+	}
+
+	t.Logf("System info via %s (fallback %t): CPU %+v, Memory %+v", info.Method, info.Fallback, info.CPU, info.Memory)
+}
+
+func TestGetCPUInfo(t *testing.T) {
+	toolbox := Toolbox{}
+	info, err := toolbox.GetCPUInfo()
+
+	if err != nil {
+		t.Logf("GetCPUInfo failed (expected in test environment): %v", err)
+		return
+	}
+
+	if info.LimitCores <= 0 {
+		t.Errorf("Expected positive CPU limit, got %f", info.LimitCores)
+	}
+	if info.UsagePercent < 0 {
+		t.Errorf("Expected CPU usage >= 0, got %f", info.UsagePercent)
+	}
+
+	t.Logf("CPU info: %+v", info)
+}
+
+func TestGetMemoryInfo(t *testing.T) {
+	toolbox := Toolbox{}
+	info, err := toolbox.GetMemoryInfo()
+
+	if err != nil {
+		t.Logf("GetMemoryInfo failed (expected in test environment): %v", err)
+		return
+	}
+
+	if info.LimitBytes <= 0 {
+		t.Errorf("Expected positive memory limit, got %d", info.LimitBytes)
+	}
+	if info.Unit == "" {
+		t.Error("Expected the memory unit to be set")
+	}
+
+	t.Logf("Memory info: %+v", info)
+}
+
 func TestGetPsOutput(t *testing.T) {
 	toolbox := Toolbox{}
 	output, err := toolbox.GetPsOutput()
@@ -218,8 +286,8 @@ func TestParseCgroupV2CPUUsage(t *testing.T) {
 	if err != nil {
 		t.Errorf("parseCgroupV2CPUUsage failed: %v", err)
 	}
-	if usage <= 0 {
-		t.Errorf("Expected usage > 0, got %f", usage)
+	if usage != 123.456789 {
+		t.Errorf("Expected 123.456789 CPU seconds, got %f", usage)
 	}
 
 	// Test invalid input
@@ -235,6 +303,40 @@ func TestParseCgroupV2CPUUsage(t *testing.T) {
 	}
 }
 
+func TestCoresUsed(t *testing.T) {
+	// 0.5s of CPU time over 250ms of wall time is 2 cores
+	if cores := coresUsed(10, 10.5, 250*time.Millisecond); cores != 2 {
+		t.Errorf("Expected 2 cores, got %f", cores)
+	}
+
+	// A counter that went backwards (cgroup recreated) or no elapsed time yields 0
+	if cores := coresUsed(10, 5, time.Second); cores != 0 {
+		t.Errorf("Expected 0 cores for a reset counter, got %f", cores)
+	}
+	if cores := coresUsed(10, 11, 0); cores != 0 {
+		t.Errorf("Expected 0 cores without elapsed time, got %f", cores)
+	}
+}
+
+func TestGetCPUUsageOverInterval(t *testing.T) {
+	toolbox := Toolbox{}
+
+	if _, err := toolbox.GetCPUUsageOverInterval(0); err == nil {
+		t.Error("Expected error for zero interval")
+	}
+
+	usage, err := toolbox.GetCPUUsageOverInterval(50)
+	if err != nil {
+		t.Logf("GetCPUUsageOverInterval failed (expected in test environment): %v", err)
+		return
+	}
+	if usage < 0 || usage > 100 {
+		t.Errorf("Expected CPU usage between 0-100, got %f", usage)
+	}
+
+	t.Logf("CPU usage over 50ms: %.2f%%", usage)
+}
+
 func TestParseTopCPUUsage(t *testing.T) {
 	// Test standard top output format
 	output := `top - 10:30:00 up 2 days, 20:45,  1 user,  load average: 0.52, 0.58, 0.59
@@ -265,6 +367,24 @@ MiB Mem :  16384.0 total,   8192.0 free,   4096.0 used,   4096.0 buff/cache`
 		t.Errorf("Expected CPU usage %f, got %f", expected2, usage2)
 	}
 
+	// Two iterations: the first block covers the time since boot, the second is current
+	output3 := `top - 10:30:00 up 2 days, 20:45,  1 user,  load average: 0.52, 0.58, 0.59
+Tasks: 123 total,   1 running, 122 sleeping,   0 stopped,   0 zombie
+%Cpu(s):  1.0 us,  1.0 sy,  0.0 ni, 98.0 id,  0.0 wa,  0.0 hi,  0.0 si,  0.0 st
+MiB Mem :  16384.0 total,   8192.0 free,   4096.0 used,   4096.0 buff/cache
+
+top - 10:30:01 up 2 days, 20:45,  1 user,  load average: 0.52, 0.58, 0.59
+Tasks: 123 total,   2 running, 121 sleeping,   0 stopped,   0 zombie
+%Cpu(s): 30.5 us,  9.5 sy,  0.0 ni, 60.0 id,  0.0 wa,  0.0 hi,  0.0 si,  0.0 st
+MiB Mem :  16384.0 total,   8192.0 free,   4096.0 used,   4096.0 buff/cache`
+	usage3, err := parseTopCPUUsage(output3)
+	if err != nil {
+		t.Errorf("parseTopCPUUsage failed on two iterations: %v", err)
+	}
+	if expected3 := 100.0 - 60.0; usage3 < expected3-epsilon || usage3 > expected3+epsilon {
+		t.Errorf("Expected CPU usage %f from the last iteration, got %f", expected3, usage3)
+	}
+
 	// Test invalid format
 	_, err = parseTopCPUUsage("invalid output")
 	if err == nil {
@@ -272,6 +392,48 @@ MiB Mem :  16384.0 total,   8192.0 free,   4096.0 used,   4096.0 buff/cache`
 	}
 }
 
+func TestParseTopCPUUsageVariants(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected float64
+	}{
+		{
+			name: "busybox",
+			output: `Mem: 1960548K used, 65104K free, 1228K shrd, 93540K buff, 1109744K cached
+CPU:   2% usr   1% sys   0% nic  96% idle   0% io   0% irq   0% sirq
+Load average: 0.25 0.28 0.30 2/263 112`,
+			expected: 4,
+		},
+		{
+			name:     "comma decimals",
+			output:   `%Cpu(s):  2,3 us,  1,0 sy,  0,0 ni, 96,5 id,  0,0 wa,  0,0 hi,  0,2 si,  0,0 st`,
+			expected: 3.5,
+		},
+		{
+			name:     "older procps",
+			output:   `Cpu(s):  2.3%us,  1.0%sy,  0.0%ni, 96.5%id,  0.0%wa,  0.0%hi,  0.2%si,  0.0%st`,
+			expected: 3.5,
+		},
+		{
+			name:     "macOS",
+			output:   `CPU usage: 7.98% user, 5.32% sys, 86.69% idle`,
+			expected: 13.31,
+		},
+	}
+
+	for _, tt := range tests {
+		usage, err := parseTopCPUUsage(tt.output)
+		if err != nil {
+			t.Errorf("%s: parseTopCPUUsage failed: %v", tt.name, err)
+			continue
+		}
+		if math.Abs(usage-tt.expected) > 0.001 {
+			t.Errorf("%s: expected CPU usage %f, got %f", tt.name, tt.expected, usage)
+		}
+	}
+}
+
 func TestParseFreeCmdOutput(t *testing.T) {
 	// Test standard free output format
 	output := `              total        used        free      shared  buff/cache   available
@@ -293,8 +455,25 @@ Swap:      16777216            0    16777216`
 	if info.FreeBytes != 4194304 {
 		t.Errorf("Expected free memory 4194304, got %d", info.FreeBytes)
 	}
-	if info.BufferBytes != 4194304 {
-		t.Errorf("Expected buffer memory 4194304, got %d", info.BufferBytes)
+	// buff/cache is a single column, reported as cache
+	if info.CachedBytes != 4194304 || info.BufferBytes != 0 {
+		t.Errorf("Expected cached memory 4194304 and no buffers, got %d and %d", info.CachedBytes, info.BufferBytes)
+	}
+	// The available column is used as-is
+	if info.AvailableBytes != 8388608 {
+		t.Errorf("Expected available memory 8388608, got %d", info.AvailableBytes)
+	}
+	if info.SwapLimitBytes != 16777216 || info.SwapUsageBytes != 0 {
+		t.Errorf("Expected swap 0/16777216, got %d/%d", info.SwapUsageBytes, info.SwapLimitBytes)
+	}
+
+	// Swap disabled
+	info, err = parseFreeCmdOutput("              total        used        free\nMem:           1000         600         400\nSwap:             0           0           0")
+	if err != nil {
+		t.Errorf("parseFreeCmdOutput failed without swap: %v", err)
+	}
+	if info.SwapUsagePercent != 0 || info.LimitBytes != 1000 {
+		t.Errorf("Expected 0%% swap and 1000 total, got %f and %d", info.SwapUsagePercent, info.LimitBytes)
 	}
 
 	// Test invalid format
@@ -304,6 +483,130 @@ Swap:      16777216            0    16777216`
 	}
 }
 
+// vmStatSonoma is vm_stat output of a 16GB Apple silicon Mac under memory pressure
+const vmStatSonoma = `Mach Virtual Memory Statistics: (page size of 16384 bytes)
+Pages free:                               13456.
+Pages active:                            312345.
+Pages inactive:                          301234.
+Pages speculative:                         4567.
+Pages throttled:                              0.
+Pages wired down:                        180432.
+Pages purgeable:                           8123.
+"Translation faults":                1234567890.
+Pages copy-on-write:                   45678901.
+Pages zero filled:                    456789012.
+Pages reactivated:                     12345678.
+Pages purged:                           2345678.
+File-backed pages:                       250000.
+Anonymous pages:                         368146.
+Pages stored in compressor:              812345.
+Pages occupied by compressor:            230112.
+Decompressions:                        23456789.
+Compressions:                          34567890.
+Pageins:                               12345678.
+Pageouts:                                123456.
+Swapins:                                  12345.
+Swapouts:                                 23456.
+`
+
+func TestParseVMStatOutput(t *testing.T) {
+	useFakeRunner(t, fakeRunner{"sysctl -n hw.memsize": "17179869184\n"})
+
+	info, err := parseVMStatOutput(vmStatSonoma)
+	if err != nil {
+		t.Fatalf("parseVMStatOutput failed: %v", err)
+	}
+	if info.LimitBytes != 17179869184 {
+		t.Errorf("Expected the hw.memsize total, got %d", info.LimitBytes)
+	}
+	// App memory (anonymous - purgeable) + wired + compressor, in 16KiB pages
+	if want := int64(360023+180432+230112) * 16384; info.UsageBytes != want {
+		t.Errorf("Expected usage %d including compressed memory, got %d", want, info.UsageBytes)
+	}
+	if info.UsagePercent < 60 || info.UsagePercent > 90 {
+		t.Errorf("Expected usage between 60-90%%, got %f", info.UsagePercent)
+	}
+	if info.AvailableBytes != info.LimitBytes-info.UsageBytes {
+		t.Errorf("Expected available to be total minus used, got %d", info.AvailableBytes)
+	}
+	if want := int64(250000+8123) * 16384; info.CachedBytes != want {
+		t.Errorf("Expected cached %d from file-backed and purgeable pages, got %d", want, info.CachedBytes)
+	}
+
+	// Without hw.memsize the total is the sum of the physical page classes
+	useFakeRunner(t, fakeRunner{})
+	info, err = parseVMStatOutput(vmStatSonoma)
+	if err != nil {
+		t.Fatalf("parseVMStatOutput failed: %v", err)
+	}
+	if want := int64(13456+312345+301234+4567+180432+230112) * 16384; info.LimitBytes != want {
+		t.Errorf("Expected total %d from page classes, got %d", want, info.LimitBytes)
+	}
+	if info.UsagePercent < 60 || info.UsagePercent > 90 {
+		t.Errorf("Expected usage between 60-90%%, got %f", info.UsagePercent)
+	}
+
+	if _, err := parseVMStatOutput("garbage"); err == nil {
+		t.Error("Expected error for output without page counts")
+	}
+}
+
+func TestParseFreeCmdOutputLayouts(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		buffers   int64
+		cached    int64
+		available int64
+	}{
+		{
+			name: "old procps with buffers and cached",
+			output: `             total       used       free     shared    buffers     cached
+Mem:          1000        800        200         10         50        250
+-/+ buffers/cache:        500        500
+Swap:            0          0          0`,
+			buffers:   50,
+			cached:    250,
+			available: 500, // free + buffers + cached
+		},
+		{
+			name: "wide output with separate buffers and cache",
+			output: `               total        used        free      shared     buffers       cache   available
+Mem:            1000         400         200          10          50         350         550
+Swap:            100          25          75`,
+			buffers:   50,
+			cached:    350,
+			available: 550,
+		},
+		{
+			name: `BusyBox with buff/cache and no available column`,
+			output: `              total        used        free      shared  buff/cache
+Mem:           1000         600         100           0         300
+Swap:             0           0           0`,
+			cached:    300,
+			available: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := parseFreeCmdOutput(tt.output)
+			if err != nil {
+				t.Fatalf("parseFreeCmdOutput failed: %v", err)
+			}
+			if info.LimitBytes != 1000 {
+				t.Errorf("Expected total 1000, got %d", info.LimitBytes)
+			}
+			if info.BufferBytes != tt.buffers || info.CachedBytes != tt.cached {
+				t.Errorf("Expected buffers/cached %d/%d, got %d/%d", tt.buffers, tt.cached, info.BufferBytes, info.CachedBytes)
+			}
+			if info.AvailableBytes != tt.available {
+				t.Errorf("Expected available %d, got %d", tt.available, info.AvailableBytes)
+			}
+		})
+	}
+}
+
 func TestGetLoadAverage(t *testing.T) {
 	loadAvg, err := getLoadAverage()
 	if err != nil {
@@ -351,20 +654,6 @@ func TestGetSystemMemory(t *testing.T) {
 	t.Logf("System memory: %d bytes (%.2f GB)", memory, float64(memory)/(1024*1024*1024))
 }
 
-func TestCheckConnectivity(t *testing.T) {
-	// This is a basic test that requires network access
-	report := CheckConnectivity("google.com", "80", 5)
-
-	if report.Domain != "google.com" {
-		t.Errorf("Expected domain 'google.com', got '%s'", report.Domain)
-	}
-
-	if report.TCP != "success" && !strings.Contains(report.TCP, "refused") {
-		// Allow for connection refused in sandboxed environments
-		t.Logf("TCP check did not succeed (as expected in some environments): %s", report.TCP)
-	}
-}
-
 func TestOSDetection(t *testing.T) {
 	toolbox := Toolbox{}
 	isMac := toolbox.IsMacOS()
@@ -396,3 +685,75 @@ func TestOSDetection(t *testing.T) {
 	}
 	t.Logf("OS detection: GOOS=%s, isMacOS=%v, isLinux=%v", runtime.GOOS, isMac, isLin)
 }
+
+func TestParseCgroupV2CPUMax(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		limit     float64
+		unlimited bool
+		wantErr   bool
+	}{
+		{name: "unlimited", content: "max 100000", unlimited: true},
+		{name: "unlimited uppercase", content: "MAX 100000\n", unlimited: true},
+		{name: "unlimited without period", content: "max", unlimited: true},
+		{name: "two cores", content: "200000 100000", limit: 2},
+		{name: "extra whitespace", content: "  50000\t100000 \n\n", limit: 0.5},
+		{name: "quota without period", content: "150000", limit: 1.5},
+		{name: "empty", content: "", wantErr: true},
+		{name: "whitespace only", content: " \n", wantErr: true},
+		{name: "too many fields", content: "200000 100000 1", wantErr: true},
+		{name: "non-numeric quota", content: "abc 100000", wantErr: true},
+		{name: "non-numeric period", content: "200000 abc", wantErr: true},
+		{name: "zero period", content: "200000 0", wantErr: true},
+		{name: "negative quota", content: "-1 100000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, unlimited, err := parseCgroupV2CPUMax(tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.content)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCgroupV2CPUMax(%q) failed: %v", tt.content, err)
+			}
+			if unlimited != tt.unlimited {
+				t.Errorf("Expected unlimited=%v, got %v", tt.unlimited, unlimited)
+			}
+			if limit != tt.limit {
+				t.Errorf("Expected limit %f, got %f", tt.limit, limit)
+			}
+		})
+	}
+}
+
+func TestGetSystemInfoWithMethod(t *testing.T) {
+	toolbox := Toolbox{}
+
+	for _, method := range []string{"cgroup", "command", "proc", "auto"} {
+		info, err := toolbox.GetSystemInfoWithMethod(method)
+		if err != nil {
+			t.Logf("GetSystemInfoWithMethod(%q) failed (expected in test environment): %v", method, err)
+			continue
+		}
+		if method != "auto" && info.Method != method {
+			t.Errorf("Expected method %q, got %q", method, info.Method)
+		}
+		if info.CPU.LimitCores <= 0 {
+			t.Errorf("%s: expected positive CPU limit, got %f", method, info.CPU.LimitCores)
+		}
+	}
+
+	if _, err := toolbox.GetSystemInfoWithMethod("bogus"); err == nil {
+		t.Error("Expected error for unknown method")
+	}
+	if isMacOS() {
+		if _, err := toolbox.GetSystemInfoWithMethod("cgroup"); err == nil {
+			t.Error("Expected cgroup method to be unsupported on macOS")
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package toolbox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// memoryUnits maps accepted memory unit names to their size in bytes. KB, MB
+// and GB are decimal; KiB, MiB and GiB are binary.
+var memoryUnits = map[string]float64{
+	"bytes": 1,
+	"KB":    1e3,
+	"MB":    1e6,
+	"GB":    1e9,
+	"KiB":   1 << 10,
+	"MiB":   1 << 20,
+	"GiB":   1 << 30,
+}
+
+// defaultMemoryUnit is the unit of a Toolbox whose unit was never set
+const defaultMemoryUnit = "bytes"
+
+// SetMemoryUnit sets the unit of this instance's ...In memory getters called
+// with an empty unit, and of the Unit, Usage, Limit and Available fields of the
+// MemoryInfo it returns. Getters and fields named in bytes or MB keep their
+// unit. Each VU has its own instance, so the unit doesn't change what other VUs read.
+// unit: "bytes" (default, or "B"), "KB", "MB", "GB" (powers of 1000) or "KiB",
+// "MiB", "GiB" (powers of 1024), case-insensitive
+func (tb *Toolbox) SetMemoryUnit(unit string) error {
+	name, err := normalizeMemoryUnit(unit)
+	if err != nil {
+		return err
+	}
+	tb.memoryUnit = name
+	return nil
+}
+
+// GetMemoryUnit returns the currently configured memory unit
+func (tb Toolbox) GetMemoryUnit() string {
+	if tb.memoryUnit == "" {
+		return defaultMemoryUnit
+	}
+	return tb.memoryUnit
+}
+
+// normalizeMemoryUnit returns the canonical name of unit
+func normalizeMemoryUnit(unit string) (string, error) {
+	for name := range memoryUnits {
+		if strings.EqualFold(unit, name) {
+			return name, nil
+		}
+	}
+	if strings.EqualFold(unit, "B") {
+		return "bytes", nil
+	}
+	return "", fmt.Errorf("unsupported memory unit %q (expected B, KB, MB, GB, KiB, MiB or GiB)", unit)
+}
+
+// inMemoryUnit returns info with its memory unit fields in the instance's unit
+func (tb Toolbox) inMemoryUnit(info SystemInfo) SystemInfo {
+	applyMemoryUnit(&info.Memory, tb.GetMemoryUnit())
+	return info
+}
+
+// applyMemoryUnit fills the unit-scaled fields of info from its byte counts
+func applyMemoryUnit(info *MemoryInfo, unit string) {
+	divisor := memoryUnits[unit]
+
+	info.Unit = unit
+	info.Usage = float64(info.UsageBytes) / divisor
+	info.Limit = float64(info.LimitBytes) / divisor
+	info.Available = float64(info.AvailableBytes) / divisor
+}
+
+// GetMemoryUsageIn returns current memory usage in unit, any of the units
+// SetMemoryUnit accepts, or in the unit set with SetMemoryUnit when unit is
+// empty. The byte getters such as GetMemoryUsage always return bytes.
+func (tb Toolbox) GetMemoryUsageIn(unit string) (float64, error) {
+	return tb.memoryIn(unit, func(info MemoryInfo) int64 { return info.UsageBytes })
+}
+
+// GetMemoryLimitIn returns the memory limit in unit, see GetMemoryUsageIn
+func (tb Toolbox) GetMemoryLimitIn(unit string) (float64, error) {
+	return tb.memoryIn(unit, func(info MemoryInfo) int64 { return info.LimitBytes })
+}
+
+// GetAvailableMemoryIn returns the available memory in unit, see GetMemoryUsageIn
+func (tb Toolbox) GetAvailableMemoryIn(unit string) (float64, error) {
+	return tb.memoryIn(unit, func(info MemoryInfo) int64 { return info.AvailableBytes })
+}
+
+// GetMemoryLimitHierarchicalIn returns GetMemoryLimitHierarchical in unit, see GetMemoryUsageIn
+func (tb Toolbox) GetMemoryLimitHierarchicalIn(unit string) (float64, error) {
+	return tb.convertMemory(unit, getMemoryLimitHierarchical)
+}
+
+// GetMemoryPeakIn returns GetMemoryPeak in unit, see GetMemoryUsageIn
+func (tb Toolbox) GetMemoryPeakIn(unit string) (float64, error) {
+	return tb.convertMemory(unit, getMemoryPeak)
+}
+
+// memoryIn collects MemoryInfo and converts one of its byte counts to unit
+func (tb Toolbox) memoryIn(unit string, bytes func(MemoryInfo) int64) (float64, error) {
+	return tb.convertMemory(unit, func() (int64, error) {
+		info, err := collectMemoryInfo()
+		return bytes(info), err
+	})
+}
+
+// convertMemory reads a byte count and converts it to unit, or to the
+// instance's unit when unit is empty
+func (tb Toolbox) convertMemory(unit string, read func() (int64, error)) (float64, error) {
+	if unit == "" {
+		unit = tb.GetMemoryUnit()
+	}
+	name, err := normalizeMemoryUnit(unit)
+	if err != nil {
+		return 0, err
+	}
+	bytes, err := read()
+	if err != nil {
+		return 0, err
+	}
+	return float64(bytes) / memoryUnits[name], nil
+}
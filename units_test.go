@@ -0,0 +1,167 @@
+package toolbox
+
+import (
+	"testing"
+)
+
+func TestSetMemoryUnit(t *testing.T) {
+	toolbox := Toolbox{}
+
+	if unit := toolbox.GetMemoryUnit(); unit != "bytes" {
+		t.Errorf("Expected default unit 'bytes', got '%s'", unit)
+	}
+
+	if err := toolbox.SetMemoryUnit("mib"); err != nil {
+		t.Fatalf("SetMemoryUnit failed: %v", err)
+	}
+	if unit := toolbox.GetMemoryUnit(); unit != "MiB" {
+		t.Errorf("Expected canonical unit 'MiB', got '%s'", unit)
+	}
+	read := func() (int64, error) { return 512 * 1024 * 1024, nil }
+	if value, err := toolbox.convertMemory("", read); err != nil || value != 512 {
+		t.Errorf("Expected 512 MiB, got %f", value)
+	}
+
+	// MB is decimal, the same as in GetMemoryUsageIn
+	if err := toolbox.SetMemoryUnit("MB"); err != nil {
+		t.Fatalf("SetMemoryUnit failed: %v", err)
+	}
+	if value, err := toolbox.convertMemory("", read); err != nil || value != 536.870912 {
+		t.Errorf("Expected 536.870912 MB, got %f", value)
+	}
+
+	if err := toolbox.SetMemoryUnit("TB"); err == nil {
+		t.Error("Expected error for unsupported unit")
+	}
+	if unit := toolbox.GetMemoryUnit(); unit != "MB" {
+		t.Errorf("Expected unit to be unchanged after an invalid unit, got '%s'", unit)
+	}
+}
+
+func TestMemoryUnitPerInstance(t *testing.T) {
+	root := t.TempDir()
+	writeCgroupFile(t, root, "cpu.max", "200000 100000\n")
+	writeCgroupFile(t, root, "cpu.stat", "usage_usec 1000000\n")
+	writeCgroupFile(t, root, "memory.max", "1073741824\n")
+	writeCgroupFile(t, root, "memory.current", "536870912\n")
+	Configure(Options{CgroupRoot: root})
+	t.Cleanup(func() { Configure(Options{}) })
+
+	// Each VU gets its own instance, so one VU's unit doesn't leak into another's
+	gigabytes, bytes := Toolbox{}, Toolbox{}
+	if err := gigabytes.SetMemoryUnit("GiB"); err != nil {
+		t.Fatalf("SetMemoryUnit failed: %v", err)
+	}
+	if unit := bytes.GetMemoryUnit(); unit != "bytes" {
+		t.Errorf("Expected the other instance to keep 'bytes', got '%s'", unit)
+	}
+
+	if !isLinux() {
+		return
+	}
+	if usage, err := gigabytes.GetMemoryUsageIn(""); err != nil || usage != 0.5 {
+		t.Errorf("Expected 0.5 GiB, got %f (%v)", usage, err)
+	}
+	if usage, err := bytes.GetMemoryUsageIn(""); err != nil || usage != 536870912 {
+		t.Errorf("Expected 536870912 bytes, got %f (%v)", usage, err)
+	}
+	// The byte getters ignore the unit
+	if usage, err := gigabytes.GetMemoryUsage(); err != nil || usage != 536870912 {
+		t.Errorf("Expected GetMemoryUsage in bytes, got %d (%v)", usage, err)
+	}
+	if info, err := gigabytes.GetSystemInfo(); err != nil || info.Memory.Unit != "GiB" || info.Memory.Limit != 1 {
+		t.Errorf("Expected SystemInfo memory in GiB, got %+v (%v)", info.Memory, err)
+	}
+	if info, err := bytes.GetMemoryInfo(); err != nil || info.Unit != "bytes" {
+		t.Errorf("Expected MemoryInfo in bytes, got %+v (%v)", info, err)
+	}
+}
+
+func TestApplyMemoryUnit(t *testing.T) {
+	toolbox := Toolbox{}
+
+	if err := toolbox.SetMemoryUnit("GiB"); err != nil {
+		t.Fatalf("SetMemoryUnit failed: %v", err)
+	}
+
+	info := MemoryInfo{
+		UsageBytes:     1024 * 1024 * 1024,
+		LimitBytes:     4 * 1024 * 1024 * 1024,
+		AvailableBytes: 3 * 1024 * 1024 * 1024,
+	}
+	applyMemoryUnit(&info, toolbox.GetMemoryUnit())
+
+	if info.Unit != "GiB" {
+		t.Errorf("Expected unit 'GiB', got '%s'", info.Unit)
+	}
+	if info.Usage != 1 || info.Limit != 4 || info.Available != 3 {
+		t.Errorf("Expected 1/4/3 GiB, got %f/%f/%f", info.Usage, info.Limit, info.Available)
+	}
+}
+
+func TestNormalizeMemoryUnit(t *testing.T) {
+	tests := map[string]float64{
+		"B":   1,
+		"kb":  1000,
+		"MB":  1000 * 1000,
+		"GB":  1000 * 1000 * 1000,
+		"KiB": 1024,
+		"mib": 1024 * 1024,
+		"GiB": 1024 * 1024 * 1024,
+	}
+	for unit, want := range tests {
+		name, err := normalizeMemoryUnit(unit)
+		if err != nil || memoryUnits[name] != want {
+			t.Errorf("Expected %s to be %.0f bytes, got %.0f (%v)", unit, want, memoryUnits[name], err)
+		}
+	}
+	if _, err := normalizeMemoryUnit("TB"); err == nil {
+		t.Error("Expected error for unsupported unit")
+	}
+}
+
+func TestGetMemoryUsageIn(t *testing.T) {
+	if !isLinux() {
+		t.Skip("cgroup fixtures are Linux only")
+	}
+	root := t.TempDir()
+	writeCgroupFile(t, root, "memory.max", "2000000000\n")
+	writeCgroupFile(t, root, "memory.current", "536870912\n")
+	writeCgroupFile(t, root, "memory.peak", "1073741824\n")
+	Configure(Options{CgroupRoot: root})
+	t.Cleanup(func() { Configure(Options{}) })
+
+	toolbox := Toolbox{}
+	// The configured unit doesn't affect an explicit unit
+	if err := toolbox.SetMemoryUnit("GB"); err != nil {
+		t.Fatalf("SetMemoryUnit failed: %v", err)
+	}
+
+	if usage, err := toolbox.GetMemoryUsageIn("MiB"); err != nil || usage != 512 {
+		t.Errorf("Expected 512 MiB, got %f (%v)", usage, err)
+	}
+	if usage, err := toolbox.GetMemoryUsageIn("MB"); err != nil || usage != 536.870912 {
+		t.Errorf("Expected 536.870912 MB, got %f (%v)", usage, err)
+	}
+	if limit, err := toolbox.GetMemoryLimitIn("GB"); err != nil || limit != 2 {
+		t.Errorf("Expected a 2 GB limit, got %f (%v)", limit, err)
+	}
+	if available, err := toolbox.GetAvailableMemoryIn("B"); err != nil || available != 2000000000-536870912 {
+		t.Errorf("Expected available bytes, got %f (%v)", available, err)
+	}
+	if peak, err := toolbox.GetMemoryPeakIn("GiB"); err != nil || peak != 1 {
+		t.Errorf("Expected a 1 GiB peak, got %f (%v)", peak, err)
+	}
+
+	// An empty unit is the configured one
+	if peak, err := toolbox.GetMemoryPeakIn(""); err != nil || peak != 1.073741824 {
+		t.Errorf("Expected a 1.073741824 GB peak, got %f (%v)", peak, err)
+	}
+	if limit, err := toolbox.GetMemoryLimitIn(""); err != nil || limit != 2 {
+		t.Errorf("Expected a 2 GB limit, got %f (%v)", limit, err)
+	}
+
+	if _, err := toolbox.GetMemoryUsageIn("furlongs"); err == nil {
+		t.Error("Expected error for unsupported unit")
+	}
+}
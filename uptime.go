@@ -0,0 +1,128 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bootTimePattern matches the seconds of `sysctl -n kern.boottime` output,
+// e.g. "{ sec = 1700000000, usec = 123456 } Tue Nov 14 22:13:20 2023"
+var bootTimePattern = regexp.MustCompile(`sec\s*=\s*(\d+),\s*usec\s*=\s*(\d+)`)
+
+// GetUptimeSeconds returns the system uptime in seconds, from /proc/uptime on
+// Linux or the kern.boottime sysctl on macOS
+func (Toolbox) GetUptimeSeconds() (float64, error) {
+	if isMacOS() {
+		bootTime, err := readBootTime()
+		if err != nil {
+			return 0, err
+		}
+		return time.Since(bootTime).Seconds(), nil
+	}
+	if !isLinux() {
+		return 0, errors.New(ErrNotSupported)
+	}
+	return readUptimeSeconds()
+}
+
+// GetBootTime returns when the system booted, in UTC, from the btime line of
+// /proc/stat on Linux or the kern.boottime sysctl on macOS. Comparing it
+// across runners on the same host helps tell clock skew from separate hosts.
+func (Toolbox) GetBootTime() (time.Time, error) {
+	bootTime, err := readBootTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return bootTime.UTC(), nil
+}
+
+// GetMonotonicUptime returns seconds since boot from the monotonic clock, which
+// never jumps when the wall clock is adjusted (it does not count time suspended)
+func (Toolbox) GetMonotonicUptime() (float64, error) {
+	return monotonicSeconds()
+}
+
+// GetWallClock returns the current wall clock time in UTC. Comparing it across
+// load generators shows how far their clocks are skewed.
+func (Toolbox) GetWallClock() time.Time {
+	return time.Now().UTC()
+}
+
+// readBootTime reads the boot time from /proc/stat on Linux or kern.boottime on macOS
+func readBootTime() (time.Time, error) {
+	if isMacOS() {
+		output, err := commandOutput("sysctl", "-n", "kern.boottime")
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: %w", ErrCommandFailed, err)
+		}
+		return parseBootTime(string(output))
+	}
+	if !isLinux() {
+		return time.Time{}, errors.New(ErrNotSupported)
+	}
+
+	content, err := readFile("/proc/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseProcStatBootTime(content)
+}
+
+// parseProcStatBootTime parses the "btime <unix seconds>" line of /proc/stat
+func parseProcStatBootTime(content string) (time.Time, error) {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "btime" {
+			continue
+		}
+		sec, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: %w", ErrParsingValue, err)
+		}
+		return time.Unix(sec, 0), nil
+	}
+	return time.Time{}, errors.New("btime not found in /proc/stat")
+}
+
+// readUptimeSeconds reads the system uptime from /proc/uptime
+func readUptimeSeconds() (float64, error) {
+	content, err := readFile("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	return parseProcUptime(content)
+}
+
+// parseProcUptime returns the first value of /proc/uptime, the uptime in seconds
+func parseProcUptime(content string) (float64, error) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return 0, errors.New("invalid /proc/uptime format")
+	}
+	uptime, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+	return uptime, nil
+}
+
+// parseBootTime parses the output of `sysctl -n kern.boottime`
+func parseBootTime(output string) (time.Time, error) {
+	match := bootTimePattern.FindStringSubmatch(output)
+	if match == nil {
+		return time.Time{}, errors.New("invalid kern.boottime format")
+	}
+	sec, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+	usec, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", ErrParsingValue, err)
+	}
+	return time.Unix(sec, usec*1000), nil
+}
@@ -0,0 +1,111 @@
+package toolbox
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseProcUptime(t *testing.T) {
+	uptime, err := parseProcUptime("350735.47 234388.90\n")
+	if err != nil {
+		t.Fatalf("parseProcUptime() error: %v", err)
+	}
+	if uptime != 350735.47 {
+		t.Errorf("Expected 350735.47, got %v", uptime)
+	}
+
+	for _, content := range []string{"", "abc 1.0"} {
+		if _, err := parseProcUptime(content); err == nil {
+			t.Errorf("Expected error for %q", content)
+		}
+	}
+}
+
+func TestParseBootTime(t *testing.T) {
+	bootTime, err := parseBootTime("{ sec = 1700000000, usec = 250000 } Tue Nov 14 22:13:20 2023\n")
+	if err != nil {
+		t.Fatalf("parseBootTime() error: %v", err)
+	}
+	expected := time.Unix(1700000000, 250000000)
+	if !bootTime.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, bootTime)
+	}
+
+	if _, err := parseBootTime("garbage"); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
+
+func TestGetUptimeSeconds(t *testing.T) {
+	toolbox := Toolbox{}
+
+	uptime, err := toolbox.GetUptimeSeconds()
+	if err != nil {
+		t.Logf("GetUptimeSeconds failed (may not be available in test environment): %v", err)
+		return
+	}
+	if uptime <= 0 {
+		t.Errorf("Expected positive uptime, got %v", uptime)
+	}
+	t.Logf("Uptime: %.2fs", uptime)
+}
+
+func TestParseProcStatBootTime(t *testing.T) {
+	content := "cpu  100 0 50 1000 0 0 0 0 0 0\nintr 12345\nctxt 67890\nbtime 1700000000\nprocesses 4242\n"
+	bootTime, err := parseProcStatBootTime(content)
+	if err != nil {
+		t.Fatalf("parseProcStatBootTime() error: %v", err)
+	}
+	if !bootTime.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Expected 1700000000, got %v", bootTime)
+	}
+
+	for _, content := range []string{"", "btime abc\n"} {
+		if _, err := parseProcStatBootTime(content); err == nil {
+			t.Errorf("Expected error for %q", content)
+		}
+	}
+}
+
+func TestGetBootTime(t *testing.T) {
+	toolbox := Toolbox{}
+	bootTime, err := toolbox.GetBootTime()
+	if err != nil {
+		t.Logf("GetBootTime failed (expected in test environment): %v", err)
+		return
+	}
+	if bootTime.Location() != time.UTC {
+		t.Errorf("Expected boot time in UTC, got %v", bootTime.Location())
+	}
+	if !bootTime.Before(time.Now()) {
+		t.Errorf("Expected boot time in the past, got %v", bootTime)
+	}
+}
+
+func TestGetMonotonicUptime(t *testing.T) {
+	toolbox := Toolbox{}
+	first, err := toolbox.GetMonotonicUptime()
+	if err != nil {
+		t.Logf("GetMonotonicUptime failed (expected in test environment): %v", err)
+		return
+	}
+	time.Sleep(10 * time.Millisecond)
+	second, err := toolbox.GetMonotonicUptime()
+	if err != nil {
+		t.Fatalf("GetMonotonicUptime failed: %v", err)
+	}
+	if first <= 0 || second-first < 0.01 {
+		t.Errorf("Expected a positive, advancing uptime, got %f then %f", first, second)
+	}
+}
+
+func TestGetWallClock(t *testing.T) {
+	toolbox := Toolbox{}
+	now := toolbox.GetWallClock()
+	if now.Location() != time.UTC {
+		t.Errorf("Expected wall clock in UTC, got %v", now.Location())
+	}
+	if diff := time.Since(now); diff < 0 || diff > time.Second {
+		t.Errorf("Expected the current time, got %v", now)
+	}
+}
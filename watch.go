@@ -0,0 +1,190 @@
+package toolbox
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// resourceWatchCapacity is how many samples each watch keeps before overwriting the oldest
+const resourceWatchCapacity = 1024
+
+// minResourceWatchInterval keeps watches from sampling faster than a CPU sample takes
+const minResourceWatchInterval = 100 * time.Millisecond
+
+// resourceWatch samples SystemInfo on its own goroutine into a ring buffer
+type resourceWatch struct {
+	mu      sync.Mutex
+	samples []SystemInfo // Ring buffer, oldest sample at next once full
+	next    int
+	full    bool
+	lastErr error // Error of the most recent collection, nil if it succeeded
+
+	owner *Toolbox // Instance that started the watch, stopped by its Close
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// Active resource watches keyed by watch ID
+var (
+	resourceWatchesMu   sync.Mutex
+	resourceWatches     = make(map[int]*resourceWatch)
+	nextResourceWatchID int
+)
+
+// StartResourceWatch starts sampling SystemInfo every intervalMs milliseconds on a
+// background goroutine and returns the ID of the watch. Call StopResourceWatch
+// when done to stop the goroutine; Close stops the watches this instance started.
+func (tb *Toolbox) StartResourceWatch(intervalMs int) (int, error) {
+	interval := time.Duration(intervalMs) * time.Millisecond
+	if interval < minResourceWatchInterval {
+		return 0, fmt.Errorf("interval must be at least %dms", minResourceWatchInterval.Milliseconds())
+	}
+	return startResourceWatch(tb, interval, resourceWatchCapacity, getSystemInfo), nil
+}
+
+// GetLatestSample returns the most recent sample of a watch. Before the first
+// sample is collected, the collection error (if any) is returned.
+func (tb Toolbox) GetLatestSample(watchID int) (SystemInfo, error) {
+	watch, err := getResourceWatch(watchID)
+	if err != nil {
+		return SystemInfo{}, err
+	}
+	info, err := watch.latest()
+	return tb.inMemoryUnit(info), err
+}
+
+// StopResourceWatch stops a watch and waits for its goroutine to exit. Its
+// samples are discarded. Stopping an unknown or already stopped watch is a no-op.
+func (Toolbox) StopResourceWatch(watchID int) {
+	resourceWatchesMu.Lock()
+	watch, ok := resourceWatches[watchID]
+	delete(resourceWatches, watchID)
+	resourceWatchesMu.Unlock()
+
+	if ok {
+		close(watch.stop)
+		<-watch.done
+	}
+}
+
+// stopResourceWatches stops the active watches started by owner, or every
+// watch when owner is nil, and waits for their goroutines to exit
+func stopResourceWatches(owner *Toolbox) {
+	var watches []*resourceWatch
+	resourceWatchesMu.Lock()
+	for id, watch := range resourceWatches {
+		if owner == nil || watch.owner == owner {
+			watches = append(watches, watch)
+			delete(resourceWatches, id)
+		}
+	}
+	resourceWatchesMu.Unlock()
+
+	for _, watch := range watches {
+		close(watch.stop)
+	}
+	for _, watch := range watches {
+		<-watch.done
+	}
+}
+
+// startResourceWatch registers and starts a watch for owner calling collect every interval
+func startResourceWatch(owner *Toolbox, interval time.Duration, capacity int, collect func() (SystemInfo, error)) int {
+	watch := &resourceWatch{
+		samples: make([]SystemInfo, 0, capacity),
+		owner:   owner,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	resourceWatchesMu.Lock()
+	nextResourceWatchID++
+	id := nextResourceWatchID
+	resourceWatches[id] = watch
+	resourceWatchesMu.Unlock()
+
+	go watch.run(interval, collect)
+	return id
+}
+
+// getResourceWatch looks up an active watch
+func getResourceWatch(watchID int) (*resourceWatch, error) {
+	resourceWatchesMu.Lock()
+	defer resourceWatchesMu.Unlock()
+
+	watch, ok := resourceWatches[watchID]
+	if !ok {
+		return nil, fmt.Errorf("resource watch %d not found", watchID)
+	}
+	return watch, nil
+}
+
+// run collects a sample immediately and then every interval until stopped
+func (w *resourceWatch) run(interval time.Duration, collect func() (SystemInfo, error)) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		info, err := collect()
+		w.record(info, err)
+
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// record stores a collected sample, or the error if collection failed
+func (w *resourceWatch) record(info SystemInfo, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.lastErr = err
+	if err != nil {
+		return
+	}
+	if len(w.samples) < cap(w.samples) {
+		w.samples = append(w.samples, info)
+		return
+	}
+	w.samples[w.next] = info
+	w.next = (w.next + 1) % len(w.samples)
+	w.full = true
+}
+
+// latest returns the most recently stored sample
+func (w *resourceWatch) latest() (SystemInfo, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.samples) == 0 {
+		if w.lastErr != nil {
+			return SystemInfo{}, w.lastErr
+		}
+		return SystemInfo{}, errors.New("no samples collected yet")
+	}
+	if !w.full {
+		return w.samples[len(w.samples)-1], nil
+	}
+	return w.samples[(w.next+len(w.samples)-1)%len(w.samples)], nil
+}
+
+// usagePercents copies the CPU and memory usage percentages of the buffered samples
+func (w *resourceWatch) usagePercents() (cpu, memory []float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cpu = make([]float64, len(w.samples))
+	memory = make([]float64, len(w.samples))
+	for i, sample := range w.samples {
+		cpu[i] = sample.CPU.UsagePercent
+		memory[i] = sample.Memory.UsagePercent
+	}
+	return cpu, memory
+}
@@ -0,0 +1,109 @@
+package toolbox
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// snapshot returns a copy of the buffered samples, oldest first
+func (w *resourceWatch) snapshot() []SystemInfo {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	samples := make([]SystemInfo, 0, len(w.samples))
+	samples = append(samples, w.samples[w.next:]...)
+	return append(samples, w.samples[:w.next]...)
+}
+
+func TestResourceWatchRingBuffer(t *testing.T) {
+	watch := &resourceWatch{samples: make([]SystemInfo, 0, 3)}
+
+	if _, err := watch.latest(); err == nil {
+		t.Error("Expected error before the first sample")
+	}
+
+	for i := 1; i <= 5; i++ {
+		watch.record(SystemInfo{CPU: CPUInfo{UsagePercent: float64(i)}}, nil)
+	}
+
+	latest, err := watch.latest()
+	if err != nil {
+		t.Fatalf("latest() error: %v", err)
+	}
+	if latest.CPU.UsagePercent != 5 {
+		t.Errorf("Expected latest sample 5, got %v", latest.CPU.UsagePercent)
+	}
+
+	samples := watch.snapshot()
+	if len(samples) != 3 {
+		t.Fatalf("Expected 3 buffered samples, got %d", len(samples))
+	}
+	for i, want := range []float64{3, 4, 5} {
+		if samples[i].CPU.UsagePercent != want {
+			t.Errorf("Sample %d: expected %v, got %v", i, want, samples[i].CPU.UsagePercent)
+		}
+	}
+
+	// A failed collection keeps the previous samples
+	watch.record(SystemInfo{}, errors.New("collection failed"))
+	if latest, err := watch.latest(); err != nil || latest.CPU.UsagePercent != 5 {
+		t.Errorf("Expected sample 5 to survive a failed collection, got %v, %v", latest.CPU.UsagePercent, err)
+	}
+}
+
+func TestResourceWatchLifecycle(t *testing.T) {
+	toolbox := Toolbox{}
+	baseline := runtime.NumGoroutine()
+
+	var calls atomic.Int64
+	collect := func() (SystemInfo, error) {
+		n := calls.Add(1)
+		return SystemInfo{CPU: CPUInfo{UsagePercent: float64(n)}}, nil
+	}
+	id := startResourceWatch(&toolbox, 5*time.Millisecond, 16, collect)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for calls.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	sample, err := toolbox.GetLatestSample(id)
+	if err != nil {
+		t.Fatalf("GetLatestSample() error: %v", err)
+	}
+	if sample.CPU.UsagePercent < 1 {
+		t.Errorf("Expected a collected sample, got %+v", sample)
+	}
+
+	toolbox.StopResourceWatch(id)
+	stopped := calls.Load()
+	time.Sleep(20 * time.Millisecond)
+	if calls.Load() != stopped {
+		t.Error("Expected no collections after StopResourceWatch")
+	}
+	if _, err := toolbox.GetLatestSample(id); err == nil {
+		t.Error("Expected error for a stopped watch")
+	}
+	if after := runtime.NumGoroutine(); after > baseline {
+		t.Errorf("Expected goroutines to return to baseline %d after Stop, got %d", baseline, after)
+	}
+
+	// Stopping twice is a no-op
+	toolbox.StopResourceWatch(id)
+}
+
+func TestStartResourceWatchValidation(t *testing.T) {
+	toolbox := Toolbox{}
+
+	for _, interval := range []int{-1, 0, 50} {
+		if _, err := toolbox.StartResourceWatch(interval); err == nil {
+			t.Errorf("Expected error for interval %dms", interval)
+		}
+	}
+	if _, err := toolbox.GetLatestSample(-1); err == nil {
+		t.Error("Expected error for unknown watch ID")
+	}
+}